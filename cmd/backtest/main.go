@@ -0,0 +1,186 @@
+// Команда backtest воспроизводит историю, записанную recorder, через детектор
+// арбитражных возможностей и печатает число возможностей, гипотетический PnL
+// и его чувствительность к комиссиям.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/adapter/binance"
+	"github.com/dimryb/cross-arb/internal/adapter/jupiter"
+	"github.com/dimryb/cross-arb/internal/adapter/mexc"
+	"github.com/dimryb/cross-arb/internal/adapter/raydium"
+	"github.com/dimryb/cross-arb/internal/backtest"
+	"github.com/dimryb/cross-arb/internal/config"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/logger"
+	"github.com/dimryb/cross-arb/internal/recorder"
+	"github.com/dimryb/cross-arb/internal/usecase/scan"
+)
+
+var (
+	configPath = flag.String("config", "configs/config.yaml", "Path to configuration file")
+	dataDir    = flag.String("data", "", "History directory (default: recorder.dir from config)")
+	fromFlag   = flag.String("from", "", "Replay events from this time, RFC 3339")
+	toFlag     = flag.String("to", "", "Replay events before this time, RFC 3339")
+	speed      = flag.Float64("speed", 0, "Replay speed relative to real time; 0 replays without pauses")
+	size       = flag.Float64("size", 0, "Trade size in BASE for hypothetical PnL (default: scanner.sizing.minSize)")
+	minSpread  = flag.Float64("min-spread", -1, "Minimum net spread, % (default: scanner.minSpreadPct)")
+	feesFlag   = flag.String("fees", "0,0.5,1,1.5,2", "Comma-separated taker fee multipliers")
+	asJSON     = flag.Bool("json", false, "Print the report as JSON")
+)
+
+func main() {
+	flag.Parse()
+
+	cfg, err := config.NewConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Config error: %s", err)
+	}
+	opts, err := options(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	from, err := parseTime(*fromFlag)
+	if err != nil {
+		log.Fatalf("invalid -from: %v", err)
+	}
+	to, err := parseTime(*toFlag)
+	if err != nil {
+		log.Fatalf("invalid -to: %v", err)
+	}
+
+	l := logger.New(cfg.Log.Level)
+	adapters, err := feeAdapters(l, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dir := *dataDir
+	if dir == "" {
+		dir = cfg.Recorder.Dir
+	}
+	src, err := recorder.OpenDir(dir, from, to)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer src.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	report, err := backtest.Run(ctx, l, src, adapters, opts)
+	if err != nil {
+		log.Printf("Backtest stopped: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Printf("Failed to encode report: %v", err)
+		}
+		return
+	}
+	printReport(report, opts.Size)
+}
+
+func options(cfg *config.CrossArbConfig) (backtest.Options, error) {
+	opts := backtest.Options{
+		Speed: *speed,
+		Size:  *size,
+		Detector: scan.OpportunityOptions{
+			MinSpreadPct: cfg.Scanner.MinSpreadPct,
+		},
+	}
+	if opts.Size == 0 {
+		opts.Size = cfg.Scanner.Sizing.MinSize
+	}
+	if *minSpread >= 0 {
+		opts.Detector.MinSpreadPct = *minSpread
+	}
+	if cfg.Scanner.MaxQuoteAge != "" {
+		age, err := time.ParseDuration(cfg.Scanner.MaxQuoteAge)
+		if err != nil {
+			return opts, fmt.Errorf("invalid scanner maxQuoteAge: %w", err)
+		}
+		opts.Detector.MaxQuoteAge = age
+	}
+	for _, f := range strings.Split(*feesFlag, ",") {
+		k, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return opts, fmt.Errorf("invalid fee multiplier %q", f)
+		}
+		opts.FeeMultipliers = append(opts.FeeMultipliers, k)
+	}
+	return opts, nil
+}
+
+// feeAdapters создаёт адаптеры включённых в конфиге бирж, как приложение, но только ради
+// комиссий: запросов к биржам они не делают (у Binance — комиссии по умолчанию).
+func feeAdapters(l i.Logger, cfg *config.CrossArbConfig) ([]i.EXAdapter, error) {
+	adapters := []i.EXAdapter{
+		mexc.NewAdapter(l, 0),
+		jupiter.NewAdapter(l, &jupiter.AdapterConfig{BaseURL: cfg.Exchanges[config.JupExchange].BaseURL}),
+	}
+	if binCfg := cfg.Exchanges[config.BinanceExchange]; binCfg.Enabled {
+		ad, err := binance.NewAdapter(l, binance.Config{BaseURL: binCfg.BaseURL})
+		if err != nil {
+			return nil, fmt.Errorf("binance adapter: %w", err)
+		}
+		adapters = append(adapters, ad)
+	}
+	if cfg.Exchanges[config.RaydiumExchange].Enabled {
+		adapters = append(adapters, raydium.NewAdapter(l, nil, nil))
+	}
+	return adapters, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func printReport(r backtest.Report, size float64) {
+	if r.From.IsZero() {
+		fmt.Println("No events in history")
+		return
+	}
+	fmt.Printf("History: %s — %s\n", r.From.Format(time.RFC3339), r.To.Format(time.RFC3339))
+	fmt.Printf("Events: %d quotes, %d order books, %d tickers\n",
+		r.Events[recorder.KindQuote], r.Events[recorder.KindBook], r.Events[recorder.KindTicker])
+	fmt.Printf("Hypothetical PnL for %v BASE per opportunity\n\n", size)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "FEES\tOPPORTUNITIES\tPNL")
+	for _, s := range r.Scenarios {
+		_, _ = fmt.Fprintf(w, "x%.2f\t%d\t%.4f\n", s.FeeMultiplier, s.Opportunities, s.Pnl)
+	}
+	_ = w.Flush()
+
+	for _, s := range r.Scenarios {
+		if len(s.Pairs) == 0 {
+			continue
+		}
+		fmt.Printf("\nFees x%.2f by direction:\n", s.FeeMultiplier)
+		_, _ = fmt.Fprintln(w, "PAIR\tBUY\tSELL\tOPPORTUNITIES\tPNL\tMAX SPREAD %")
+		for _, p := range s.Pairs {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%.4f\t%.4f\n",
+				p.Pair, p.BuyOn, p.SellOn, p.Opportunities, p.Pnl, p.MaxSpreadPct)
+		}
+		_ = w.Flush()
+	}
+}
@@ -0,0 +1,190 @@
+package binance
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+var _ i.CEXAdapter = (*Adapter)(nil)
+
+const (
+	defaultBaseURL = "https://api.binance.com"
+	defaultTimeout = 3 * time.Second
+	// defaultTakerFee — базовая комиссия спота Binance, пока не загружены комиссии аккаунта.
+	defaultTakerFee = 0.001
+	recvWindow      = 5000
+)
+
+// Config — параметры адаптера Binance-совместимой биржи.
+type Config struct {
+	// BaseURL — адрес REST API без /api/v3. При пустом значении — https://api.binance.com.
+	BaseURL string
+	// APIKey и SecretKey нужны только для подписанных запросов (RefreshFees).
+	APIKey    string
+	SecretKey string
+	// Timeout — таймаут HTTP-запросов. При 0 — 3 сек.
+	Timeout time.Duration
+	// Markets — реестр инструментов, через который пары переводятся в символы биржи.
+	// Необязателен: без листинга символ — Base+Quote ("SOL/USDT" → "SOLUSDT").
+	Markets *market.Registry
+}
+
+// Adapter реализует CEXAdapter поверх REST API Binance (/api/v3/depth, /api/v3/ticker/bookTicker).
+// Подходит и для бирж с совместимым API: адрес задаётся в Config.BaseURL.
+//
+// Комиссии по умолчанию — 0.1 %; при наличии ключей RefreshFees загружает комиссии аккаунта.
+type Adapter struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	secret  string
+	markets *market.Registry
+	logger  i.Logger
+	nowFunc func() time.Time
+
+	mu   sync.RWMutex
+	fees map[string][2]float64 // символ → {maker, taker}
+}
+
+// NewAdapter создаёт адаптер.
+func NewAdapter(l i.Logger, cfg Config) (*Adapter, error) {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+	if _, err := url.Parse(cfg.BaseURL); err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	return &Adapter{
+		client:  &http.Client{Timeout: cfg.Timeout},
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		apiKey:  cfg.APIKey,
+		secret:  cfg.SecretKey,
+		markets: cfg.Markets,
+		logger:  l.Named("binance"),
+		nowFunc: time.Now,
+		fees:    make(map[string][2]float64),
+	}, nil
+}
+
+// Name удовлетворяет интерфейсу EXAdapter.
+func (a *Adapter) Name() string { return "binance" }
+
+// TradingFee удовлетворяет интерфейсу EXAdapter: комиссии аккаунта, если они загружены
+// через RefreshFees, иначе базовые 0.1 %.
+func (a *Adapter) TradingFee(pair string) (maker, taker float64) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if f, ok := a.fees[a.Symbol(pair)]; ok {
+		return f[0], f[1]
+	}
+	return defaultTakerFee, defaultTakerFee
+}
+
+// Close удовлетворяет интерфейсу, доп. ресурсы не удерживаются.
+func (a *Adapter) Close() error { return nil }
+
+// Symbol переводит пару ("SOL/USDT") в символ биржи ("SOLUSDT") через Config.Markets.
+func (a *Adapter) Symbol(pair string) string {
+	return a.markets.Symbol(a.Name(), pair)
+}
+
+// RefreshFees загружает комиссии аккаунта (GET /sapi/v1/asset/tradeFee, подписанный запрос).
+func (a *Adapter) RefreshFees(ctx context.Context) error {
+	if a.apiKey == "" || a.secret == "" {
+		return errors.New("api key and secret are required")
+	}
+	var raw []struct {
+		Symbol          string `json:"symbol"`
+		MakerCommission string `json:"makerCommission"`
+		TakerCommission string `json:"takerCommission"`
+	}
+	if err := a.do(ctx, "/sapi/v1/asset/tradeFee", url.Values{}, true, &raw); err != nil {
+		return err
+	}
+
+	fees := make(map[string][2]float64, len(raw))
+	for _, f := range raw {
+		maker, err := strconv.ParseFloat(f.MakerCommission, 64)
+		if err != nil {
+			return fmt.Errorf("parse maker fee of %s: %w", f.Symbol, err)
+		}
+		taker, err := strconv.ParseFloat(f.TakerCommission, 64)
+		if err != nil {
+			return fmt.Errorf("parse taker fee of %s: %w", f.Symbol, err)
+		}
+		fees[f.Symbol] = [2]float64{maker, taker}
+	}
+
+	a.mu.Lock()
+	a.fees = fees
+	a.mu.Unlock()
+	return nil
+}
+
+// do выполняет GET-запрос и декодирует ответ в out. Подписанный запрос дополняется
+// timestamp, recvWindow и подписью HMAC-SHA256 строки запроса, ключ — в X-MBX-APIKEY.
+func (a *Adapter) do(ctx context.Context, path string, q url.Values, signed bool, out any) error {
+	query := q.Encode()
+	if signed {
+		q.Set("timestamp", strconv.FormatInt(a.nowFunc().UnixMilli(), 10))
+		q.Set("recvWindow", strconv.Itoa(recvWindow))
+		query = q.Encode()
+		query += "&signature=" + sign(query, a.secret)
+	}
+	requestURL := a.baseURL + path
+	if query != "" {
+		requestURL += "?" + query
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if signed {
+		req.Header.Set("X-MBX-APIKEY", a.apiKey)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request: %w", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read %s response: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return parseAPIError(resp.StatusCode, body)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode %s response: %w", path, err)
+	}
+	return nil
+}
+
+// sign возвращает HMAC-SHA256 строки запроса в hex.
+func sign(query, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(query))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,138 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/adapter/cextest"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/logger"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+func newTestAdapter(t *testing.T, baseURL string, cfg Config) *Adapter {
+	t.Helper()
+	cfg.BaseURL = baseURL
+	a, err := NewAdapter(logger.New("error"), cfg)
+	if err != nil {
+		t.Fatalf("NewAdapter: %v", err)
+	}
+	a.nowFunc = func() time.Time { return time.UnixMilli(1700000000000) }
+	return a
+}
+
+func TestAdapter_CEXContract(t *testing.T) {
+	cextest.Run(t, cextest.Venue{
+		Symbol: "SOLUSDT",
+		New: func(t *testing.T, baseURL string) i.CEXAdapter {
+			return newTestAdapter(t, baseURL, Config{})
+		},
+		ServeDepth: func(w http.ResponseWriter, r *http.Request, symbol string, limit int, book cextest.Book) {
+			q := r.URL.Query()
+			if r.URL.Path != "/api/v3/depth" || q.Get("symbol") != symbol || q.Get("limit") != strconv.Itoa(limit) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"lastUpdateId":1027024,"bids":%s,"asks":%s}`, levels(book.Bids), levels(book.Asks))
+		},
+		ServeError: func(w http.ResponseWriter, status int) {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+		},
+	})
+}
+
+func levels(ls []cextest.Level) string {
+	parts := make([]string, len(ls))
+	for n, l := range ls {
+		parts[n] = fmt.Sprintf("[%q,%q]", l.Price, l.Qty)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func TestAdapter_SymbolOverridesAndErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("symbol"); got != "SOLFDUSD" {
+			t.Errorf("symbol = %s, want SOLFDUSD", got)
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+	}))
+	defer srv.Close()
+
+	markets := market.NewRegistry()
+	if err := markets.Add(market.Listing{
+		Venue: "binance", Instrument: market.Instrument{Base: "SOL", Quote: "USD"}, Symbol: "SOLFDUSD",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	a := newTestAdapter(t, srv.URL, Config{Markets: markets})
+	if _, err := a.OrderBookDepth(context.Background(), "SOL/USD", 0); !errors.Is(err, ErrInvalidSymbol) {
+		t.Errorf("err = %v, want ErrInvalidSymbol", err)
+	}
+	if got := a.Symbol("sol-usdt"); got != "SOLUSDT" {
+		t.Errorf("Symbol = %s, want SOLUSDT", got)
+	}
+}
+
+func TestAdapter_BookTicker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/ticker/bookTicker" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"symbol":"SOLUSDT","bidPrice":"100.1","bidQty":"3","askPrice":"100.2","askQty":"4.5"}`))
+	}))
+	defer srv.Close()
+
+	tk, err := newTestAdapter(t, srv.URL, Config{}).BookTicker(context.Background(), "SOL/USDT")
+	if err != nil {
+		t.Fatalf("BookTicker: %v", err)
+	}
+	if tk.Exchange != "binance" || tk.BidPrice != 100.1 || tk.AskQty != 4.5 {
+		t.Errorf("ticker = %+v", tk)
+	}
+}
+
+func TestAdapter_RefreshFees_SignsRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-MBX-APIKEY") != "key" {
+			t.Error("missing api key header")
+		}
+		raw := r.URL.RawQuery
+		idx := strings.LastIndex(raw, "&signature=")
+		if idx < 0 {
+			t.Fatalf("no signature in %q", raw)
+		}
+		if want := sign(raw[:idx], "secret"); raw[idx+len("&signature="):] != want {
+			t.Errorf("bad signature for %q", raw[:idx])
+		}
+		if r.URL.Query().Get("timestamp") != "1700000000000" {
+			t.Errorf("timestamp = %s", r.URL.Query().Get("timestamp"))
+		}
+		_, _ = w.Write([]byte(`[{"symbol":"SOLUSDT","makerCommission":"0.0002","takerCommission":"0.00075"}]`))
+	}))
+	defer srv.Close()
+
+	a := newTestAdapter(t, srv.URL, Config{APIKey: "key", SecretKey: "secret"})
+	if _, taker := a.TradingFee("SOL/USDT"); taker != defaultTakerFee {
+		t.Errorf("default taker = %v", taker)
+	}
+	if err := a.RefreshFees(context.Background()); err != nil {
+		t.Fatalf("RefreshFees: %v", err)
+	}
+	if maker, taker := a.TradingFee("SOL/USDT"); maker != 0.0002 || taker != 0.00075 {
+		t.Errorf("fees = %v, %v", maker, taker)
+	}
+
+	if err := newTestAdapter(t, srv.URL, Config{}).RefreshFees(context.Background()); err == nil {
+		t.Error("expected error without keys")
+	}
+}
@@ -0,0 +1,104 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+)
+
+// maxDepthLimit — максимальная глубина, которую отдаёт /api/v3/depth.
+const maxDepthLimit = 5000
+
+// OrderBookDepth удовлетворяет интерфейсу CEXAdapter: запрашивает /api/v3/depth.
+// При limit <= 0 используется глубина по умолчанию на стороне биржи.
+// Ошибки биржи возвращаются как *APIError.
+func (a *Adapter) OrderBookDepth(ctx context.Context, pair string, limit int) (entity.OrderBook, error) {
+	symbol := a.Symbol(pair)
+	if symbol == "" {
+		return entity.OrderBook{}, fmt.Errorf("empty pair")
+	}
+
+	q := url.Values{}
+	q.Set("symbol", symbol)
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(min(limit, maxDepthLimit)))
+	}
+	var raw struct {
+		Bids [][]string `json:"bids"`
+		Asks [][]string `json:"asks"`
+	}
+	if err := a.do(ctx, "/api/v3/depth", q, false, &raw); err != nil {
+		return entity.OrderBook{}, err
+	}
+
+	bids, err := parseLevels(raw.Bids)
+	if err != nil {
+		return entity.OrderBook{}, fmt.Errorf("bids: %w", err)
+	}
+	asks, err := parseLevels(raw.Asks)
+	if err != nil {
+		return entity.OrderBook{}, fmt.Errorf("asks: %w", err)
+	}
+	sort.SliceStable(bids, func(x, y int) bool { return bids[x].Price > bids[y].Price })
+	sort.SliceStable(asks, func(x, y int) bool { return asks[x].Price < asks[y].Price })
+	return entity.OrderBook{Bids: bids, Asks: asks}, nil
+}
+
+// BookTicker возвращает лучшие цены пары (/api/v3/ticker/bookTicker).
+func (a *Adapter) BookTicker(ctx context.Context, pair string) (entity.TickerData, error) {
+	q := url.Values{}
+	q.Set("symbol", a.Symbol(pair))
+	var raw struct {
+		Symbol   string `json:"symbol"`
+		BidPrice string `json:"bidPrice"`
+		BidQty   string `json:"bidQty"`
+		AskPrice string `json:"askPrice"`
+		AskQty   string `json:"askQty"`
+	}
+	if err := a.do(ctx, "/api/v3/ticker/bookTicker", q, false, &raw); err != nil {
+		return entity.TickerData{}, err
+	}
+
+	t := entity.TickerData{Symbol: raw.Symbol, Exchange: a.Name()}
+	for _, f := range []struct {
+		dst *float64
+		raw string
+	}{
+		{&t.BidPrice, raw.BidPrice}, {&t.BidQty, raw.BidQty}, {&t.AskPrice, raw.AskPrice}, {&t.AskQty, raw.AskQty},
+	} {
+		v, err := strconv.ParseFloat(f.raw, 64)
+		if err != nil {
+			return entity.TickerData{}, fmt.Errorf("parse book ticker %q: %w", f.raw, err)
+		}
+		*f.dst = v
+	}
+	return t, nil
+}
+
+// parseLevels разбирает уровни стакана вида [["price","qty"], ...].
+// Уровни с нулевым объёмом пропускаются.
+func parseLevels(raw [][]string) ([]entity.Order, error) {
+	levels := make([]entity.Order, 0, len(raw))
+	for _, item := range raw {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("malformed level %v", item)
+		}
+		price, err := strconv.ParseFloat(item[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse price %q: %w", item[0], err)
+		}
+		qty, err := strconv.ParseFloat(item[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse quantity %q: %w", item[1], err)
+		}
+		if price <= 0 || qty <= 0 {
+			continue
+		}
+		levels = append(levels, entity.Order{Price: price, Quantity: qty})
+	}
+	return levels, nil
+}
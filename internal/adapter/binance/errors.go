@@ -0,0 +1,57 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// APIError — ошибка Binance API в формате {"code": ..., "msg": ...}.
+// Сравнение через errors.Is выполняется по коду ошибки, а при его отсутствии — по HTTP-статусу.
+type APIError struct {
+	StatusCode int    // HTTP-статус ответа
+	Code       int    // код ошибки Binance
+	Msg        string // сообщение Binance
+}
+
+func (e *APIError) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("binance api error: status %d: %s", e.StatusCode, e.Msg)
+	}
+	return fmt.Sprintf("binance api error: status %d, code %d: %s", e.StatusCode, e.Code, e.Msg)
+}
+
+// Is позволяет сравнивать ошибку с сентинелами пакета через errors.Is.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	if t.Code != 0 {
+		return t.Code == e.Code
+	}
+	return t.StatusCode != 0 && t.StatusCode == e.StatusCode
+}
+
+// Известные коды ошибок Binance Spot API.
+var (
+	ErrInvalidSymbol = &APIError{Code: -1121, Msg: "invalid symbol"}
+	ErrSignature     = &APIError{Code: -1022, Msg: "signature is not valid"}
+	ErrTimestamp     = &APIError{Code: -1021, Msg: "timestamp outside of recvWindow"}
+	ErrInvalidAPIKey = &APIError{Code: -2015, Msg: "invalid api key, ip or permissions"}
+	ErrRateLimited   = &APIError{StatusCode: http.StatusTooManyRequests, Msg: "rate limited"}
+	ErrIPBanned      = &APIError{StatusCode: http.StatusTeapot, Msg: "ip banned"}
+)
+
+// parseAPIError строит *APIError из HTTP-статуса и тела ответа.
+// Если тело не содержит структурированной ошибки, в Msg попадает сырое тело.
+func parseAPIError(statusCode int, body []byte) error {
+	var raw struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil || (raw.Code == 0 && raw.Msg == "") {
+		return &APIError{StatusCode: statusCode, Msg: string(body)}
+	}
+	return &APIError{StatusCode: statusCode, Code: raw.Code, Msg: raw.Msg}
+}
@@ -0,0 +1,119 @@
+// Package cextest — общий контрактный набор тестов для реализаций CEXAdapter.
+//
+// Каждый адаптер подключает его из своего пакета тестов, описывая биржу через Venue:
+// как создать адаптер на адрес тестового сервера и как отдать стакан в формате биржи.
+package cextest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	i "github.com/dimryb/cross-arb/internal/interface"
+)
+
+// Pair — пара, на которой проверяется контракт.
+const Pair = "SOL/USDT"
+
+// Level — уровень стакана в текстовом виде, как его отдают биржи.
+type Level struct {
+	Price, Qty string
+}
+
+// Book — стакан, который тестовый сервер отдаёт в формате биржи. Уровни намеренно
+// не упорядочены и содержат нулевой объём.
+type Book struct {
+	Bids, Asks []Level
+}
+
+// Fixture — стакан контракта.
+var Fixture = Book{
+	Bids: []Level{{"99.5", "2"}, {"100", "1"}, {"98", "0"}},
+	Asks: []Level{{"101.5", "3"}, {"101", "4"}},
+}
+
+// Venue описывает биржу для контрактных тестов.
+type Venue struct {
+	// Symbol — символ Pair на бирже.
+	Symbol string
+	// New создаёт адаптер, обращающийся к baseURL тестового сервера.
+	New func(t *testing.T, baseURL string) i.CEXAdapter
+	// ServeDepth отдаёт book в формате биржи. Запрос с другим символом или глубиной
+	// должен получить ответ об ошибке в формате биржи.
+	ServeDepth func(w http.ResponseWriter, r *http.Request, symbol string, limit int, book Book)
+	// ServeError отдаёт ошибку биржи с HTTP-статусом status.
+	ServeError func(w http.ResponseWriter, status int)
+}
+
+// Run проверяет контракт CEXAdapter для venue.
+func Run(t *testing.T, venue Venue) {
+	t.Helper()
+
+	newAdapter := func(t *testing.T, h http.HandlerFunc) i.CEXAdapter {
+		t.Helper()
+		srv := httptest.NewServer(h)
+		t.Cleanup(srv.Close)
+		return venue.New(t, srv.URL)
+	}
+
+	t.Run("identity", func(t *testing.T) {
+		ad := newAdapter(t, func(http.ResponseWriter, *http.Request) {})
+		if ad.Name() == "" {
+			t.Error("empty Name")
+		}
+		maker, taker := ad.TradingFee(Pair)
+		if maker < 0 || taker < 0 || maker > 0.01 || taker > 0.01 {
+			t.Errorf("TradingFee = %v, %v; want fractions within [0, 0.01]", maker, taker)
+		}
+		if err := ad.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+
+	t.Run("depth is sorted and cleaned", func(t *testing.T) {
+		ad := newAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+			venue.ServeDepth(w, r, venue.Symbol, 5, Fixture)
+		})
+		book, err := ad.OrderBookDepth(context.Background(), Pair, 5)
+		if err != nil {
+			t.Fatalf("OrderBookDepth: %v", err)
+		}
+		if len(book.Bids) != 2 || book.Bids[0].Price != 100 || book.Bids[0].Quantity != 1 || book.Bids[1].Price != 99.5 {
+			t.Errorf("bids = %+v, want [100x1 99.5x2]", book.Bids)
+		}
+		if len(book.Asks) != 2 || book.Asks[0].Price != 101 || book.Asks[0].Quantity != 4 || book.Asks[1].Price != 101.5 {
+			t.Errorf("asks = %+v, want [101x4 101.5x3]", book.Asks)
+		}
+	})
+
+	t.Run("venue error", func(t *testing.T) {
+		ad := newAdapter(t, func(w http.ResponseWriter, _ *http.Request) {
+			venue.ServeError(w, http.StatusBadRequest)
+		})
+		if _, err := ad.OrderBookDepth(context.Background(), Pair, 5); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("malformed response", func(t *testing.T) {
+		ad := newAdapter(t, func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"bids":[["x","1"]],"asks":[]}`))
+		})
+		if _, err := ad.OrderBookDepth(context.Background(), Pair, 5); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("respects context", func(t *testing.T) {
+		ad := newAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+			venue.ServeDepth(w, r, venue.Symbol, 5, Fixture)
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := ad.OrderBookDepth(ctx, Pair, 5); !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	})
+}
@@ -3,32 +3,47 @@ package jupiter
 import (
 	"context"
 	"fmt"
+	"math"
 	"strconv"
 	"time"
 
 	"github.com/dimryb/cross-arb/internal/api/jupiter"
 	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/market"
 )
 
+var _ i.DEXAdapter = (*Adapter)(nil)
+
 // AdapterConfig конфигурация адаптера.
 type AdapterConfig struct {
 	BaseURL string
 	Enabled bool
-	Timeout time.Duration       // Может использоваться в NewJupiterAdapterFromConfig
-	Pairs   map[string]MintPair // symbol → [base_mint, quote_mint]
+	Timeout time.Duration // Может использоваться в NewJupiterAdapterFromConfig
+	// Markets — реестр инструментов: листинги площадки "jupiter" задают mint-адреса
+	// и decimals токенов пары.
+	Markets *market.Registry
+	// Tokens — список токенов Jupiter, из которого берутся decimals, не заданные в листинге.
+	// Необязателен: без него decimals должны быть в листингах.
+	Tokens *jupiter.TokenRegistry
+	// SlippageBps — допустимый слиппедж в б.п., учитывается в исполнимых ценах. 0 — значение Jupiter по умолчанию.
+	SlippageBps int
+	// MaxPriceImpactPct — предельное ценовое влияние маршрута в процентах. 0 — без ограничения.
+	MaxPriceImpactPct float64
 }
 
 // Adapter JupiterAdapter использует публичный Quote-API агрегатора Jupiter (Solana).
 // Он запрашивает цену обмена base → quote (ask) и quote → base (bid).
 type Adapter struct {
-	client     *jupiter.Client
-	logger     i.Logger
-	baseURL    string
-	pairConfig map[string]MintPair // "SOL/USDT" → {baseMint, quoteMint}
+	client            *jupiter.Client
+	logger            i.Logger
+	baseURL           string
+	markets           *market.Registry
+	tokens            *jupiter.TokenRegistry
+	slippageBps       int
+	maxPriceImpactPct float64
 }
 
 // NewAdapter создаёт адаптер.
-// pairMap: "SOL/USDT": {baseMint, quoteMint}.
 func NewAdapter(l i.Logger, cfg *AdapterConfig) *Adapter {
 	// TODO: handle error
 	client, err := jupiter.NewJupiterClient(l, cfg.BaseURL)
@@ -38,77 +53,178 @@ func NewAdapter(l i.Logger, cfg *AdapterConfig) *Adapter {
 	}
 
 	return &Adapter{
-		client:     client,
-		logger:     l.Named("jupiter"),
-		baseURL:    cfg.BaseURL,
-		pairConfig: cfg.Pairs,
+		client:            client,
+		logger:            l.Named("jupiter"),
+		baseURL:           cfg.BaseURL,
+		markets:           cfg.Markets,
+		tokens:            cfg.Tokens,
+		slippageBps:       cfg.SlippageBps,
+		maxPriceImpactPct: cfg.MaxPriceImpactPct,
 	}
 }
 
 // Name удовлетворяет интерфейсу EXAdapter.
 func (j *Adapter) Name() string { return "jupiter" }
 
-// Quote для Jupiter: цены в QUOTE за 1 BASE для пары, например SOL/USDT.
+// Quote удовлетворяет интерфейсу DEXAdapter: эффективные цены в QUOTE за 1 BASE
+// для объёма baseAmount (в BASE), например для пары SOL/USDT.
+//
+//   - bid — сколько QUOTE получим за продажу baseAmount BASE (ExactIn BASE → QUOTE);
+//   - ask — сколько QUOTE заплатим за покупку baseAmount BASE (ExactOut QUOTE → BASE,
+//     при недоступности ExactOut — эквивалентная обратная ExactIn-котировка).
+//
+// Ценовое влияние маршрута уже заложено в OutAmount/InAmount Jupiter; поверх него
+// учитывается слиппедж (OtherAmountThreshold). Котировки с PriceImpactPct выше
+// MaxPriceImpactPct отклоняются.
 func (j *Adapter) Quote(
 	ctx context.Context,
 	pair string,
+	baseAmount float64,
 ) (bid, ask float64, err error) {
-	mints, ok := j.pairConfig[pair]
-	if !ok {
-		return 0, 0, fmt.Errorf("неизвестная пара %s", pair)
+	if baseAmount <= 0 {
+		return 0, 0, fmt.Errorf("baseAmount must be positive, got %v", baseAmount)
 	}
-
-	// ask: сколько QUOTE за 1 BASE
-	ask, err = j.quote(ctx, mints.BaseMint, mints.QuoteMint, nil)
+	mints, baseUnit, quoteUnit, err := j.listing(pair)
 	if err != nil {
-		return 0, 0, fmt.Errorf("ask: %w", err)
+		return 0, 0, err
 	}
 
-	// rawBid: сколько BASE за 1 QUOTE
-	rawBid, err := j.quote(ctx, mints.QuoteMint, mints.BaseMint, nil)
+	baseAtoms := int64(math.Round(baseAmount * float64(baseUnit)))
+	if baseAtoms <= 0 {
+		return 0, 0, fmt.Errorf("baseAmount %v is below token precision", baseAmount)
+	}
+
+	// bid: продаём baseAmount BASE, получаем QUOTE (не меньше порога слиппеджа).
+	sell, err := j.client.Quote(ctx, mints.BaseMint, mints.QuoteMint, baseAtoms, j.quoteOptions(jupiter.SwapModeExactIn))
 	if err != nil {
 		return 0, 0, fmt.Errorf("bid: %w", err)
 	}
-	if rawBid == 0 {
-		return 0, 0, fmt.Errorf("zero raw bid")
+	if err := j.checkPriceImpact(sell); err != nil {
+		return 0, 0, fmt.Errorf("bid: %w", err)
+	}
+	quoteOut, err := worstAmount(sell.OtherAmountThreshold, sell.OutAmount)
+	if err != nil {
+		return 0, 0, fmt.Errorf("bid: %w", err)
 	}
+	bid = quoteOut / float64(quoteUnit) / baseAmount
 
-	// bid в тех же единицах, что и ask: QUOTE за 1 BASE
-	bid = 1 / rawBid
+	// ask: покупаем ровно baseAmount BASE, платим QUOTE (не больше порога слиппеджа).
+	quoteIn, err := j.buyCost(ctx, mints, baseAtoms, quoteOut)
+	if err != nil {
+		return 0, 0, fmt.Errorf("ask: %w", err)
+	}
+	ask = quoteIn / float64(quoteUnit) / baseAmount
+
+	if bid <= 0 || ask <= 0 {
+		return 0, 0, fmt.Errorf("non-positive quote: bid=%v ask=%v", bid, ask)
+	}
 	return bid, ask, nil
 }
 
-// quote возвращает: "сколько OUT токенов за 1 IN токен".
-func (j *Adapter) quote(
-	ctx context.Context,
-	inMint string,
-	outMint string,
-	opts *jupiter.QuoteOptions,
-) (float64, error) {
-	inUnit, err := jupiter.UnitAmountByMint(inMint) // 10^decimals(IN)
-	if err != nil {
+// buyCost возвращает стоимость покупки baseAtoms BASE в атомах QUOTE.
+// Сначала пробует ExactOut; если маршрут его не поддерживает, оценивает стоимость
+// обратной ExactIn-котировкой QUOTE → BASE на сумму quoteHint и масштабирует результат.
+func (j *Adapter) buyCost(ctx context.Context, mints market.Listing, baseAtoms int64, quoteHint float64) (float64, error) {
+	buy, err := j.client.Quote(ctx, mints.QuoteMint, mints.BaseMint, baseAtoms, j.quoteOptions(jupiter.SwapModeExactOut))
+	if err == nil {
+		if err := j.checkPriceImpact(buy); err != nil {
+			return 0, err
+		}
+		return worstAmount(buy.OtherAmountThreshold, buy.InAmount)
+	}
+	if ctx.Err() != nil {
 		return 0, err
 	}
+	j.logger.Debug("ExactOut quote failed, falling back to inverse ExactIn", "err", err)
 
-	resp, err := j.client.Quote(ctx, inMint, outMint, inUnit, opts)
+	quoteAtoms := int64(math.Round(quoteHint))
+	if quoteAtoms <= 0 {
+		return 0, fmt.Errorf("cannot estimate inverse quote amount")
+	}
+	inv, err := j.client.Quote(ctx, mints.QuoteMint, mints.BaseMint, quoteAtoms, j.quoteOptions(jupiter.SwapModeExactIn))
 	if err != nil {
 		return 0, err
 	}
-	if resp == nil {
-		return 0, fmt.Errorf("empty response from jupiter")
+	if err := j.checkPriceImpact(inv); err != nil {
+		return 0, err
 	}
-
-	outAtoms, err := strconv.ParseFloat(resp.OutAmount, 64)
+	baseOut, err := worstAmount(inv.OtherAmountThreshold, inv.OutAmount)
 	if err != nil {
-		return 0, fmt.Errorf("parse OutAmount: %w", err)
+		return 0, err
+	}
+	if baseOut <= 0 {
+		return 0, fmt.Errorf("zero inverse quote")
 	}
+	return float64(quoteAtoms) * float64(baseAtoms) / baseOut, nil
+}
+
+// listing возвращает листинг пары из реестра и 10^decimals её токенов.
+func (j *Adapter) listing(pair string) (l market.Listing, baseUnit, quoteUnit int64, err error) {
+	l, ok := j.markets.Listing(j.Name(), pair)
+	if !ok || l.BaseMint == "" || l.QuoteMint == "" {
+		return market.Listing{}, 0, 0, fmt.Errorf("неизвестная пара %s", pair)
+	}
+	if baseUnit, err = j.unitAmount(l.BaseMint, l.BaseDecimals); err != nil {
+		return market.Listing{}, 0, 0, err
+	}
+	if quoteUnit, err = j.unitAmount(l.QuoteMint, l.QuoteDecimals); err != nil {
+		return market.Listing{}, 0, 0, err
+	}
+	return l, baseUnit, quoteUnit, nil
+}
+
+// unitAmount возвращает 10^decimals токена: из листинга, а если decimals в нём не заданы —
+// из списка токенов Jupiter.
+func (j *Adapter) unitAmount(mint string, decimals uint8) (int64, error) {
+	if decimals > 0 {
+		return int64(math.Pow10(int(decimals))), nil
+	}
+	if j.tokens == nil {
+		return 0, fmt.Errorf("decimals not configured for mint %s", mint)
+	}
+	return j.tokens.UnitAmountByMint(mint)
+}
+
+// quoteOptions собирает опции запроса котировки с учётом режима и слиппеджа адаптера.
+func (j *Adapter) quoteOptions(mode jupiter.SwapMode) *jupiter.QuoteOptions {
+	opts := jupiter.DefaultQuoteOptions()
+	opts.SwapMode = &mode
+	if j.slippageBps > 0 {
+		slippage := j.slippageBps
+		opts.SlippageBps = &slippage
+	}
+	return opts
+}
 
-	outUnit, err := jupiter.UnitAmountByMint(outMint) // 10^decimals(OUT)
+// checkPriceImpact отклоняет котировки с ценовым влиянием выше допустимого.
+func (j *Adapter) checkPriceImpact(resp *jupiter.QuoteResponse) error {
+	if resp == nil {
+		return fmt.Errorf("empty response from jupiter")
+	}
+	if j.maxPriceImpactPct <= 0 || resp.PriceImpactPct == "" {
+		return nil
+	}
+	impact, err := strconv.ParseFloat(resp.PriceImpactPct, 64)
 	if err != nil {
-		return 0, err
+		return fmt.Errorf("parse PriceImpactPct: %w", err)
 	}
+	if math.Abs(impact) > j.maxPriceImpactPct {
+		return fmt.Errorf("price impact %.4f%% exceeds limit %.4f%%", impact, j.maxPriceImpactPct)
+	}
+	return nil
+}
 
-	return outAtoms / float64(outUnit), nil
+// worstAmount возвращает порог с учётом слиппеджа, а при его отсутствии — номинальную сумму.
+func worstAmount(threshold, nominal string) (float64, error) {
+	raw := threshold
+	if raw == "" || raw == "0" {
+		raw = nominal
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse amount %q: %w", raw, err)
+	}
+	return v, nil
 }
 
 // TradingFee Jupiter комиссия 0 (только сеть).
@@ -0,0 +1,172 @@
+package jupiter
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dimryb/cross-arb/internal/logger"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+const (
+	solMint  = "So11111111111111111111111111111111111111112"
+	usdtMint = "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB"
+)
+
+// quoteReply — ответ тестового сервера на /quote; status != 0 — ошибка с этим статусом.
+type quoteReply struct {
+	status               int
+	inAmount, outAmount  string
+	otherAmountThreshold string
+	priceImpactPct       string
+}
+
+// quoteKey — направление и режим котировки.
+type quoteKey struct {
+	inputMint string
+	swapMode  string
+}
+
+func newTestAdapter(t *testing.T, replies map[quoteKey]quoteReply) *Adapter {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/quote" || q.Get("slippageBps") != "50" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		reply, ok := replies[quoteKey{inputMint: q.Get("inputMint"), swapMode: q.Get("swapMode")}]
+		if !ok || reply.status != 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"ROUTE_NOT_FOUND","message":"no route"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"inputMint":            q.Get("inputMint"),
+			"outputMint":           q.Get("outputMint"),
+			"inAmount":             reply.inAmount,
+			"outAmount":            reply.outAmount,
+			"otherAmountThreshold": reply.otherAmountThreshold,
+			"swapMode":             q.Get("swapMode"),
+			"priceImpactPct":       reply.priceImpactPct,
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	reg := market.NewRegistry()
+	if err := reg.Add(market.Listing{
+		Venue:         "jupiter",
+		Instrument:    market.Instrument{Base: "SOL", Quote: "USDT"},
+		BaseMint:      solMint,
+		QuoteMint:     usdtMint,
+		BaseDecimals:  9,
+		QuoteDecimals: 6,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return NewAdapter(logger.New("error"), &AdapterConfig{
+		BaseURL:           srv.URL,
+		Markets:           reg,
+		SlippageBps:       50,
+		MaxPriceImpactPct: 1,
+	})
+}
+
+// sell2SOL — продажа 2 SOL: номинально 201 USDT, с учётом слиппеджа не меньше 200.
+var sell2SOL = quoteReply{inAmount: "2000000000", outAmount: "201000000", otherAmountThreshold: "200000000", priceImpactPct: "0.1"}
+
+func TestAdapter_Quote(t *testing.T) {
+	t.Run("exact out", func(t *testing.T) {
+		ad := newTestAdapter(t, map[quoteKey]quoteReply{
+			{solMint, "ExactIn"}: sell2SOL,
+			// Покупка 2 SOL: номинально 202 USDT, с учётом слиппеджа не больше 203.
+			{usdtMint, "ExactOut"}: {inAmount: "202000000", outAmount: "2000000000", otherAmountThreshold: "203000000"},
+		})
+		bid, ask, err := ad.Quote(context.Background(), "SOL/USDT", 2)
+		if err != nil {
+			t.Fatalf("Quote: %v", err)
+		}
+		// Bid — выручка от продажи, Ask — стоимость покупки, обе по порогу слиппеджа.
+		if bid != 100 || ask != 101.5 {
+			t.Errorf("bid/ask = %v/%v, want 100/101.5", bid, ask)
+		}
+	})
+
+	t.Run("inverse exact in fallback", func(t *testing.T) {
+		ad := newTestAdapter(t, map[quoteKey]quoteReply{
+			{solMint, "ExactIn"}:   sell2SOL,
+			{usdtMint, "ExactOut"}: {status: http.StatusBadRequest},
+			// За 200 USDT (выручка продажи) — 1.96 SOL, без порога слиппеджа.
+			{usdtMint, "ExactIn"}: {inAmount: "200000000", outAmount: "1960000000"},
+		})
+		bid, ask, err := ad.Quote(context.Background(), "SOL/USDT", 2)
+		if err != nil {
+			t.Fatalf("Quote: %v", err)
+		}
+		// 200 USDT * 2 / 1.96 SOL = 204.08 USDT за 2 SOL.
+		if bid != 100 || math.Abs(ask-200/1.96) > 1e-9 {
+			t.Errorf("bid/ask = %v/%v, want 100/%v", bid, ask, 200/1.96)
+		}
+	})
+
+	t.Run("nominal amount without threshold", func(t *testing.T) {
+		ad := newTestAdapter(t, map[quoteKey]quoteReply{
+			{solMint, "ExactIn"}:   {inAmount: "2000000000", outAmount: "201000000"},
+			{usdtMint, "ExactOut"}: {inAmount: "202000000", outAmount: "2000000000", otherAmountThreshold: "0"},
+		})
+		bid, ask, err := ad.Quote(context.Background(), "SOL/USDT", 2)
+		if err != nil {
+			t.Fatalf("Quote: %v", err)
+		}
+		if bid != 100.5 || ask != 101 {
+			t.Errorf("bid/ask = %v/%v, want 100.5/101", bid, ask)
+		}
+	})
+
+	t.Run("price impact above limit", func(t *testing.T) {
+		ad := newTestAdapter(t, map[quoteKey]quoteReply{
+			{solMint, "ExactIn"}:   {inAmount: "2000000000", outAmount: "201000000", priceImpactPct: "2.5"},
+			{usdtMint, "ExactOut"}: {inAmount: "202000000", outAmount: "2000000000"},
+		})
+		if _, _, err := ad.Quote(context.Background(), "SOL/USDT", 2); err == nil {
+			t.Error("expected price impact error")
+		}
+		if _, err := ad.SwapQuote(context.Background(), "SOL/USDT", false, 2); err == nil {
+			t.Error("expected price impact error from SwapQuote")
+		}
+	})
+
+	t.Run("unknown pair", func(t *testing.T) {
+		ad := newTestAdapter(t, nil)
+		if _, _, err := ad.Quote(context.Background(), "BTC/USDT", 1); err == nil {
+			t.Error("expected error for unlisted pair")
+		}
+	})
+}
+
+func TestAdapter_SwapQuote(t *testing.T) {
+	ad := newTestAdapter(t, map[quoteKey]quoteReply{
+		{solMint, "ExactIn"}:   sell2SOL,
+		{usdtMint, "ExactOut"}: {inAmount: "202000000", outAmount: "2000000000", otherAmountThreshold: "203000000"},
+	})
+
+	buy, err := ad.SwapQuote(context.Background(), "SOL/USDT", true, 2)
+	if err != nil {
+		t.Fatalf("SwapQuote buy: %v", err)
+	}
+	if buy.Base != 2 || buy.Quote != 202 || buy.Response.SwapMode != "ExactOut" {
+		t.Errorf("buy = %+v", buy)
+	}
+
+	sell, err := ad.SwapQuote(context.Background(), "SOL/USDT", false, 2)
+	if err != nil {
+		t.Fatalf("SwapQuote sell: %v", err)
+	}
+	if sell.Base != 2 || sell.Quote != 201 {
+		t.Errorf("sell = %+v", sell)
+	}
+}
@@ -1,7 +0,0 @@
-package jupiter
-
-// MintPair хранит адреса SPL-токенов.
-type MintPair struct {
-	BaseMint  string
-	QuoteMint string
-}
@@ -0,0 +1,68 @@
+package jupiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/dimryb/cross-arb/internal/api/jupiter"
+)
+
+// SwapQuote — исполнимая котировка Jupiter для одной ноги сделки.
+// Base/Quote — ожидаемые объёмы обмена в целых токенах (по номинальным InAmount/OutAmount).
+type SwapQuote struct {
+	Response *jupiter.QuoteResponse
+	Base     float64
+	Quote    float64
+}
+
+// SwapQuote запрашивает котировку, пригодную для Swapper.SwapWithQuote:
+//
+//   - buy == false — продажа ровно baseAmount BASE (ExactIn BASE → QUOTE);
+//   - buy == true — покупка ровно baseAmount BASE (ExactOut QUOTE → BASE).
+//
+// Учитываются слиппедж и ограничение ценового влияния адаптера.
+func (j *Adapter) SwapQuote(ctx context.Context, pair string, buy bool, baseAmount float64) (SwapQuote, error) {
+	if baseAmount <= 0 {
+		return SwapQuote{}, fmt.Errorf("baseAmount must be positive, got %v", baseAmount)
+	}
+	mints, baseUnit, quoteUnit, err := j.listing(pair)
+	if err != nil {
+		return SwapQuote{}, err
+	}
+	baseAtoms := int64(math.Round(baseAmount * float64(baseUnit)))
+	if baseAtoms <= 0 {
+		return SwapQuote{}, fmt.Errorf("baseAmount %v is below token precision", baseAmount)
+	}
+
+	var resp *jupiter.QuoteResponse
+	if buy {
+		resp, err = j.client.Quote(ctx, mints.QuoteMint, mints.BaseMint, baseAtoms, j.quoteOptions(jupiter.SwapModeExactOut))
+	} else {
+		resp, err = j.client.Quote(ctx, mints.BaseMint, mints.QuoteMint, baseAtoms, j.quoteOptions(jupiter.SwapModeExactIn))
+	}
+	if err != nil {
+		return SwapQuote{}, err
+	}
+	if err := j.checkPriceImpact(resp); err != nil {
+		return SwapQuote{}, err
+	}
+
+	in, err := strconv.ParseFloat(resp.InAmount, 64)
+	if err != nil {
+		return SwapQuote{}, fmt.Errorf("parse inAmount %q: %w", resp.InAmount, err)
+	}
+	out, err := strconv.ParseFloat(resp.OutAmount, 64)
+	if err != nil {
+		return SwapQuote{}, fmt.Errorf("parse outAmount %q: %w", resp.OutAmount, err)
+	}
+
+	q := SwapQuote{Response: resp}
+	if buy {
+		q.Base, q.Quote = out/float64(baseUnit), in/float64(quoteUnit)
+	} else {
+		q.Base, q.Quote = in/float64(baseUnit), out/float64(quoteUnit)
+	}
+	return q, nil
+}
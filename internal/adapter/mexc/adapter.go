@@ -1,19 +1,37 @@
 package mexc
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/dimryb/cross-arb/internal/api/mexc/utils"
+	"github.com/dimryb/cross-arb/internal/entity"
 	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/market"
 )
 
-// Adapter реализует доступ к публичному REST-API биржи MEXC.
-// Используется только энд-поинт depth, поэтому ключ и секрет
-// не обязательны. Для боевой торговли стоит добавить WebSocket-стримы.
+var _ i.CEXAdapter = (*Adapter)(nil)
+
+// Adapter реализует доступ к публичным данным биржи MEXC: стаканы и торговые фильтры
+// (exchangeInfo). Стаканы отдаются из OrderBookSource (WebSocket-клиент), а REST depth
+// запрашивается, только если стакан в памяти недоступен. Ключ и секрет не нужны.
 type Adapter struct {
 	client  *http.Client
 	baseURL string
 	logger  i.Logger
+	books   OrderBookSource
+	markets *market.Registry
+}
+
+// OrderBookSource — источник локально поддерживаемых стаканов (например, WebSocket-клиент).
+type OrderBookSource interface {
+	// OrderBook возвращает стакан символа MEXC; ok == false, если стакан сейчас недоступен.
+	OrderBook(symbol string, limit int) (book entity.OrderBook, ok bool)
 }
 
 // NewAdapter возвращает готовый к работе адаптер.
@@ -29,6 +47,18 @@ func NewAdapter(l i.Logger, clientTimeout time.Duration) *Adapter {
 	}
 }
 
+// SetOrderBookSource подключает источник стаканов в памяти.
+// OrderBookDepth обслуживается из него, а к REST обращается только когда стакан недоступен.
+func (m *Adapter) SetOrderBookSource(src OrderBookSource) {
+	m.books = src
+}
+
+// SetMarkets подключает реестр инструментов: через него пары переводятся в символы MEXC,
+// в него же LoadFilters сохраняет торговые фильтры. Без реестра символ — Base+Quote.
+func (m *Adapter) SetMarkets(reg *market.Registry) {
+	m.markets = reg
+}
+
 // Name удовлетворяет интерфейсу EXAdapter.
 func (m *Adapter) Name() string { return "mexc" }
 
@@ -37,3 +67,58 @@ func (m *Adapter) TradingFee(string) (maker, taker float64) { return 0.001, 0.00
 
 // Close удовлетворяет интерфейсу, доп. ресурсы не удерживаются.
 func (m *Adapter) Close() error { return nil }
+
+// Symbol переводит пару ("SOL/USDT") в символ MEXC ("SOLUSDT") через реестр инструментов.
+func (m *Adapter) Symbol(pair string) string {
+	return m.markets.Symbol(m.Name(), pair)
+}
+
+// Filters возвращает торговые фильтры пары, загруженные LoadFilters; ok == false,
+// если фильтры ещё не загружены.
+func (m *Adapter) Filters(pair string) (market.Filters, bool) {
+	return m.markets.Filters(m.Name(), pair)
+}
+
+// get выполняет публичный GET-запрос и декодирует ответ в out.
+// Ошибки MEXC (в том числе ответ 200 с кодом ошибки в теле) возвращаются как *utils.APIError.
+func (m *Adapter) get(ctx context.Context, path string, q url.Values, out any) error {
+	requestURL := m.baseURL + path
+	if len(q) > 0 {
+		requestURL += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("build %s request: %w", path, err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request: %w", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read %s response: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return utils.ParseAPIError(resp.StatusCode, body)
+	}
+
+	var status struct {
+		Code *int   `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return fmt.Errorf("decode %s response: %w", path, err)
+	}
+	// MEXC иногда отвечает 200 с кодом ошибки в теле.
+	if status.Code != nil && *status.Code != 0 && *status.Code != http.StatusOK {
+		return &utils.APIError{StatusCode: resp.StatusCode, Code: *status.Code, Msg: status.Msg}
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode %s response: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package mexc
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dimryb/cross-arb/internal/adapter/cextest"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+func TestAdapter_CEXContract(t *testing.T) {
+	cextest.Run(t, cextest.Venue{
+		Symbol: "SOLUSDT",
+		New: func(_ *testing.T, baseURL string) i.CEXAdapter {
+			a := NewAdapter(logger.New("error"), 0)
+			a.baseURL = baseURL
+			return a
+		},
+		ServeDepth: func(w http.ResponseWriter, r *http.Request, symbol string, limit int, book cextest.Book) {
+			q := r.URL.Query()
+			if r.URL.Path != "/api/v3/depth" || q.Get("symbol") != symbol || q.Get("limit") != strconv.Itoa(limit) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"lastUpdateId":1,"bids":%s,"asks":%s}`, levels(book.Bids), levels(book.Asks))
+		},
+		ServeError: func(w http.ResponseWriter, status int) {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+		},
+	})
+}
+
+func levels(ls []cextest.Level) string {
+	parts := make([]string, len(ls))
+	for n, l := range ls {
+		parts[n] = fmt.Sprintf("[%q,%q]", l.Price, l.Qty)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
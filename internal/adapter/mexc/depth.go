@@ -0,0 +1,104 @@
+package mexc
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	"github.com/dimryb/cross-arb/internal/orderbook"
+)
+
+// maxDepthLimit — максимальная глубина, которую отдаёт /api/v3/depth.
+const maxDepthLimit = 5000
+
+// OrderBookDepth удовлетворяет интерфейсу CEXAdapter.
+// Если подключён OrderBookSource и в нём есть стакан, он отдаётся из памяти;
+// иначе запрашивается /api/v3/depth. pair может быть как "SOL/USDT", так и "SOLUSDT";
+// пара переводится в символ MEXC через реестр инструментов.
+// При limit <= 0 используется глубина по умолчанию на стороне MEXC.
+// Ошибки MEXC возвращаются как *utils.APIError.
+func (m *Adapter) OrderBookDepth(ctx context.Context, pair string, limit int) (entity.OrderBook, error) {
+	symbol := m.Symbol(pair)
+	if symbol == "" {
+		return entity.OrderBook{}, fmt.Errorf("empty pair")
+	}
+	if m.books != nil {
+		if book, ok := m.books.OrderBook(symbol, limit); ok {
+			return book, nil
+		}
+	}
+
+	snap, err := m.fetchDepth(ctx, symbol, limit)
+	if err != nil {
+		return entity.OrderBook{}, err
+	}
+	return entity.OrderBook{Bids: snap.Bids, Asks: snap.Asks}, nil
+}
+
+// DepthSnapshot запрашивает /api/v3/depth и возвращает снапшот с lastUpdateId —
+// основу для локального стакана, собираемого из инкрементальных обновлений.
+// Подходит как orderbook.SnapshotFunc после фиксации limit.
+func (m *Adapter) DepthSnapshot(ctx context.Context, pair string, limit int) (orderbook.Snapshot, error) {
+	symbol := m.Symbol(pair)
+	if symbol == "" {
+		return orderbook.Snapshot{}, fmt.Errorf("empty pair")
+	}
+	return m.fetchDepth(ctx, symbol, limit)
+}
+
+func (m *Adapter) fetchDepth(ctx context.Context, symbol string, limit int) (orderbook.Snapshot, error) {
+	q := url.Values{}
+	q.Set("symbol", symbol)
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(min(limit, maxDepthLimit)))
+	}
+	var raw struct {
+		LastUpdateID int64      `json:"lastUpdateId"`
+		Bids         [][]string `json:"bids"`
+		Asks         [][]string `json:"asks"`
+	}
+	if err := m.get(ctx, "/api/v3/depth", q, &raw); err != nil {
+		return orderbook.Snapshot{}, err
+	}
+
+	bids, err := parseLevels(raw.Bids)
+	if err != nil {
+		return orderbook.Snapshot{}, fmt.Errorf("bids: %w", err)
+	}
+	asks, err := parseLevels(raw.Asks)
+	if err != nil {
+		return orderbook.Snapshot{}, fmt.Errorf("asks: %w", err)
+	}
+
+	sort.SliceStable(bids, func(a, b int) bool { return bids[a].Price > bids[b].Price })
+	sort.SliceStable(asks, func(a, b int) bool { return asks[a].Price < asks[b].Price })
+
+	return orderbook.Snapshot{LastUpdateID: raw.LastUpdateID, Bids: bids, Asks: asks}, nil
+}
+
+// parseLevels разбирает уровни стакана вида [["price","qty"], ...].
+// Уровни с нулевым объёмом пропускаются.
+func parseLevels(raw [][]string) ([]entity.Order, error) {
+	levels := make([]entity.Order, 0, len(raw))
+	for _, item := range raw {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("malformed level %v", item)
+		}
+		price, err := strconv.ParseFloat(item[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse price %q: %w", item[0], err)
+		}
+		qty, err := strconv.ParseFloat(item[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse quantity %q: %w", item[1], err)
+		}
+		if price <= 0 || qty <= 0 {
+			continue
+		}
+		levels = append(levels, entity.Order{Price: price, Quantity: qty})
+	}
+	return levels, nil
+}
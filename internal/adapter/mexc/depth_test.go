@@ -0,0 +1,98 @@
+package mexc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dimryb/cross-arb/internal/api/mexc/utils"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+func newTestAdapter(t *testing.T, h http.HandlerFunc) *Adapter {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	a := NewAdapter(logger.New("error"), 0)
+	a.baseURL = srv.URL
+	return a
+}
+
+func TestAdapter_OrderBookDepth(t *testing.T) {
+	a := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/depth" {
+			t.Errorf("path = %s, want /api/v3/depth", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("symbol"); got != "SOLUSDT" {
+			t.Errorf("symbol = %s, want SOLUSDT", got)
+		}
+		if got := r.URL.Query().Get("limit"); got != "5" {
+			t.Errorf("limit = %s, want 5", got)
+		}
+		_, _ = w.Write([]byte(`{"lastUpdateId":1,
+			"bids":[["99.5","2"],["100","1"],["98","0"]],
+			"asks":[["101.5","3"],["101","4"]]}`))
+	})
+
+	book, err := a.OrderBookDepth(context.Background(), "SOL/USDT", 5)
+	if err != nil {
+		t.Fatalf("OrderBookDepth: %v", err)
+	}
+
+	if len(book.Bids) != 2 || book.Bids[0].Price != 100 || book.Bids[1].Price != 99.5 {
+		t.Errorf("bids not sorted descending or zero level kept: %+v", book.Bids)
+	}
+	if len(book.Asks) != 2 || book.Asks[0].Price != 101 || book.Asks[0].Quantity != 4 {
+		t.Errorf("asks not sorted ascending: %+v", book.Asks)
+	}
+}
+
+func TestAdapter_OrderBookDepth_APIError(t *testing.T) {
+	a := newTestAdapter(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+	})
+
+	_, err := a.OrderBookDepth(context.Background(), "FOO/BAR", 5)
+	if !errors.Is(err, utils.ErrInvalidSymbol) {
+		t.Fatalf("err = %v, want ErrInvalidSymbol", err)
+	}
+
+	var apiErr *utils.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("err = %#v, want *APIError with status 400", err)
+	}
+}
+
+func TestAdapter_OrderBookDepth_RespectsContext(t *testing.T) {
+	a := newTestAdapter(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"bids":[],"asks":[]}`))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := a.OrderBookDepth(ctx, "SOL/USDT", 5); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestAdapter_DepthSnapshot(t *testing.T) {
+	a := newTestAdapter(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"lastUpdateId":4242,"bids":[["99","1"]],"asks":[["101","2"]]}`))
+	})
+
+	snap, err := a.DepthSnapshot(context.Background(), "SOLUSDT", 100)
+	if err != nil {
+		t.Fatalf("DepthSnapshot: %v", err)
+	}
+	if snap.LastUpdateID != 4242 {
+		t.Errorf("LastUpdateID = %d, want 4242", snap.LastUpdateID)
+	}
+	if len(snap.Bids) != 1 || len(snap.Asks) != 1 {
+		t.Errorf("snapshot = %+v, want one level per side", snap)
+	}
+}
@@ -0,0 +1,107 @@
+package mexc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+// ExchangeInfo запрашивает /api/v3/exchangeInfo и возвращает торговые фильтры символов.
+// Пустой symbols — все символы биржи.
+//
+// MEXC задаёт точность числом знаков: шаг цены — 10^-quotePrecision, шаг количества —
+// 10^-baseAssetPrecision; минимальное количество — baseSizePrecision, минимальная
+// сумма ордера — quoteAmountPrecision.
+func (m *Adapter) ExchangeInfo(ctx context.Context, symbols []string) (map[string]market.Filters, error) {
+	q := url.Values{}
+	if len(symbols) > 0 {
+		q.Set("symbols", strings.Join(symbols, ","))
+	}
+	var raw struct {
+		Symbols []struct {
+			Symbol               string `json:"symbol"`
+			BaseAssetPrecision   int    `json:"baseAssetPrecision"`
+			QuotePrecision       int    `json:"quotePrecision"`
+			BaseSizePrecision    string `json:"baseSizePrecision"`
+			QuoteAmountPrecision string `json:"quoteAmountPrecision"`
+		} `json:"symbols"`
+	}
+	if err := m.get(ctx, "/api/v3/exchangeInfo", q, &raw); err != nil {
+		return nil, err
+	}
+
+	filters := make(map[string]market.Filters, len(raw.Symbols))
+	for _, s := range raw.Symbols {
+		minQty, err := parseOptionalFloat(s.BaseSizePrecision)
+		if err != nil {
+			return nil, fmt.Errorf("%s baseSizePrecision: %w", s.Symbol, err)
+		}
+		minNotional, err := parseOptionalFloat(s.QuoteAmountPrecision)
+		if err != nil {
+			return nil, fmt.Errorf("%s quoteAmountPrecision: %w", s.Symbol, err)
+		}
+		filters[s.Symbol] = market.Filters{
+			TickSize:    math.Pow10(-s.QuotePrecision),
+			StepSize:    math.Pow10(-s.BaseAssetPrecision),
+			MinQty:      minQty,
+			MinNotional: minNotional,
+		}
+	}
+	return filters, nil
+}
+
+// LoadFilters загружает фильтры листингов MEXC из реестра и сохраняет их в реестре.
+func (m *Adapter) LoadFilters(ctx context.Context) error {
+	listings := m.markets.Listings(m.Name())
+	if len(listings) == 0 {
+		return nil
+	}
+	symbols := make([]string, 0, len(listings))
+	for _, l := range listings {
+		symbols = append(symbols, l.Symbol)
+	}
+	filters, err := m.ExchangeInfo(ctx, symbols)
+	if err != nil {
+		return err
+	}
+	for _, symbol := range symbols {
+		f, ok := filters[symbol]
+		if !ok {
+			m.logger.Warn("symbol missing from exchangeInfo", "symbol", symbol)
+			continue
+		}
+		m.markets.SetFilters(m.Name(), symbol, f)
+	}
+	return nil
+}
+
+// RefreshFilters перезагружает фильтры каждые interval до отмены ctx, чтобы изменения
+// шагов цены и количества на бирже подхватывались без перезапуска. Ошибки логируются,
+// прежние фильтры при этом сохраняются.
+func (m *Adapter) RefreshFilters(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.LoadFilters(ctx); err != nil && ctx.Err() == nil {
+				m.logger.Warn("failed to refresh exchange info", "err", err)
+			}
+		}
+	}
+}
+
+func parseOptionalFloat(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,58 @@
+package mexc
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+const exchangeInfoBody = `{"timezone":"CST","serverTime":1700000000000,"symbols":[
+	{"symbol":"SOLUSDT","status":"1","baseAsset":"SOL","quoteAsset":"USDT",
+	 "baseAssetPrecision":3,"quotePrecision":2,"baseSizePrecision":"0.01","quoteAmountPrecision":"1"},
+	{"symbol":"SOLUSDC","status":"1","baseAsset":"SOL","quoteAsset":"USDC",
+	 "baseAssetPrecision":2,"quotePrecision":3,"baseSizePrecision":"0","quoteAmountPrecision":"5"}]}`
+
+func TestAdapter_LoadFilters(t *testing.T) {
+	a := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/exchangeInfo" {
+			t.Errorf("path = %s, want /api/v3/exchangeInfo", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("symbols"); got != "SOLUSDC,SOLUSDT" {
+			t.Errorf("symbols = %s, want SOLUSDC,SOLUSDT", got)
+		}
+		_, _ = w.Write([]byte(exchangeInfoBody))
+	})
+	reg := market.NewRegistry()
+	for _, quote := range []string{"USDT", "USDC"} {
+		if err := reg.Add(market.Listing{Venue: "mexc", Instrument: market.Instrument{Base: "SOL", Quote: quote}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.SetMarkets(reg)
+
+	if err := a.LoadFilters(context.Background()); err != nil {
+		t.Fatalf("LoadFilters: %v", err)
+	}
+
+	l, _ := reg.Listing("mexc", "SOL/USDT")
+	f := l.Filters
+	if math.Abs(f.TickSize-0.01) > 1e-12 || math.Abs(f.StepSize-0.001) > 1e-12 || f.MinQty != 0.01 || f.MinNotional != 1 {
+		t.Errorf("SOL/USDT filters = %+v", f)
+	}
+	l, _ = reg.Listing("mexc", "SOL/USDC")
+	if f := l.Filters; math.Abs(f.TickSize-0.001) > 1e-12 || f.MinQty != 0 || f.MinNotional != 5 {
+		t.Errorf("SOL/USDC filters = %+v", f)
+	}
+}
+
+func TestAdapter_ExchangeInfo_APIError(t *testing.T) {
+	a := newTestAdapter(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+	})
+	if _, err := a.ExchangeInfo(context.Background(), []string{"NOPE"}); err == nil {
+		t.Error("expected error for code in 200 response")
+	}
+}
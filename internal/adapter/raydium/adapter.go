@@ -0,0 +1,155 @@
+package raydium
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+var _ i.DEXAdapter = (*Adapter)(nil)
+
+// AccountReader читает данные аккаунтов Solana одним запросом (solana.Client).
+type AccountReader interface {
+	// AccountsData возвращает данные аккаунтов в порядке адресов; nil — аккаунт не найден.
+	AccountsData(ctx context.Context, addresses []string) ([][]byte, error)
+}
+
+// Adapter реализует DEXAdapter по состоянию пулов Raydium AMM v4 (constant product)
+// напрямую из блокчейна, без агрегатора: так видно, когда маршрут Jupiter хуже или устарел.
+//
+// На каждую котировку одним запросом читаются состояние пула и оба хранилища (vault);
+// резервы — остатки хранилищ за вычетом накопленной, но не снятой прибыли пула
+// (need_take_pnl). Ликвидность, выставленная пулом в стакан OpenBook, не учитывается.
+// Комиссия свопа уже заложена в цены, поэтому TradingFee возвращает 0.
+type Adapter struct {
+	reader  AccountReader
+	markets *market.Registry
+	logger  i.Logger
+
+	mu     sync.Mutex
+	states map[string]ammState // адрес пула → последнее состояние (адреса хранилищ)
+}
+
+// NewAdapter создаёт адаптер. Листинги площадки "raydium" в реестре markets задают
+// адрес пула (Address) и ожидаемые mint-адреса BASE и QUOTE.
+func NewAdapter(l i.Logger, reader AccountReader, markets *market.Registry) *Adapter {
+	return &Adapter{
+		reader:  reader,
+		markets: markets,
+		logger:  l.Named("raydium"),
+		states:  make(map[string]ammState),
+	}
+}
+
+// Name удовлетворяет интерфейсу EXAdapter.
+func (a *Adapter) Name() string { return "raydium" }
+
+// TradingFee удовлетворяет интерфейсу EXAdapter: комиссия пула уже учтена в Quote.
+func (a *Adapter) TradingFee(string) (maker, taker float64) { return 0, 0 }
+
+// Close дополнительных ресурсов нет.
+func (a *Adapter) Close() error { return nil }
+
+// Quote удовлетворяет интерфейсу DEXAdapter: эффективные цены в QUOTE за 1 BASE
+// для объёма baseAmount по формуле постоянного произведения с комиссией пула:
+//
+//   - bid — выручка за продажу baseAmount BASE (ExactIn BASE → QUOTE);
+//   - ask — стоимость покупки baseAmount BASE (ExactOut QUOTE → BASE).
+func (a *Adapter) Quote(ctx context.Context, pair string, baseAmount float64) (bid, ask float64, err error) {
+	if baseAmount <= 0 {
+		return 0, 0, fmt.Errorf("baseAmount must be positive, got %v", baseAmount)
+	}
+	pool, ok := a.markets.Listing(a.Name(), pair)
+	if !ok || pool.Address == "" {
+		return 0, 0, fmt.Errorf("no raydium pool configured for %s", pair)
+	}
+
+	r, err := a.reserves(ctx, pool)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s pool %s: %w", pair, pool.Address, err)
+	}
+	cost, err := r.buy(baseAmount)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s pool %s: %w", pair, pool.Address, err)
+	}
+	return r.sell(baseAmount) / baseAmount, cost / baseAmount, nil
+}
+
+// reserves читает пул и его хранилища и возвращает резервы в ориентации пары.
+func (a *Adapter) reserves(ctx context.Context, pool market.Listing) (reserves, error) {
+	a.mu.Lock()
+	known, cached := a.states[pool.Address]
+	a.mu.Unlock()
+
+	addresses := []string{pool.Address}
+	if cached {
+		addresses = append(addresses, known.BaseVault, known.QuoteVault)
+	}
+	data, err := a.reader.AccountsData(ctx, addresses)
+	if err != nil {
+		return reserves{}, err
+	}
+	if len(data) != len(addresses) || data[0] == nil {
+		return reserves{}, fmt.Errorf("pool account not found")
+	}
+	state, err := decodeAmmState(data[0])
+	if err != nil {
+		return reserves{}, err
+	}
+	if err := checkMints(pool, state); err != nil {
+		return reserves{}, err
+	}
+
+	// Хранилища пула неизменны; если всё же изменились (или пул читается впервые), дочитываем.
+	if !cached || state.BaseVault != known.BaseVault || state.QuoteVault != known.QuoteVault {
+		data, err = a.reader.AccountsData(ctx, []string{pool.Address, state.BaseVault, state.QuoteVault})
+		if err != nil {
+			return reserves{}, err
+		}
+		if len(data) != 3 || data[0] == nil {
+			return reserves{}, fmt.Errorf("pool account not found")
+		}
+		if state, err = decodeAmmState(data[0]); err != nil {
+			return reserves{}, err
+		}
+	}
+	a.mu.Lock()
+	a.states[pool.Address] = state
+	a.mu.Unlock()
+
+	baseVault, err := tokenAmount(data[1])
+	if err != nil {
+		return reserves{}, fmt.Errorf("base vault: %w", err)
+	}
+	quoteVault, err := tokenAmount(data[2])
+	if err != nil {
+		return reserves{}, fmt.Errorf("quote vault: %w", err)
+	}
+	if baseVault <= state.BaseNeedTakePnl || quoteVault <= state.QuoteNeedTakePnl {
+		return reserves{}, errInsufficientLiquidity
+	}
+
+	r := reserves{
+		Base:  scale(baseVault-state.BaseNeedTakePnl, state.BaseDecimals),
+		Quote: scale(quoteVault-state.QuoteNeedTakePnl, state.QuoteDecimals),
+		Fee:   float64(state.FeeNum) / float64(state.FeeDenom),
+	}
+	if state.BaseMint != pool.BaseMint {
+		r.Base, r.Quote = r.Quote, r.Base
+	}
+	return r, nil
+}
+
+// checkMints проверяет, что пул торгует именно mint-адресами пары (в любой ориентации).
+func checkMints(pool market.Listing, s ammState) error {
+	direct := s.BaseMint == pool.BaseMint && s.QuoteMint == pool.QuoteMint
+	inverted := s.BaseMint == pool.QuoteMint && s.QuoteMint == pool.BaseMint
+	if !direct && !inverted {
+		return fmt.Errorf("pool mints %s/%s do not match pair mints %s/%s",
+			s.BaseMint, s.QuoteMint, pool.BaseMint, pool.QuoteMint)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package raydium
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dimryb/cross-arb/internal/logger"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+const (
+	solMint  = "So11111111111111111111111111111111111111112"
+	usdtMint = "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB"
+)
+
+func solUSDT(pool string) market.Listing {
+	return market.Listing{
+		Venue:      "raydium",
+		Instrument: market.Instrument{Base: "SOL", Quote: "USDT"},
+		BaseMint:   solMint,
+		QuoteMint:  usdtMint,
+		Address:    pool,
+	}
+}
+
+func markets(t *testing.T, listings ...market.Listing) *market.Registry {
+	t.Helper()
+	reg := market.NewRegistry()
+	for _, l := range listings {
+		if err := reg.Add(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return reg
+}
+
+// fixtureReader отдаёт аккаунты из снимков getAccountInfo в testdata.
+type fixtureReader struct {
+	accounts map[string][]byte
+	calls    int
+}
+
+func loadFixtures(t *testing.T, names ...string) *fixtureReader {
+	t.Helper()
+	r := &fixtureReader{accounts: make(map[string][]byte)}
+	for _, name := range names {
+		raw, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var snap struct {
+			Accounts map[string]struct {
+				Data []string `json:"data"`
+			} `json:"accounts"`
+		}
+		if err := json.Unmarshal(raw, &snap); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for addr, acc := range snap.Accounts {
+			data, err := base64.StdEncoding.DecodeString(acc.Data[0])
+			if err != nil {
+				t.Fatalf("%s %s: %v", name, addr, err)
+			}
+			r.accounts[addr] = data
+		}
+	}
+	return r
+}
+
+func (r *fixtureReader) AccountsData(_ context.Context, addresses []string) ([][]byte, error) {
+	r.calls++
+	out := make([][]byte, len(addresses))
+	for n, addr := range addresses {
+		out[n] = r.accounts[addr]
+	}
+	return out, nil
+}
+
+// Резервы пулов в снимках: 40 000 SOL и 6 000 000 USDT за вычетом need_take_pnl, комиссия 0.25 %.
+const (
+	wantBid = 149.58769656816833 // 6e6 * 9.975 / (40000 + 9.975) / 10
+	wantAsk = 150.41354323543288 // 6e6 * 10 / (40000 - 10) / 0.9975 / 10
+)
+
+func TestAdapter_Quote(t *testing.T) {
+	reader := loadFixtures(t, "sol_usdt_pool.json")
+	a := NewAdapter(logger.New("error"), reader, markets(t, solUSDT("7XawhbbxtsRcQA8KTkHT9f9nc6d69UwqCDh6U5EEbEmX")))
+
+	for range 2 {
+		bid, ask, err := a.Quote(context.Background(), "SOL/USDT", 10)
+		if err != nil {
+			t.Fatalf("Quote: %v", err)
+		}
+		if math.Abs(bid-wantBid) > 1e-9 || math.Abs(ask-wantAsk) > 1e-9 {
+			t.Errorf("bid/ask = %v/%v, want %v/%v", bid, ask, wantBid, wantAsk)
+		}
+	}
+	// Первая котировка читает пул дважды (адреса хранилищ), следующие — одним запросом.
+	if reader.calls != 3 {
+		t.Errorf("rpc calls = %d, want 3", reader.calls)
+	}
+}
+
+func TestAdapter_Quote_InvertedPool(t *testing.T) {
+	a := NewAdapter(logger.New("error"), loadFixtures(t, "usdt_sol_pool.json"),
+		markets(t, solUSDT("CdZpzBM3tRw3QCtJBuUkzjAM6QyE7xXCREppTS6mHiVa")))
+	bid, ask, err := a.Quote(context.Background(), "SOL/USDT", 10)
+	if err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	if math.Abs(bid-wantBid) > 1e-9 || math.Abs(ask-wantAsk) > 1e-9 {
+		t.Errorf("bid/ask = %v/%v, want %v/%v", bid, ask, wantBid, wantAsk)
+	}
+}
+
+func TestAdapter_Quote_Errors(t *testing.T) {
+	reader := loadFixtures(t, "sol_usdt_pool.json")
+	pool := solUSDT("7XawhbbxtsRcQA8KTkHT9f9nc6d69UwqCDh6U5EEbEmX")
+	jup := market.Listing{
+		Venue: "raydium", Instrument: market.Instrument{Base: "JUP", Quote: "USDT"}, Address: pool.Address,
+		BaseMint: "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN", QuoteMint: usdtMint,
+	}
+
+	for name, tc := range map[string]struct {
+		pools  []market.Listing
+		pair   string
+		amount float64
+		want   string
+	}{
+		"no pool":              {pair: "SOL/USDT", amount: 1, want: "no raydium pool"},
+		"zero amount":          {pools: []market.Listing{pool}, pair: "SOL/USDT", amount: 0, want: "must be positive"},
+		"too large":            {pools: []market.Listing{pool}, pair: "SOL/USDT", amount: 40000, want: "insufficient pool liquidity"},
+		"wrong mints":          {pools: []market.Listing{jup}, pair: "JUP/USDT", amount: 1, want: "do not match"},
+		"missing pool account": {pools: []market.Listing{solUSDT("11111111111111111111111111111111")}, pair: "SOL/USDT", amount: 1, want: "not found"},
+	} {
+		_, _, err := NewAdapter(logger.New("error"), reader, markets(t, tc.pools...)).Quote(context.Background(), tc.pair, tc.amount)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", name, err, tc.want)
+		}
+	}
+}
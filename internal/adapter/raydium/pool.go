@@ -0,0 +1,100 @@
+package raydium
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Размер и смещения полей состояния пула Raydium AMM v4 (LIQUIDITY_STATE_LAYOUT_V4).
+const (
+	ammStateSize = 752
+
+	offBaseDecimal      = 32
+	offQuoteDecimal     = 40
+	offSwapFeeNumerator = 176
+	offSwapFeeDenom     = 184
+	offBaseNeedTakePnl  = 192
+	offQuoteNeedTakePnl = 200
+	offBaseVault        = 336
+	offQuoteVault       = 368
+	offBaseMint         = 400
+	offQuoteMint        = 432
+
+	// Токен-аккаунт SPL: mint (32), owner (32), amount (u64), ...
+	tokenAccountSize      = 165
+	offTokenAccountAmount = 64
+)
+
+// ammState — нужные для котировки поля состояния пула AMM v4.
+type ammState struct {
+	BaseDecimals, QuoteDecimals uint8
+	// FeeNum/FeeDenom — комиссия свопа, удерживаемая из входящего объёма.
+	FeeNum, FeeDenom                  uint64
+	BaseNeedTakePnl, QuoteNeedTakePnl uint64
+	BaseVault, QuoteVault             string
+	BaseMint, QuoteMint               string
+}
+
+func decodeAmmState(data []byte) (ammState, error) {
+	if len(data) != ammStateSize {
+		return ammState{}, fmt.Errorf("amm state: got %d bytes, want %d", len(data), ammStateSize)
+	}
+	u64 := func(off int) uint64 { return binary.LittleEndian.Uint64(data[off:]) }
+	key := func(off int) string { return solana.PublicKeyFromBytes(data[off : off+32]).String() }
+
+	s := ammState{
+		BaseDecimals:     uint8(u64(offBaseDecimal)),  //nolint:gosec // decimals SPL-токена < 256
+		QuoteDecimals:    uint8(u64(offQuoteDecimal)), //nolint:gosec // decimals SPL-токена < 256
+		FeeNum:           u64(offSwapFeeNumerator),
+		FeeDenom:         u64(offSwapFeeDenom),
+		BaseNeedTakePnl:  u64(offBaseNeedTakePnl),
+		QuoteNeedTakePnl: u64(offQuoteNeedTakePnl),
+		BaseVault:        key(offBaseVault),
+		QuoteVault:       key(offQuoteVault),
+		BaseMint:         key(offBaseMint),
+		QuoteMint:        key(offQuoteMint),
+	}
+	if s.FeeDenom == 0 || s.FeeNum >= s.FeeDenom {
+		return ammState{}, fmt.Errorf("amm state: invalid swap fee %d/%d", s.FeeNum, s.FeeDenom)
+	}
+	return s, nil
+}
+
+// tokenAmount возвращает остаток SPL-токен-аккаунта в минимальных единицах.
+func tokenAmount(data []byte) (uint64, error) {
+	if len(data) < tokenAccountSize {
+		return 0, fmt.Errorf("token account: got %d bytes, want %d", len(data), tokenAccountSize)
+	}
+	return binary.LittleEndian.Uint64(data[offTokenAccountAmount:]), nil
+}
+
+// reserves — резервы пула в целых токенах в ориентации пары (BASE, QUOTE) и комиссия свопа.
+type reserves struct {
+	Base, Quote float64
+	Fee         float64
+}
+
+var errInsufficientLiquidity = errors.New("insufficient pool liquidity")
+
+// sell возвращает выручку в QUOTE за продажу amount BASE (ExactIn).
+func (r reserves) sell(amount float64) float64 {
+	in := amount * (1 - r.Fee)
+	return r.Quote * in / (r.Base + in)
+}
+
+// buy возвращает стоимость в QUOTE покупки amount BASE (ExactOut).
+func (r reserves) buy(amount float64) (float64, error) {
+	if amount >= r.Base {
+		return 0, errInsufficientLiquidity
+	}
+	in := r.Quote * amount / (r.Base - amount)
+	return in / (1 - r.Fee), nil
+}
+
+func scale(raw uint64, decimals uint8) float64 {
+	return float64(raw) / math.Pow10(int(decimals))
+}
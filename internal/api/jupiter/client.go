@@ -43,6 +43,8 @@ type QuoteOptions struct {
 	AsLegacyTransaction        *bool `json:"asLegacyTransaction,omitempty"`
 	PlatformFeeBps             *int  `json:"platformFeeBps,omitempty"`
 	MaxAccounts                *int  `json:"maxAccounts,omitempty"`
+	// SwapMode — ExactIn (по умолчанию) или ExactOut; при ExactOut amount задаёт объём на выходе.
+	SwapMode *SwapMode `json:"swapMode,omitempty"`
 }
 
 // DefaultQuoteOptions возвращает опции по умолчанию.
@@ -149,6 +151,9 @@ func (c *Client) buildQuoteURL(inputMint, outputMint string, amount int64, opts
 	if opts.MaxAccounts != nil {
 		q.Add("maxAccounts", strconv.Itoa(*opts.MaxAccounts))
 	}
+	if opts.SwapMode != nil {
+		q.Add("swapMode", string(*opts.SwapMode))
+	}
 
 	requestURL.RawQuery = q.Encode()
 	return requestURL.String()
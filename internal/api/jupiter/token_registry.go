@@ -0,0 +1,334 @@
+package jupiter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+// tokenListURL эндпоинт для запроса токенов для Jupiter.
+const tokenListURL = "https://tokens.jup.ag/tokens?tags=verified,community,strict" // #nosec G101
+
+const (
+	defaultTokenFetchTimeout  = 5 * time.Second
+	defaultTokenRetryInterval = 30 * time.Second
+)
+
+type TokenEntry struct {
+	Symbol  string `json:"symbol"`
+	Address string `json:"address"`
+	// Decimals число знаков после запятой у котируемого токена (USDT = 6).
+	Decimals uint8 `json:"decimals"`
+}
+
+// TokenFetcher загружает список токенов.
+type TokenFetcher func(ctx context.Context) ([]TokenEntry, error)
+
+// TokenRegistryConfig — параметры реестра токенов.
+type TokenRegistryConfig struct {
+	// URL — адрес списка токенов в формате Jupiter. При пустом значении — tokenListURL.
+	URL string
+	// Fetch — источник списка. При nil список запрашивается по URL.
+	Fetch TokenFetcher
+	// CachePath — файл, в котором хранится последний загруженный список. Он используется,
+	// если источник недоступен. Пусто — без кеша.
+	CachePath string
+	// Overrides — токены, которых нет в списке или чьи записи нужно заменить.
+	// Доступны и без загрузки списка.
+	Overrides []TokenEntry
+	// RetryInterval — минимальная пауза между попытками загрузки после неудачи. При 0 — 30 сек.
+	RetryInterval time.Duration
+}
+
+// TokenRegistry — список токенов Solana (тикер, mint-адрес, decimals) из Jupiter.
+//
+// Список загружается через Load и обновляется через Refresh; каждый успешно загруженный
+// список сохраняется в CachePath, и при недоступном источнике реестр поднимается из него.
+// Пока список не загружен, поиск повторяет загрузку не чаще RetryInterval, так что
+// временный сбой источника не ломает реестр до перезапуска процесса.
+type TokenRegistry struct {
+	logger        i.Logger
+	fetch         TokenFetcher
+	cachePath     string
+	overrides     []TokenEntry
+	retryInterval time.Duration
+	nowFunc       func() time.Time
+
+	loadMu sync.Mutex // сериализует загрузки
+
+	mu          sync.RWMutex
+	bySymbol    map[string]TokenEntry // SYMBOL -> entry
+	byMint      map[string]TokenEntry // MINT ADDRESS -> entry
+	loaded      bool                  // список получен из источника или кеша
+	lastErr     error
+	lastAttempt time.Time
+}
+
+// NewTokenRegistry создаёт реестр. Сразу доступны только Overrides; список загружает Load
+// (или первый поиск токена, которого нет среди Overrides).
+func NewTokenRegistry(l i.Logger, cfg TokenRegistryConfig) *TokenRegistry {
+	if cfg.URL == "" {
+		cfg.URL = tokenListURL
+	}
+	if cfg.Fetch == nil {
+		cfg.Fetch = httpTokenFetcher(cfg.URL)
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = defaultTokenRetryInterval
+	}
+	r := &TokenRegistry{
+		logger:        l.Named("tokens"),
+		fetch:         cfg.Fetch,
+		cachePath:     cfg.CachePath,
+		overrides:     cfg.Overrides,
+		retryInterval: cfg.RetryInterval,
+		nowFunc:       time.Now,
+	}
+	r.set(nil)
+	return r
+}
+
+// Load загружает список из источника и сохраняет его в кеш. Если источник недоступен,
+// а список ещё не загружен, реестр поднимается из кеша. Ошибка возвращается, только
+// если список не удалось получить ни оттуда, ни оттуда; ранее загруженный список при
+// этом сохраняется.
+func (r *TokenRegistry) Load(ctx context.Context) error {
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+	return r.load(ctx)
+}
+
+func (r *TokenRegistry) load(ctx context.Context) error {
+	list, err := r.fetch(ctx)
+	if err == nil && len(list) == 0 {
+		err = errors.New("empty token list")
+	}
+	if err == nil {
+		r.set(list)
+		if err := r.writeCache(list); err != nil {
+			r.logger.Warn("failed to write token cache", "path", r.cachePath, "err", err)
+		}
+		return nil
+	}
+
+	r.mu.Lock()
+	loaded := r.loaded
+	r.lastErr, r.lastAttempt = err, r.nowFunc()
+	r.mu.Unlock()
+	if loaded {
+		return fmt.Errorf("fetch token list: %w", err)
+	}
+
+	cached, cacheErr := r.readCache()
+	if cacheErr != nil {
+		return errors.Join(fmt.Errorf("fetch token list: %w", err), cacheErr)
+	}
+	r.logger.Warn("token list unavailable, using cache", "path", r.cachePath, "err", err)
+	r.set(cached)
+	return nil
+}
+
+// Refresh перезагружает список каждые interval до отмены ctx. Ошибки логируются,
+// прежний список при этом сохраняется.
+func (r *TokenRegistry) Refresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Load(ctx); err != nil && ctx.Err() == nil {
+				r.logger.Warn("failed to refresh token list", "err", err)
+			}
+		}
+	}
+}
+
+// Token возвращает токен по тикеру (без учёта регистра).
+func (r *TokenRegistry) Token(ticker string) (TokenEntry, error) {
+	key := strings.ToUpper(strings.TrimSpace(ticker))
+	return r.lookup(func() (TokenEntry, bool) {
+		t, ok := r.bySymbol[key]
+		return t, ok
+	}, "token "+ticker)
+}
+
+// TokenByMint возвращает токен по mint-адресу.
+func (r *TokenRegistry) TokenByMint(mint string) (TokenEntry, error) {
+	key := strings.TrimSpace(mint) // регистр в base58 важен — не меняем
+	return r.lookup(func() (TokenEntry, bool) {
+		t, ok := r.byMint[key]
+		return t, ok
+	}, "mint "+mint)
+}
+
+// UnitAmount возвращает int64, равный 1*10^decimals для заданного тикера.
+func (r *TokenRegistry) UnitAmount(ticker string) (int64, error) {
+	t, err := r.Token(ticker)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Pow10(int(t.Decimals))), nil
+}
+
+// UnitAmountByMint возвращает int64, равный 1*10^decimals для заданного mint-адреса.
+func (r *TokenRegistry) UnitAmountByMint(mint string) (int64, error) {
+	t, err := r.TokenByMint(mint)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Pow10(int(t.Decimals))), nil
+}
+
+// Mints преобразует пару вида "SOL/USDT" в mint-адреса BASE и QUOTE по тикерам токенов.
+func (r *TokenRegistry) Mints(pair string) (baseMint, quoteMint string, err error) {
+	inst, err := market.ParsePair(pair)
+	if err != nil {
+		return "", "", err
+	}
+	base, err := r.Token(inst.Base)
+	if err != nil {
+		return "", "", err
+	}
+	quote, err := r.Token(inst.Quote)
+	if err != nil {
+		return "", "", err
+	}
+	return base.Address, quote.Address, nil
+}
+
+// lookup ищет токен; если его нет, а список ещё не загружен, повторяет загрузку
+// (не чаще retryInterval) и ищет снова.
+func (r *TokenRegistry) lookup(find func() (TokenEntry, bool), what string) (TokenEntry, error) {
+	t, loaded, ok := r.find(find)
+	if !ok && !loaded {
+		if err := r.retryLoad(); err != nil {
+			return TokenEntry{}, fmt.Errorf("%s: token list unavailable: %w", what, err)
+		}
+		t, _, ok = r.find(find)
+	}
+	if !ok {
+		return TokenEntry{}, fmt.Errorf("%s not found in token list", what)
+	}
+	return t, nil
+}
+
+func (r *TokenRegistry) find(find func() (TokenEntry, bool)) (t TokenEntry, loaded, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok = find()
+	return t, r.loaded, ok
+}
+
+// retryLoad загружает список, если он ещё не загружен и с последней неудачной попытки
+// прошло не меньше retryInterval; иначе возвращает ошибку последней попытки.
+func (r *TokenRegistry) retryLoad() error {
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
+	r.mu.RLock()
+	loaded, lastErr, lastAttempt := r.loaded, r.lastErr, r.lastAttempt
+	r.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+	if lastErr != nil && r.nowFunc().Sub(lastAttempt) < r.retryInterval {
+		return lastErr
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTokenFetchTimeout)
+	defer cancel()
+	return r.load(ctx)
+}
+
+// set заменяет список токенов; Overrides применяются поверх него.
+// Вызов с nil только инициализирует карты записями Overrides.
+func (r *TokenRegistry) set(list []TokenEntry) {
+	bySymbol := make(map[string]TokenEntry, len(list)+len(r.overrides))
+	byMint := make(map[string]TokenEntry, len(list)+len(r.overrides))
+	for _, t := range append(list[:len(list):len(list)], r.overrides...) {
+		bySymbol[strings.ToUpper(t.Symbol)] = t
+		byMint[strings.TrimSpace(t.Address)] = t // адрес оставляем в исходном регистре
+	}
+
+	r.mu.Lock()
+	r.bySymbol, r.byMint = bySymbol, byMint
+	if list != nil {
+		r.loaded, r.lastErr = true, nil
+	}
+	r.mu.Unlock()
+}
+
+// writeCache атомарно сохраняет список в кеш: через временный файл и переименование.
+func (r *TokenRegistry) writeCache(list []TokenEntry) error {
+	if r.cachePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.cachePath), 0o750); err != nil {
+		return err
+	}
+	tmp := r.cachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.cachePath)
+}
+
+func (r *TokenRegistry) readCache() ([]TokenEntry, error) {
+	if r.cachePath == "" {
+		return nil, errors.New("token cache is not configured")
+	}
+	data, err := os.ReadFile(r.cachePath)
+	if err != nil {
+		return nil, fmt.Errorf("read token cache: %w", err)
+	}
+	var list []TokenEntry
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("decode token cache %s: %w", r.cachePath, err)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("token cache %s is empty", r.cachePath)
+	}
+	return list, nil
+}
+
+// httpTokenFetcher запрашивает список токенов в формате Jupiter по адресу url.
+func httpTokenFetcher(url string) TokenFetcher {
+	client := &http.Client{Timeout: defaultTokenFetchTimeout}
+	return func(ctx context.Context) ([]TokenEntry, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("build request: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %d when fetching token list", resp.StatusCode)
+		}
+		var list []TokenEntry
+		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+			return nil, fmt.Errorf("decode token list: %w", err)
+		}
+		return list, nil
+	}
+}
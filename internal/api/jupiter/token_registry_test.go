@@ -0,0 +1,146 @@
+package jupiter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+var (
+	solToken  = TokenEntry{Symbol: "SOL", Address: "So11111111111111111111111111111111111111112", Decimals: 9}
+	usdtToken = TokenEntry{Symbol: "USDT", Address: "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB", Decimals: 6}
+)
+
+// scriptedFetcher отдаёт ошибку err, а при err == nil — список list; считает вызовы.
+type scriptedFetcher struct {
+	list  []TokenEntry
+	err   error
+	calls int
+}
+
+func (f *scriptedFetcher) fetch(context.Context) ([]TokenEntry, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.list, nil
+}
+
+func TestTokenRegistry_Lookups(t *testing.T) {
+	src := &scriptedFetcher{list: []TokenEntry{solToken, usdtToken}}
+	r := NewTokenRegistry(logger.New("error"), TokenRegistryConfig{Fetch: src.fetch})
+
+	unit, err := r.UnitAmount("sol")
+	if err != nil || unit != 1_000_000_000 {
+		t.Errorf("UnitAmount(sol) = %v, %v; want 1e9", unit, err)
+	}
+	unit, err = r.UnitAmountByMint(usdtToken.Address)
+	if err != nil || unit != 1_000_000 {
+		t.Errorf("UnitAmountByMint(USDT) = %v, %v; want 1e6", unit, err)
+	}
+	base, quote, err := r.Mints("SOL/USDT")
+	if err != nil || base != solToken.Address || quote != usdtToken.Address {
+		t.Errorf("Mints = %s, %s, %v", base, quote, err)
+	}
+	if _, err := r.Token("BONK"); err == nil {
+		t.Error("expected error for unknown token")
+	}
+	if src.calls != 1 {
+		t.Errorf("fetch calls = %d, want 1 (list is loaded lazily once)", src.calls)
+	}
+}
+
+func TestTokenRegistry_RetriesAfterFailure(t *testing.T) {
+	src := &scriptedFetcher{err: errors.New("unavailable")}
+	r := NewTokenRegistry(logger.New("error"), TokenRegistryConfig{Fetch: src.fetch, RetryInterval: time.Minute})
+	now := time.Unix(1_700_000_000, 0)
+	r.nowFunc = func() time.Time { return now }
+
+	if _, err := r.Token("SOL"); err == nil {
+		t.Fatal("expected error while source is down")
+	}
+	if _, err := r.Token("SOL"); err == nil || src.calls != 1 {
+		t.Fatalf("err = %v, calls = %d; want cached error without refetch", err, src.calls)
+	}
+
+	src.err, src.list = nil, []TokenEntry{solToken}
+	now = now.Add(time.Minute)
+	if tok, err := r.Token("SOL"); err != nil || tok != solToken {
+		t.Fatalf("Token after recovery = %+v, %v", tok, err)
+	}
+	if src.calls != 2 {
+		t.Errorf("fetch calls = %d, want 2", src.calls)
+	}
+}
+
+func TestTokenRegistry_FallsBackToCache(t *testing.T) {
+	cache := filepath.Join(t.TempDir(), "tokens", "jupiter.json")
+
+	online := NewTokenRegistry(logger.New("error"), TokenRegistryConfig{
+		Fetch:     (&scriptedFetcher{list: []TokenEntry{solToken, usdtToken}}).fetch,
+		CachePath: cache,
+	})
+	if err := online.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	src := &scriptedFetcher{err: errors.New("unavailable")}
+	offline := NewTokenRegistry(logger.New("error"), TokenRegistryConfig{Fetch: src.fetch, CachePath: cache})
+	if err := offline.Load(context.Background()); err != nil {
+		t.Fatalf("Load from cache: %v", err)
+	}
+	if tok, err := offline.TokenByMint(usdtToken.Address); err != nil || tok != usdtToken {
+		t.Errorf("TokenByMint = %+v, %v", tok, err)
+	}
+
+	// Без кеша и без источника загрузка не удаётся.
+	empty := NewTokenRegistry(logger.New("error"), TokenRegistryConfig{
+		Fetch:     src.fetch,
+		CachePath: filepath.Join(t.TempDir(), "missing.json"),
+	})
+	if err := empty.Load(context.Background()); err == nil {
+		t.Error("expected error without source and cache")
+	}
+}
+
+func TestTokenRegistry_Overrides(t *testing.T) {
+	custom := TokenEntry{Symbol: "WIF", Address: "EKpQGSJtjMFqKZ9KQanSqYXRcF8fBopzLHYxdM65zcjm", Decimals: 6}
+	src := &scriptedFetcher{err: errors.New("unavailable")}
+	r := NewTokenRegistry(logger.New("error"), TokenRegistryConfig{
+		Fetch:     src.fetch,
+		Overrides: []TokenEntry{custom, {Symbol: "SOL", Address: solToken.Address, Decimals: 8}},
+	})
+
+	if tok, err := r.Token("wif"); err != nil || tok != custom || src.calls != 0 {
+		t.Errorf("override = %+v, %v, calls = %d; want without fetching", tok, err, src.calls)
+	}
+
+	src.err, src.list = nil, []TokenEntry{solToken, usdtToken}
+	if err := r.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if tok, _ := r.Token("SOL"); tok.Decimals != 8 {
+		t.Errorf("SOL decimals = %d, want override 8", tok.Decimals)
+	}
+	if _, err := r.Token("USDT"); err != nil {
+		t.Errorf("USDT: %v", err)
+	}
+}
+
+func TestTokenRegistry_HTTPSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"symbol":"SOL","address":"So11111111111111111111111111111111111111112","decimals":9}]`))
+	}))
+	defer srv.Close()
+
+	r := NewTokenRegistry(logger.New("error"), TokenRegistryConfig{URL: srv.URL})
+	if tok, err := r.Token("SOL"); err != nil || tok != solToken {
+		t.Errorf("Token = %+v, %v", tok, err)
+	}
+}
@@ -0,0 +1,143 @@
+package trade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	withdrawPath        = "/api/v3/capital/withdraw"
+	withdrawHistoryPath = "/api/v3/capital/withdraw/history"
+	depositAddressPath  = "/api/v3/capital/deposit/address"
+	depositHistoryPath  = "/api/v3/capital/deposit/hisrec"
+)
+
+// DepositStatus — статус депозита MEXC.
+type DepositStatus int
+
+// Статусы депозита, после которых средства зачислены на счёт.
+const (
+	DepositSuccess   DepositStatus = 5
+	DepositCompleted DepositStatus = 12
+)
+
+// Credited сообщает, зачислен ли депозит.
+func (s DepositStatus) Credited() bool {
+	return s == DepositSuccess || s == DepositCompleted
+}
+
+// WithdrawStatus — статус вывода MEXC.
+type WithdrawStatus int
+
+const (
+	WithdrawSuccess WithdrawStatus = 7
+	WithdrawFailed  WithdrawStatus = 8
+	WithdrawCancel  WithdrawStatus = 9
+)
+
+// WithdrawRequest — параметры вывода средств.
+type WithdrawRequest struct {
+	Coin    string
+	Network string
+	Address string
+	Amount  float64
+	Memo    string
+	// WithdrawOrderID — клиентский идентификатор вывода.
+	WithdrawOrderID string
+}
+
+// Withdrawal — запись истории выводов.
+type Withdrawal struct {
+	ID             string         `json:"id"`
+	TxID           string         `json:"txId"`
+	Coin           string         `json:"coin"`
+	Network        string         `json:"network"`
+	Address        string         `json:"address"`
+	Amount         Decimal        `json:"amount"`
+	TransactionFee Decimal        `json:"transactionFee"`
+	Status         WithdrawStatus `json:"status"`
+	ApplyTime      int64          `json:"applyTime"`
+}
+
+// Deposit — запись истории депозитов.
+type Deposit struct {
+	TxID       string        `json:"txId"`
+	Coin       string        `json:"coin"`
+	Network    string        `json:"network"`
+	Address    string        `json:"address"`
+	Amount     Decimal       `json:"amount"`
+	Status     DepositStatus `json:"status"`
+	InsertTime int64         `json:"insertTime"`
+}
+
+// DepositAddress — адрес пополнения в сети.
+type DepositAddress struct {
+	Coin    string `json:"coin"`
+	Network string `json:"network"`
+	Address string `json:"address"`
+	Memo    string `json:"memo"`
+}
+
+// Withdraw создаёт заявку на вывод и возвращает её идентификатор.
+func (c *Client) Withdraw(ctx context.Context, req WithdrawRequest) (string, error) {
+	if req.Coin == "" || req.Address == "" || req.Amount <= 0 {
+		return "", errors.New("withdraw requires coin, address and positive amount")
+	}
+	params := url.Values{}
+	params.Set("coin", req.Coin)
+	params.Set("address", req.Address)
+	params.Set("amount", formatDecimal(req.Amount))
+	if req.Network != "" {
+		params.Set("netWork", req.Network)
+	}
+	if req.Memo != "" {
+		params.Set("memo", req.Memo)
+	}
+	if req.WithdrawOrderID != "" {
+		params.Set("withdrawOrderId", req.WithdrawOrderID)
+	}
+
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, withdrawPath, params, &resp); err != nil {
+		return "", fmt.Errorf("withdraw: %w", err)
+	}
+	return resp.ID, nil
+}
+
+// WithdrawHistory возвращает последние выводы монеты.
+func (c *Client) WithdrawHistory(ctx context.Context, coin string) ([]Withdrawal, error) {
+	var out []Withdrawal
+	if err := c.do(ctx, http.MethodGet, withdrawHistoryPath, url.Values{"coin": {coin}}, &out); err != nil {
+		return nil, fmt.Errorf("withdraw history: %w", err)
+	}
+	return out, nil
+}
+
+// DepositHistory возвращает последние депозиты монеты.
+func (c *Client) DepositHistory(ctx context.Context, coin string) ([]Deposit, error) {
+	var out []Deposit
+	if err := c.do(ctx, http.MethodGet, depositHistoryPath, url.Values{"coin": {coin}}, &out); err != nil {
+		return nil, fmt.Errorf("deposit history: %w", err)
+	}
+	return out, nil
+}
+
+// DepositAddress возвращает адрес пополнения монеты в сети network.
+func (c *Client) DepositAddress(ctx context.Context, coin, network string) (DepositAddress, error) {
+	var out []DepositAddress
+	params := url.Values{"coin": {coin}, "network": {network}}
+	if err := c.do(ctx, http.MethodGet, depositAddressPath, params, &out); err != nil {
+		return DepositAddress{}, fmt.Errorf("deposit address: %w", err)
+	}
+	for _, a := range out {
+		if a.Network == network || network == "" {
+			return a, nil
+		}
+	}
+	return DepositAddress{}, fmt.Errorf("no %s deposit address in network %q", coin, network)
+}
@@ -0,0 +1,266 @@
+package trade
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/api/mexc/utils"
+	i "github.com/dimryb/cross-arb/internal/interface"
+)
+
+const (
+	// DefaultBaseURL — адрес MEXC Spot API (без /api/v3).
+	DefaultBaseURL = "https://api.mexc.com"
+
+	defaultTimeout    = 5 * time.Second
+	defaultRecvWindow = 5 * time.Second
+
+	orderPath      = "/api/v3/order"
+	openOrdersPath = "/api/v3/openOrders"
+	testOrderPath  = "/api/v3/order/test"
+	accountPath    = "/api/v3/account"
+)
+
+// Config — параметры торгового клиента.
+type Config struct {
+	// BaseURL — адрес API без /api/v3. При пустом значении используется DefaultBaseURL.
+	BaseURL   string
+	APIKey    string
+	SecretKey string
+	// Timeout — таймаут HTTP-запроса. При 0 — 5 сек.
+	Timeout time.Duration
+	// RecvWindow — окно валидности подписанного запроса на стороне MEXC. При 0 — 5 сек.
+	RecvWindow time.Duration
+}
+
+// Client — типизированный клиент торговых эндпоинтов MEXC Spot:
+// размещение, отмена и статус ордеров. Запросы подписываются HMAC SHA256
+// и учитывают контекст. Ошибки MEXC возвращаются как *utils.APIError.
+type Client struct {
+	log     i.Logger
+	cfg     Config
+	http    *http.Client
+	nowFunc func() time.Time
+}
+
+// NewClient создаёт клиент.
+func NewClient(log i.Logger, cfg Config) (*Client, error) {
+	if cfg.APIKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("mexc api key and secret must be set")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.RecvWindow <= 0 {
+		cfg.RecvWindow = defaultRecvWindow
+	}
+	return &Client{
+		log:     log.Named("mexc_trade"),
+		cfg:     cfg,
+		http:    &http.Client{Timeout: cfg.Timeout},
+		nowFunc: time.Now,
+	}, nil
+}
+
+// PlaceOrder размещает ордер. Ответ содержит OrderID и параметры размещения;
+// фактическое исполнение нужно запрашивать через QueryOrder.
+func (c *Client) PlaceOrder(ctx context.Context, req OrderRequest) (Order, error) {
+	params, err := req.values()
+	if err != nil {
+		return Order{}, err
+	}
+	var o Order
+	if err := c.do(ctx, http.MethodPost, orderPath, params, &o); err != nil {
+		return Order{}, fmt.Errorf("place order: %w", err)
+	}
+	return o, nil
+}
+
+// TestOrder проверяет параметры ордера и подпись без размещения.
+func (c *Client) TestOrder(ctx context.Context, req OrderRequest) error {
+	params, err := req.values()
+	if err != nil {
+		return err
+	}
+	if err := c.do(ctx, http.MethodPost, testOrderPath, params, nil); err != nil {
+		return fmt.Errorf("test order: %w", err)
+	}
+	return nil
+}
+
+// CancelOrder отменяет ордер и возвращает его итоговое состояние.
+func (c *Client) CancelOrder(ctx context.Context, req CancelRequest) (Order, error) {
+	params, err := orderRef(req.Symbol, req.OrderID, req.OrigClientOrderID)
+	if err != nil {
+		return Order{}, err
+	}
+	var o Order
+	if err := c.do(ctx, http.MethodDelete, orderPath, params, &o); err != nil {
+		return Order{}, fmt.Errorf("cancel order: %w", err)
+	}
+	return o, nil
+}
+
+// QueryOrder возвращает текущее состояние ордера.
+func (c *Client) QueryOrder(ctx context.Context, req QueryRequest) (Order, error) {
+	params, err := orderRef(req.Symbol, req.OrderID, req.OrigClientOrderID)
+	if err != nil {
+		return Order{}, err
+	}
+	var o Order
+	if err := c.do(ctx, http.MethodGet, orderPath, params, &o); err != nil {
+		return Order{}, fmt.Errorf("query order: %w", err)
+	}
+	return o, nil
+}
+
+// OpenOrders возвращает открытые ордера символа.
+func (c *Client) OpenOrders(ctx context.Context, symbol string) ([]Order, error) {
+	if symbol == "" {
+		return nil, errors.New("symbol is required")
+	}
+	var orders []Order
+	if err := c.do(ctx, http.MethodGet, openOrdersPath, url.Values{"symbol": {symbol}}, &orders); err != nil {
+		return nil, fmt.Errorf("open orders: %w", err)
+	}
+	return orders, nil
+}
+
+// Account возвращает сведения о спотовом счёте с остатками активов.
+func (c *Client) Account(ctx context.Context) (Account, error) {
+	var acc Account
+	if err := c.do(ctx, http.MethodGet, accountPath, nil, &acc); err != nil {
+		return Account{}, fmt.Errorf("account: %w", err)
+	}
+	return acc, nil
+}
+
+// do выполняет подписанный запрос. Параметры передаются в query string (как требует MEXC
+// для всех методов), подпись считается от закодированной строки параметров.
+func (c *Client) do(ctx context.Context, method, path string, params url.Values, out any) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("recvWindow", strconv.FormatInt(c.cfg.RecvWindow.Milliseconds(), 10))
+	params.Set("timestamp", strconv.FormatInt(c.nowFunc().UnixMilli(), 10))
+	query := params.Encode()
+	query += "&signature=" + utils.ComputeHmac256(query, c.cfg.SecretKey)
+
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.BaseURL+path+"?"+query, nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("X-MEXC-APIKEY", c.cfg.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	c.log.Debug("mexc trade request", "method", method, "path", path)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return utils.ParseAPIError(resp.StatusCode, body)
+	}
+	// MEXC иногда отвечает 200 с кодом ошибки в теле.
+	if apiErr := embeddedError(resp.StatusCode, body); apiErr != nil {
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// embeddedError извлекает ошибку из тела успешного по HTTP ответа-объекта.
+func embeddedError(status int, body []byte) error {
+	var raw struct {
+		Code *int   `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil || raw.Code == nil {
+		return nil
+	}
+	if *raw.Code == 0 || *raw.Code == http.StatusOK {
+		return nil
+	}
+	return &utils.APIError{StatusCode: status, Code: *raw.Code, Msg: raw.Msg}
+}
+
+func (r OrderRequest) values() (url.Values, error) {
+	if r.Symbol == "" {
+		return nil, errors.New("symbol is required")
+	}
+	if r.Side != SideBuy && r.Side != SideSell {
+		return nil, fmt.Errorf("invalid side %q", r.Side)
+	}
+
+	v := url.Values{}
+	v.Set("symbol", r.Symbol)
+	v.Set("side", string(r.Side))
+	v.Set("type", string(r.Type))
+
+	switch r.Type {
+	case TypeMarket:
+		switch {
+		case r.Quantity > 0:
+			v.Set("quantity", formatDecimal(r.Quantity))
+		case r.QuoteOrderQty > 0:
+			v.Set("quoteOrderQty", formatDecimal(r.QuoteOrderQty))
+		default:
+			return nil, errors.New("market order requires quantity or quoteOrderQty")
+		}
+	case TypeLimit, TypeLimitMaker, TypeImmediateOrCancel, TypeFillOrKill:
+		if r.Quantity <= 0 || r.Price <= 0 {
+			return nil, fmt.Errorf("%s order requires positive quantity and price", r.Type)
+		}
+		v.Set("quantity", formatDecimal(r.Quantity))
+		v.Set("price", formatDecimal(r.Price))
+	default:
+		return nil, fmt.Errorf("invalid order type %q", r.Type)
+	}
+
+	if r.NewClientOrderID != "" {
+		v.Set("newClientOrderId", r.NewClientOrderID)
+	}
+	return v, nil
+}
+
+func orderRef(symbol, orderID, clientOrderID string) (url.Values, error) {
+	if symbol == "" {
+		return nil, errors.New("symbol is required")
+	}
+	v := url.Values{"symbol": {symbol}}
+	switch {
+	case orderID != "":
+		v.Set("orderId", orderID)
+	case clientOrderID != "":
+		v.Set("origClientOrderId", clientOrderID)
+	default:
+		return nil, errors.New("orderId or origClientOrderId is required")
+	}
+	return v, nil
+}
+
+func formatDecimal(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,182 @@
+package trade
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/api/mexc/utils"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+func newTestClient(t *testing.T, h http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(logger.New("error"), Config{BaseURL: srv.URL, APIKey: "key", SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	c.nowFunc = func() time.Time { return time.UnixMilli(1700000000000) }
+	return c
+}
+
+func TestClient_PlaceOrder_SignsRequest(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != orderPath {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("X-MEXC-APIKEY") != "key" {
+			t.Error("missing api key header")
+		}
+
+		raw := r.URL.RawQuery
+		idx := strings.LastIndex(raw, "&signature=")
+		if idx < 0 {
+			t.Fatalf("no signature in %q", raw)
+		}
+		if want := utils.ComputeHmac256(raw[:idx], "secret"); raw[idx+len("&signature="):] != want {
+			t.Errorf("bad signature for %q", raw[:idx])
+		}
+
+		q := r.URL.Query()
+		for k, want := range map[string]string{
+			"symbol": "SOLUSDT", "side": "BUY", "type": "LIMIT", "quantity": "1.25", "price": "100.5",
+			"newClientOrderId": "arb-1", "timestamp": "1700000000000", "recvWindow": "5000",
+		} {
+			if got := q.Get(k); got != want {
+				t.Errorf("%s = %q, want %q", k, got, want)
+			}
+		}
+		_, _ = w.Write([]byte(`{"symbol":"SOLUSDT","orderId":"C02__1","price":"100.5","origQty":"1.25",
+			"type":"LIMIT","side":"BUY","transactTime":1700000000001}`))
+	})
+
+	o, err := c.PlaceOrder(context.Background(), OrderRequest{
+		Symbol: "SOLUSDT", Side: SideBuy, Type: TypeLimit, Quantity: 1.25, Price: 100.5, NewClientOrderID: "arb-1",
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if o.OrderID != "C02__1" || o.OrigQty != 1.25 || o.Price != 100.5 || o.Side != SideBuy {
+		t.Errorf("order = %+v", o)
+	}
+}
+
+func TestClient_QueryOrder(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Query().Get("origClientOrderId") != "arb-1" {
+			t.Errorf("request = %s %s", r.Method, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"symbol":"SOLUSDT","orderId":"C02__1","status":"FILLED",
+			"executedQty":"2","cummulativeQuoteQty":"201","price":""}`))
+	})
+
+	o, err := c.QueryOrder(context.Background(), QueryRequest{Symbol: "SOLUSDT", OrigClientOrderID: "arb-1"})
+	if err != nil {
+		t.Fatalf("QueryOrder: %v", err)
+	}
+	if !o.Status.Final() || o.ExecutedQty != 2 || o.AvgPrice() != 100.5 {
+		t.Errorf("order = %+v", o)
+	}
+}
+
+func TestClient_CancelOrder_MapsErrors(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("method = %s, want DELETE", r.Method)
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":-2013,"msg":"Order does not exist."}`))
+	})
+
+	_, err := c.CancelOrder(context.Background(), CancelRequest{Symbol: "SOLUSDT", OrderID: "1"})
+	if !errors.Is(err, utils.ErrOrderNotFound) {
+		t.Fatalf("err = %v, want ErrOrderNotFound", err)
+	}
+}
+
+func TestClient_EmbeddedErrorAndContext(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"code":10101,"msg":"Insufficient balance"}`))
+	})
+	err := c.TestOrder(context.Background(), OrderRequest{Symbol: "SOLUSDT", Side: SideSell, Type: TypeMarket, Quantity: 1})
+	if !errors.Is(err, utils.ErrInsufficientBalance) {
+		t.Errorf("err = %v, want ErrInsufficientBalance", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.OpenOrders(ctx, "SOLUSDT"); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestClient_Account(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != accountPath {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"canTrade":true,"balances":[
+			{"asset":"USDT","free":"120.5","locked":"4.5"},{"asset":"SOL","free":"2","locked":"0"}]}`))
+	})
+
+	acc, err := c.Account(context.Background())
+	if err != nil {
+		t.Fatalf("Account: %v", err)
+	}
+	if !acc.CanTrade || len(acc.Balances) != 2 || acc.Balances[0].Free != 120.5 || acc.Balances[0].Locked != 4.5 {
+		t.Errorf("account = %+v", acc)
+	}
+}
+
+func TestClient_WithdrawAndHistory(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case withdrawPath:
+			if q.Get("coin") != "SOL" || q.Get("netWork") != "SOL" || q.Get("amount") != "1.5" || q.Get("address") != "addr" {
+				t.Errorf("withdraw params = %v", q)
+			}
+			_, _ = w.Write([]byte(`{"id":"w1"}`))
+		case withdrawHistoryPath:
+			_, _ = w.Write([]byte(`[{"id":"w1","txId":"tx","coin":"SOL","amount":"1.5","status":7}]`))
+		case depositAddressPath:
+			_, _ = w.Write([]byte(`[{"coin":"USDT","network":"ETH","address":"0x"},{"coin":"USDT","network":"SOL","address":"dep"}]`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	id, err := c.Withdraw(context.Background(), WithdrawRequest{Coin: "SOL", Network: "SOL", Address: "addr", Amount: 1.5})
+	if err != nil || id != "w1" {
+		t.Fatalf("Withdraw = %q, %v", id, err)
+	}
+	hist, err := c.WithdrawHistory(context.Background(), "SOL")
+	if err != nil || len(hist) != 1 || hist[0].Status != WithdrawSuccess || hist[0].Amount != 1.5 {
+		t.Errorf("history = %+v, err = %v", hist, err)
+	}
+	addr, err := c.DepositAddress(context.Background(), "USDT", "SOL")
+	if err != nil || addr.Address != "dep" {
+		t.Errorf("deposit address = %+v, err = %v", addr, err)
+	}
+}
+
+func TestOrderRequest_Validation(t *testing.T) {
+	for name, req := range map[string]OrderRequest{
+		"no symbol":     {Side: SideBuy, Type: TypeMarket, Quantity: 1},
+		"bad side":      {Symbol: "SOLUSDT", Side: "HOLD", Type: TypeMarket, Quantity: 1},
+		"market empty":  {Symbol: "SOLUSDT", Side: SideBuy, Type: TypeMarket},
+		"limit noprice": {Symbol: "SOLUSDT", Side: SideBuy, Type: TypeLimit, Quantity: 1},
+		"bad type":      {Symbol: "SOLUSDT", Side: SideBuy, Type: "STOP", Quantity: 1},
+	} {
+		if _, err := req.values(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package trade
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// Side — направление ордера.
+type Side string
+
+const (
+	SideBuy  Side = "BUY"
+	SideSell Side = "SELL"
+)
+
+// OrderType — тип ордера MEXC Spot.
+type OrderType string
+
+const (
+	TypeLimit             OrderType = "LIMIT"
+	TypeMarket            OrderType = "MARKET"
+	TypeLimitMaker        OrderType = "LIMIT_MAKER"
+	TypeImmediateOrCancel OrderType = "IMMEDIATE_OR_CANCEL"
+	TypeFillOrKill        OrderType = "FILL_OR_KILL"
+)
+
+// OrderStatus — статус ордера MEXC Spot.
+type OrderStatus string
+
+const (
+	StatusNew               OrderStatus = "NEW"
+	StatusFilled            OrderStatus = "FILLED"
+	StatusPartiallyFilled   OrderStatus = "PARTIALLY_FILLED"
+	StatusCanceled          OrderStatus = "CANCELED"
+	StatusPartiallyCanceled OrderStatus = "PARTIALLY_CANCELED"
+)
+
+// Final сообщает, что ордер больше не будет исполняться.
+func (s OrderStatus) Final() bool {
+	switch s {
+	case StatusFilled, StatusCanceled, StatusPartiallyCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// OrderRequest — параметры нового ордера (POST /api/v3/order).
+//
+// Для MARKET задаётся Quantity (объём BASE) либо QuoteOrderQty (сумма QUOTE);
+// для лимитных типов обязательны Quantity и Price.
+type OrderRequest struct {
+	Symbol           string
+	Side             Side
+	Type             OrderType
+	Quantity         float64
+	QuoteOrderQty    float64
+	Price            float64
+	NewClientOrderID string
+}
+
+// CancelRequest — отмена ордера (DELETE /api/v3/order) по OrderID или OrigClientOrderID.
+type CancelRequest struct {
+	Symbol            string
+	OrderID           string
+	OrigClientOrderID string
+}
+
+// QueryRequest — запрос статуса ордера (GET /api/v3/order) по OrderID или OrigClientOrderID.
+type QueryRequest struct {
+	Symbol            string
+	OrderID           string
+	OrigClientOrderID string
+}
+
+// Order — ордер в ответах MEXC. Ответ на размещение содержит только часть полей.
+type Order struct {
+	Symbol              string      `json:"symbol"`
+	OrderID             string      `json:"orderId"`
+	ClientOrderID       string      `json:"clientOrderId"`
+	Price               Decimal     `json:"price"`
+	OrigQty             Decimal     `json:"origQty"`
+	ExecutedQty         Decimal     `json:"executedQty"`
+	CummulativeQuoteQty Decimal     `json:"cummulativeQuoteQty"`
+	Status              OrderStatus `json:"status"`
+	Type                OrderType   `json:"type"`
+	Side                Side        `json:"side"`
+	Time                int64       `json:"time"`
+	UpdateTime          int64       `json:"updateTime"`
+	TransactTime        int64       `json:"transactTime"`
+}
+
+// AvgPrice возвращает среднюю цену исполнения; 0, если ордер не исполнялся.
+func (o Order) AvgPrice() float64 {
+	if o.ExecutedQty <= 0 {
+		return 0
+	}
+	return float64(o.CummulativeQuoteQty) / float64(o.ExecutedQty)
+}
+
+// Balance — остаток актива на спотовом счёте.
+type Balance struct {
+	Asset  string  `json:"asset"`
+	Free   Decimal `json:"free"`
+	Locked Decimal `json:"locked"`
+}
+
+// Account — сведения о спотовом счёте (GET /api/v3/account).
+type Account struct {
+	CanTrade    bool      `json:"canTrade"`
+	CanWithdraw bool      `json:"canWithdraw"`
+	CanDeposit  bool      `json:"canDeposit"`
+	UpdateTime  int64     `json:"updateTime"`
+	AccountType string    `json:"accountType"`
+	Balances    []Balance `json:"balances"`
+}
+
+// Decimal — число, которое MEXC передаёт строкой ("1.5"), числом или пустой строкой.
+type Decimal float64
+
+// UnmarshalJSON удовлетворяет json.Unmarshaler.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	b = bytes.Trim(b, `"`)
+	if len(b) == 0 || string(b) == "null" {
+		*d = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return fmt.Errorf("decimal %q: %w", b, err)
+	}
+	*d = Decimal(v)
+	return nil
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// APIError — ошибка MEXC API в формате {"code": ..., "msg": ...}.
+// Сравнение через errors.Is выполняется по коду ошибки MEXC, а при его отсутствии — по HTTP-статусу.
+type APIError struct {
+	StatusCode int    // HTTP-статус ответа
+	Code       int    // код ошибки MEXC
+	Msg        string // сообщение MEXC
+}
+
+func (e *APIError) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("mexc api error: status %d: %s", e.StatusCode, e.Msg)
+	}
+	return fmt.Sprintf("mexc api error: status %d, code %d: %s", e.StatusCode, e.Code, e.Msg)
+}
+
+// Is позволяет сравнивать ошибку с сентинелами пакета через errors.Is.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	if t.Code != 0 {
+		return t.Code == e.Code
+	}
+	return t.StatusCode != 0 && t.StatusCode == e.StatusCode
+}
+
+// Известные коды ошибок MEXC Spot API.
+var (
+	ErrInvalidSymbol       = &APIError{Code: -1121, Msg: "invalid symbol"}
+	ErrBadSymbol           = &APIError{Code: 10007, Msg: "bad symbol"}
+	ErrInvalidAPIKey       = &APIError{Code: 10072, Msg: "invalid access key"}
+	ErrSignature           = &APIError{Code: 602, Msg: "signature verification failed"}
+	ErrTimestamp           = &APIError{Code: 700003, Msg: "timestamp outside of recvWindow"}
+	ErrInsufficientBalance = &APIError{Code: 10101, Msg: "insufficient balance"}
+	ErrMinTradeAmount      = &APIError{Code: 30002, Msg: "order amount below minimum"}
+	ErrInsufficientPos     = &APIError{Code: 30004, Msg: "insufficient position"}
+	ErrOversold            = &APIError{Code: 30005, Msg: "oversold"}
+	ErrTradingDisabled     = &APIError{Code: 30016, Msg: "trading disabled"}
+	ErrOrderNotFound       = &APIError{Code: -2013, Msg: "order does not exist"}
+	ErrRateLimited         = &APIError{StatusCode: http.StatusTooManyRequests, Msg: "rate limited"}
+)
+
+// ParseAPIError строит *APIError из HTTP-статуса и тела ответа MEXC.
+// Если тело не содержит структурированной ошибки, в Msg попадает сырое тело.
+func ParseAPIError(statusCode int, body []byte) error {
+	var raw struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil || (raw.Code == 0 && raw.Msg == "") {
+		return &APIError{StatusCode: statusCode, Msg: string(body)}
+	}
+	return &APIError{StatusCode: statusCode, Code: raw.Code, Msg: raw.Msg}
+}
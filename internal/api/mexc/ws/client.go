@@ -0,0 +1,358 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/orderbook"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// DefaultURL — адрес публичного спотового WebSocket MEXC.
+	DefaultURL = "wss://wbs.mexc.com/ws"
+
+	defaultPingInterval = 20 * time.Second
+	defaultReconnectMin = time.Second
+	defaultReconnectMax = 30 * time.Second
+	writeTimeout        = 5 * time.Second
+
+	exchangeName = "mexc"
+)
+
+// Config — параметры WebSocket-клиента.
+type Config struct {
+	// URL WebSocket-эндпоинта. При пустом значении используется DefaultURL.
+	URL string
+	// Symbols — символы MEXC ("SOLUSDT"), на которые оформляется подписка.
+	Symbols []string
+	// PingInterval — период отправки PING. Соединение считается мёртвым,
+	// если за 2*PingInterval не пришло ни одного сообщения. При 0 — 20 сек.
+	PingInterval time.Duration
+	// ReconnectMin/ReconnectMax — границы экспоненциальной задержки переподключения.
+	ReconnectMin time.Duration
+	ReconnectMax time.Duration
+	// OnBookTicker вызывается на каждое обновление лучших цен. Необязателен.
+	OnBookTicker func(entity.TickerData)
+	// Snapshot загружает REST-снапшот стакана, на который накладываются инкрементальные
+	// обновления. Если не задан, подписка на глубину не оформляется и OrderBook всегда пуст.
+	Snapshot orderbook.SnapshotFunc
+	// OnOrderBookUpdate вызывается после каждого применённого к стакану обновления. Необязателен.
+	OnOrderBookUpdate func(symbol string)
+}
+
+// Client поддерживает подписку на каналы book ticker и инкрементальной глубины MEXC,
+// переподключается с экспоненциальной задержкой и повторно подписывается.
+//
+// По каждому символу поддерживается локальный стакан: REST-снапшот плюс инкрементальные
+// обновления с проверкой версий. При пропуске версии или разрыве соединения стакан
+// сбрасывается и синхронизируется заново.
+type Client struct {
+	log    i.Logger
+	cfg    Config
+	dialer *websocket.Dialer
+	books  *orderbook.Syncer // nil, если Snapshot не задан
+
+	mu      sync.RWMutex
+	tickers map[string]entity.TickerData
+}
+
+// NewClient создаёт клиент. Подключение выполняется в Run.
+func NewClient(log i.Logger, cfg Config) (*Client, error) {
+	if len(cfg.Symbols) == 0 {
+		return nil, errors.New("no symbols provided")
+	}
+	if cfg.URL == "" {
+		cfg.URL = DefaultURL
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = defaultPingInterval
+	}
+	if cfg.ReconnectMin <= 0 {
+		cfg.ReconnectMin = defaultReconnectMin
+	}
+	if cfg.ReconnectMax < cfg.ReconnectMin {
+		cfg.ReconnectMax = max(defaultReconnectMax, cfg.ReconnectMin)
+	}
+
+	symbols := make([]string, 0, len(cfg.Symbols))
+	for _, s := range cfg.Symbols {
+		symbols = append(symbols, strings.ToUpper(s))
+	}
+	cfg.Symbols = symbols
+
+	c := &Client{
+		log:     log.Named("mexc_ws"),
+		cfg:     cfg,
+		dialer:  &websocket.Dialer{HandshakeTimeout: 10 * time.Second},
+		tickers: make(map[string]entity.TickerData),
+	}
+	if cfg.Snapshot != nil {
+		books, err := orderbook.NewSyncer(c.log, orderbook.SyncerConfig{
+			Symbols:  symbols,
+			Snapshot: cfg.Snapshot,
+			OnUpdate: cfg.OnOrderBookUpdate,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("order book syncer: %w", err)
+		}
+		c.books = books
+	}
+	return c, nil
+}
+
+// Run держит соединение до отмены ctx, переподключаясь при ошибках.
+// Возвращает ctx.Err() после отмены.
+func (c *Client) Run(ctx context.Context) error {
+	if c.books != nil {
+		go func() { _ = c.books.Run(ctx) }()
+	}
+
+	backoff := c.cfg.ReconnectMin
+	for {
+		started := time.Now()
+		err := c.session(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// Обновления за время разрыва потеряны — стаканы нужно собрать заново.
+		if c.books != nil {
+			c.books.InvalidateAll()
+		}
+
+		// Долгоживущая сессия сбрасывает задержку.
+		if time.Since(started) > c.cfg.ReconnectMax {
+			backoff = c.cfg.ReconnectMin
+		}
+		c.log.Warn("mexc ws disconnected, reconnecting", "err", err, "backoff", backoff)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, c.cfg.ReconnectMax)
+	}
+}
+
+// OrderBook возвращает локальный стакан символа (не более limit уровней на сторону).
+// ok == false, если стакан ещё не синхронизирован или был сброшен после разрыва.
+func (c *Client) OrderBook(symbol string, limit int) (entity.OrderBook, bool) {
+	if c.books == nil {
+		return entity.OrderBook{}, false
+	}
+	return c.books.OrderBook(symbol, limit)
+}
+
+// BookTicker возвращает последние лучшие цены символа.
+func (c *Client) BookTicker(symbol string) (entity.TickerData, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t, ok := c.tickers[strings.ToUpper(symbol)]
+	return t, ok
+}
+
+// session обслуживает одно соединение: подписка, keepalive, чтение.
+func (c *Client) session(ctx context.Context) error {
+	conn, resp, err := c.dialer.DialContext(ctx, c.cfg.URL, nil)
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+
+	sessCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Закрываем соединение при отмене, чтобы разблокировать ReadMessage.
+	go func() {
+		<-sessCtx.Done()
+		_ = conn.Close()
+	}()
+
+	w := &writer{conn: conn}
+
+	params := make([]string, 0, 2*len(c.cfg.Symbols))
+	for _, s := range c.cfg.Symbols {
+		params = append(params, bookTickerTopic(s))
+		if c.books != nil {
+			params = append(params, depthTopic(s))
+		}
+	}
+	if err := w.writeJSON(request{Method: "SUBSCRIPTION", Params: params}); err != nil {
+		return fmt.Errorf("subscribe: %w", err)
+	}
+	c.log.Info("mexc ws subscribed", "url", c.cfg.URL, "symbols", c.cfg.Symbols)
+
+	go c.keepalive(sessCtx, w)
+
+	readTimeout := 2 * c.cfg.PingInterval
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(readTimeout)); err != nil {
+			return err
+		}
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return fmt.Errorf("read: %w", err)
+		}
+		if err := c.handle(data); err != nil {
+			return err
+		}
+	}
+}
+
+// keepalive периодически отправляет PING.
+func (c *Client) keepalive(ctx context.Context, w *writer) {
+	ticker := time.NewTicker(c.cfg.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.writeJSON(request{Method: "PING"}); err != nil {
+				c.log.Debug("mexc ws ping failed", "err", err)
+				return
+			}
+		}
+	}
+}
+
+// handle разбирает входящее сообщение. Ошибка означает необходимость переподключения.
+func (c *Client) handle(data []byte) error {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		c.log.Debug("mexc ws: skip malformed message", "err", err)
+		return nil
+	}
+
+	if env.Channel == "" {
+		// Ответ на запрос: PONG или подтверждение подписки.
+		if env.Code != 0 {
+			return fmt.Errorf("mexc ws request failed: code %d: %s", env.Code, env.Msg)
+		}
+		return nil
+	}
+
+	symbol := env.Symbol
+	if symbol == "" {
+		symbol = symbolFromChannel(env.Channel)
+	}
+
+	switch {
+	case strings.HasPrefix(env.Channel, bookTickerChannel):
+		return c.handleBookTicker(symbol, env.Data)
+	case strings.HasPrefix(env.Channel, depthChannel):
+		return c.handleDepth(symbol, env.Data)
+	default:
+		return nil
+	}
+}
+
+func (c *Client) handleBookTicker(symbol string, raw json.RawMessage) error {
+	var d bookTickerData
+	if err := json.Unmarshal(raw, &d); err != nil {
+		return fmt.Errorf("decode book ticker: %w", err)
+	}
+
+	var t entity.TickerData
+	var err error
+	t.Symbol, t.Exchange = symbol, exchangeName
+	if t.BidPrice, err = parseFloat(d.BidPrice); err != nil {
+		return err
+	}
+	if t.BidQty, err = parseFloat(d.BidQty); err != nil {
+		return err
+	}
+	if t.AskPrice, err = parseFloat(d.AskPrice); err != nil {
+		return err
+	}
+	if t.AskQty, err = parseFloat(d.AskQty); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.tickers[symbol] = t
+	c.mu.Unlock()
+
+	if c.cfg.OnBookTicker != nil {
+		c.cfg.OnBookTicker(t)
+	}
+	return nil
+}
+
+func (c *Client) handleDepth(symbol string, raw json.RawMessage) error {
+	var d depthData
+	if err := json.Unmarshal(raw, &d); err != nil {
+		return fmt.Errorf("decode depth: %w", err)
+	}
+
+	bids, err := toOrders(d.Bids)
+	if err != nil {
+		return fmt.Errorf("depth bids: %w", err)
+	}
+	asks, err := toOrders(d.Asks)
+	if err != nil {
+		return fmt.Errorf("depth asks: %w", err)
+	}
+	version, err := strconv.ParseInt(d.Version, 10, 64)
+	if err != nil {
+		return fmt.Errorf("depth version %q: %w", d.Version, err)
+	}
+	if c.books == nil {
+		return nil
+	}
+
+	// Каждое сообщение MEXC несёт одну версию, следующую за предыдущей.
+	c.books.Apply(symbol, orderbook.Diff{
+		FirstUpdateID: version,
+		FinalUpdateID: version,
+		Bids:          bids,
+		Asks:          asks,
+	})
+	return nil
+}
+
+func toOrders(levels []depthLevel) ([]entity.Order, error) {
+	orders := make([]entity.Order, 0, len(levels))
+	for _, l := range levels {
+		price, err := parseFloat(l.Price)
+		if err != nil {
+			return nil, err
+		}
+		qty, err := parseFloat(l.Volume)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, entity.Order{Price: price, Quantity: qty})
+	}
+	return orders, nil
+}
+
+// writer сериализует запись в соединение: gorilla/websocket допускает одного писателя.
+type writer struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (w *writer) writeJSON(v any) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	return w.conn.WriteJSON(v)
+}
@@ -0,0 +1,216 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	"github.com/dimryb/cross-arb/internal/logger"
+	"github.com/dimryb/cross-arb/internal/orderbook"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	tickerMsg = `{"c":"spot@public.bookTicker.v3.api@SOLUSDT","s":"SOLUSDT","t":1,
+		"d":{"b":"99.5","B":"3","a":"100.5","A":"4"}}`
+	depthMsg1 = `{"c":"spot@public.increase.depth.v3.api@SOLUSDT","s":"SOLUSDT","t":1,
+		"d":{"bids":[{"p":"99.5","v":"3"},{"p":"99","v":"1"}],"asks":[{"p":"100.5","v":"4"}],"r":"10"}}`
+	depthMsg2 = `{"c":"spot@public.increase.depth.v3.api@SOLUSDT","s":"SOLUSDT","t":2,
+		"d":{"bids":[{"p":"99.5","v":"0"}],"asks":[{"p":"101","v":"2"}],"r":"11"}}`
+)
+
+// wsServer — тестовый WebSocket-сервер MEXC. handler получает функцию отправки
+// и номер подключения (с 1) после успешной подписки.
+type wsServer struct {
+	t           *testing.T
+	connections atomic.Int32
+	subscribed  atomic.Int32
+	pings       atomic.Int32
+	handler     func(send func(string), n int32)
+}
+
+func (s *wsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	up := websocket.Upgrader{}
+	conn, err := up.Upgrade(w, r, nil)
+	if err != nil {
+		s.t.Errorf("upgrade: %v", err)
+		return
+	}
+	defer conn.Close()
+	n := s.connections.Add(1)
+
+	var req request
+	if err := conn.ReadJSON(&req); err != nil {
+		return
+	}
+	if req.Method != "SUBSCRIPTION" || len(req.Params) != 2 {
+		s.t.Errorf("unexpected subscription request: %+v", req)
+		return
+	}
+	s.subscribed.Add(1)
+
+	var mu sync.Mutex
+	send := func(m string) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(m))
+	}
+	send(`{"id":0,"code":0,"msg":"` + req.Params[0] + `"}`)
+
+	// Отвечаем на PING в фоне, пока соединение живо.
+	go func() {
+		for {
+			var r request
+			if err := conn.ReadJSON(&r); err != nil {
+				return
+			}
+			if r.Method == "PING" {
+				s.pings.Add(1)
+				send(`{"id":0,"code":0,"msg":"PONG"}`)
+			}
+		}
+	}()
+
+	s.handler(send, n)
+}
+
+func startServer(t *testing.T, handler func(send func(string), n int32)) (*wsServer, string) {
+	t.Helper()
+	s := &wsServer{t: t, handler: handler}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+// snapshot — REST-снапшот, предшествующий depthMsg1 (версия 10).
+func snapshot(_ context.Context, _ string) (orderbook.Snapshot, error) {
+	return orderbook.Snapshot{
+		LastUpdateID: 9,
+		Bids:         []entity.Order{{Price: 98, Quantity: 5}},
+		Asks:         []entity.Order{{Price: 102, Quantity: 1}},
+	}, nil
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timeout waiting for %s", what)
+}
+
+func TestClient_BookTickerAndDepth(t *testing.T) {
+	srv, url := startServer(t, func(send func(string), _ int32) {
+		for _, m := range []string{tickerMsg, depthMsg1, depthMsg2} {
+			send(m)
+		}
+		time.Sleep(time.Second)
+	})
+
+	tickers := make(chan entity.TickerData, 1)
+	c, err := NewClient(logger.New("error"), Config{
+		URL:          url,
+		Symbols:      []string{"solusdt"},
+		PingInterval: 50 * time.Millisecond,
+		OnBookTicker: func(td entity.TickerData) { tickers <- td },
+		Snapshot:     snapshot,
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = c.Run(ctx) }()
+
+	select {
+	case td := <-tickers:
+		want := entity.TickerData{
+			Symbol: "SOLUSDT", Exchange: "mexc", BidPrice: 99.5, BidQty: 3, AskPrice: 100.5, AskQty: 4,
+		}
+		if td != want {
+			t.Errorf("book ticker = %+v, want %+v", td, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no book ticker received")
+	}
+
+	waitFor(t, "depth update", func() bool {
+		b, ok := c.OrderBook("SOLUSDT", 10)
+		return ok && len(b.Asks) == 3
+	})
+
+	book, _ := c.OrderBook("SOLUSDT", 10)
+	if len(book.Bids) != 2 || book.Bids[0].Price != 99 || book.Bids[1].Price != 98 {
+		t.Errorf("bids = %+v, want [99 98] after removal of 99.5", book.Bids)
+	}
+	if book.Asks[0].Price != 100.5 || book.Asks[1].Price != 101 || book.Asks[2].Price != 102 {
+		t.Errorf("asks not sorted ascending: %+v", book.Asks)
+	}
+
+	waitFor(t, "ping", func() bool { return srv.pings.Load() > 0 })
+}
+
+func TestClient_ReconnectAndResubscribe(t *testing.T) {
+	srv, url := startServer(t, func(send func(string), n int32) {
+		if n == 1 {
+			// Первое соединение обрывается сразу после подписки.
+			return
+		}
+		send(depthMsg1)
+		time.Sleep(time.Second)
+	})
+
+	c, err := NewClient(logger.New("error"), Config{
+		URL:          url,
+		Symbols:      []string{"SOLUSDT"},
+		ReconnectMin: 10 * time.Millisecond,
+		ReconnectMax: 20 * time.Millisecond,
+		Snapshot:     snapshot,
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx) }()
+
+	waitFor(t, "resubscription", func() bool { return srv.subscribed.Load() >= 2 })
+	waitFor(t, "order book after reconnect", func() bool {
+		b, ok := c.OrderBook("SOLUSDT", 0)
+		return ok && len(b.Asks) == 2
+	})
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run returned %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not stop after cancellation")
+	}
+}
+
+func TestEnvelope_SymbolFromChannel(t *testing.T) {
+	var env envelope
+	if err := json.Unmarshal([]byte(depthMsg1), &env); err != nil {
+		t.Fatal(err)
+	}
+	if got := symbolFromChannel(env.Channel); got != "SOLUSDT" {
+		t.Errorf("symbolFromChannel = %q, want SOLUSDT", got)
+	}
+}
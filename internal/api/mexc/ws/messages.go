@@ -0,0 +1,74 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Каналы публичного спотового WebSocket MEXC (JSON, v3).
+const (
+	bookTickerChannel = "spot@public.bookTicker.v3.api@"
+	depthChannel      = "spot@public.increase.depth.v3.api@"
+)
+
+// request — исходящее сообщение управления подпиской и keepalive.
+type request struct {
+	Method string   `json:"method"`
+	Params []string `json:"params,omitempty"`
+}
+
+// envelope — общий формат входящих сообщений: ответы на запросы (id/code/msg)
+// и push-данные каналов (c/s/t/d).
+type envelope struct {
+	ID      *int            `json:"id"`
+	Code    int             `json:"code"`
+	Msg     string          `json:"msg"`
+	Channel string          `json:"c"`
+	Symbol  string          `json:"s"`
+	Time    int64           `json:"t"`
+	Data    json.RawMessage `json:"d"`
+}
+
+// bookTickerData — лучшие цены стакана.
+type bookTickerData struct {
+	BidPrice string `json:"b"`
+	BidQty   string `json:"B"`
+	AskPrice string `json:"a"`
+	AskQty   string `json:"A"`
+}
+
+// depthLevel — изменение уровня стакана; нулевой объём означает удаление уровня.
+type depthLevel struct {
+	Price  string `json:"p"`
+	Volume string `json:"v"`
+}
+
+// depthData — инкрементальное обновление стакана. Version — монотонный номер версии.
+type depthData struct {
+	Bids    []depthLevel `json:"bids"`
+	Asks    []depthLevel `json:"asks"`
+	Event   string       `json:"e"`
+	Version string       `json:"r"`
+}
+
+func bookTickerTopic(symbol string) string { return bookTickerChannel + symbol }
+
+func depthTopic(symbol string) string { return depthChannel + symbol }
+
+// symbolFromChannel извлекает символ из имени канала, если поле s не заполнено.
+func symbolFromChannel(channel string) string {
+	if idx := strings.LastIndex(channel, "@"); idx >= 0 {
+		return channel[idx+1:]
+	}
+	return ""
+}
+
+func parseFloat(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %q: %w", s, err)
+	}
+	return v, nil
+}
@@ -2,29 +2,60 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"net"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/dimryb/cross-arb/internal/adapter/binance"
 	"github.com/dimryb/cross-arb/internal/adapter/jupiter"
 	"github.com/dimryb/cross-arb/internal/adapter/mexc"
+	"github.com/dimryb/cross-arb/internal/adapter/raydium"
+	jupiterapi "github.com/dimryb/cross-arb/internal/api/jupiter"
+	mexcws "github.com/dimryb/cross-arb/internal/api/mexc/ws"
 	"github.com/dimryb/cross-arb/internal/config"
 	"github.com/dimryb/cross-arb/internal/controller/grpc"
 	"github.com/dimryb/cross-arb/internal/controller/http"
 	"github.com/dimryb/cross-arb/internal/entity"
 	i "github.com/dimryb/cross-arb/internal/interface"
 	"github.com/dimryb/cross-arb/internal/logger"
+	"github.com/dimryb/cross-arb/internal/market"
+	"github.com/dimryb/cross-arb/internal/orderbook"
+	"github.com/dimryb/cross-arb/internal/recorder"
 	"github.com/dimryb/cross-arb/internal/report"
+	"github.com/dimryb/cross-arb/internal/repository"
+	"github.com/dimryb/cross-arb/internal/risk"
 	"github.com/dimryb/cross-arb/internal/service/scanner"
+	"github.com/dimryb/cross-arb/internal/service/ticker"
+	blockchain "github.com/dimryb/cross-arb/internal/solana"
 	"github.com/dimryb/cross-arb/internal/storage"
+	"github.com/dimryb/cross-arb/internal/usecase/execute"
+	"github.com/dimryb/cross-arb/internal/usecase/scan"
 )
 
+// mexcSnapshotDepth — глубина REST-снапшота, на который накладываются WebSocket-диффы MEXC.
+const mexcSnapshotDepth = 1000
+
+// defaultHTTPAddr — адрес HTTP API по умолчанию: управление риском доступно только локально.
+const defaultHTTPAddr = "127.0.0.1:8080"
+
+// defaultFiltersRefresh — период обновления торговых фильтров MEXC, если он не задан в конфиге.
+const defaultFiltersRefresh = time.Hour
+
+// defaultTokensRefresh — период обновления списка токенов Jupiter, если он не задан в конфиге.
+const defaultTokensRefresh = 6 * time.Hour
+
 type App struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 	log    i.Logger
 	store  i.TickerStore
+	// journal — журнал возможностей и исполнений; nil, если выключен.
+	journal *repository.Journal
+	// markets — реестр инструментов: символы, mint-адреса и фильтры площадок.
+	markets *market.Registry
 
 	cfg *config.CrossArbConfig
 }
@@ -56,8 +87,65 @@ func (a *App) Run() {
 	a.store = storage.NewTickerStore()
 	reportSvc := report.NewReportService(a.log, a.store)
 
+	if a.cfg.Journal.Enabled {
+		journal, err := repository.Open(a.cfg.Journal.Path)
+		if err != nil {
+			a.log.Fatalf("failed to open journal: %v", err)
+		}
+		a.journal = journal
+		defer func() {
+			if err := journal.Close(); err != nil {
+				a.log.Errorf("failed to close journal: %v", err)
+			}
+		}()
+	}
+
+	markets, err := newMarkets(a.cfg)
+	if err != nil {
+		a.log.Fatalf("invalid market configuration: %v", err)
+	}
+	a.markets = markets
+
+	httpAddr, err := httpListenAddr(a.cfg.HTTP)
+	if err != nil {
+		a.log.Fatalf("invalid http configuration: %v", err)
+	}
+
 	// --- Adapters ---
 	mexcAdapter := mexc.NewAdapter(a.log, 3*time.Second)
+	mexcAdapter.SetMarkets(a.markets)
+	if err := mexcAdapter.LoadFilters(a.ctx); err != nil {
+		a.log.Warn("failed to load mexc exchange info", slog.Any("err", err))
+	}
+	filtersRefresh, err := parseOptionalDuration(a.cfg.Exchanges[config.MexcExchange].FiltersRefresh)
+	if err != nil {
+		a.log.Fatalf("invalid mexc filtersRefresh: %v", err)
+	}
+	if filtersRefresh <= 0 {
+		filtersRefresh = defaultFiltersRefresh
+	}
+	go mexcAdapter.RefreshFilters(a.ctx, filtersRefresh)
+
+	// MEXC WebSocket: стаканы в памяти (REST-снапшот + диффы) и book ticker в хранилище вместо REST-опроса.
+	if mexcCfg := a.cfg.Exchanges[config.MexcExchange]; mexcCfg.Enabled && mexcCfg.WSURL != "" {
+		symbols := make([]string, 0, len(a.cfg.Scanner.Pairs))
+		for _, pair := range a.cfg.Scanner.Pairs {
+			symbols = append(symbols, a.markets.Symbol(config.MexcExchange, pair))
+		}
+		wsClient, err := mexcws.NewClient(a.log, mexcws.Config{
+			URL:          mexcCfg.WSURL,
+			Symbols:      symbols,
+			OnBookTicker: a.store.Set,
+			Snapshot: func(ctx context.Context, symbol string) (orderbook.Snapshot, error) {
+				return mexcAdapter.DepthSnapshot(ctx, symbol, mexcSnapshotDepth)
+			},
+		})
+		if err != nil {
+			a.log.Fatalf("failed to create mexc ws client: %v", err)
+		}
+		mexcAdapter.SetOrderBookSource(wsClient)
+		go func() { _ = wsClient.Run(a.ctx) }()
+	}
 
 	// Jupiter: собираем конфиг напрямую (заменяет фабрику)
 	jupCfg, ok := a.cfg.Exchanges[config.JupExchange]
@@ -68,22 +156,19 @@ func (a *App) Run() {
 		a.log.Fatalf("exchange %s is disabled", config.JupExchange)
 	}
 
-	pairMap := make(map[string]jupiter.MintPair, len(jupCfg.Pairs))
-	for symbol, p := range jupCfg.Pairs {
-		if p.Base == "" || p.Quote == "" {
-			a.log.Fatalf("missing mint address for Jupiter pair %q", symbol)
-		}
-		pairMap[symbol] = jupiter.MintPair{
-			BaseMint:  p.Base,
-			QuoteMint: p.Quote,
-		}
+	tokens, err := a.newTokenRegistry(jupCfg.Tokens)
+	if err != nil {
+		a.log.Fatalf("invalid jupiter tokens configuration: %v", err)
 	}
-
 	jupiterAdapter := jupiter.NewAdapter(a.log, &jupiter.AdapterConfig{
 		BaseURL: jupCfg.BaseURL,
 		Enabled: true,
 		Timeout: jupCfg.Timeout,
-		Pairs:   pairMap,
+		Markets: a.markets,
+		Tokens:  tokens,
+
+		SlippageBps:       jupCfg.SlippageBps,
+		MaxPriceImpactPct: jupCfg.MaxPriceImpactPct,
 	})
 
 	defer func() {
@@ -98,6 +183,21 @@ func (a *App) Run() {
 	}()
 
 	adapters := []i.EXAdapter{mexcAdapter, jupiterAdapter}
+	var binanceAdapter *binance.Adapter
+	if binCfg := a.cfg.Exchanges[config.BinanceExchange]; binCfg.Enabled {
+		binanceAdapter, err = a.newBinanceAdapter(binCfg)
+		if err != nil {
+			a.log.Fatalf("failed to create binance adapter: %v", err)
+		}
+		adapters = append(adapters, binanceAdapter)
+	}
+	if rayCfg := a.cfg.Exchanges[config.RaydiumExchange]; rayCfg.Enabled {
+		raydiumAdapter, err := a.newRaydiumAdapter(rayCfg)
+		if err != nil {
+			a.log.Fatalf("failed to create raydium adapter: %v", err)
+		}
+		adapters = append(adapters, raydiumAdapter)
+	}
 
 	pricesCh := make(chan entity.ExecutableQuote, a.cfg.Scanner.Buffers.Prices)
 	orderBooksCh := make(chan entity.OrderBookResult, a.cfg.Scanner.Buffers.OrderBooks)
@@ -111,18 +211,119 @@ func (a *App) Run() {
 		a.cancel()
 		return
 	}
-	_, err = scanner.NewService(
+	// maxQuoteAge необязателен: при пустом значении детектор берёт значение по умолчанию.
+	var maxQuoteAge time.Duration
+	if a.cfg.Scanner.MaxQuoteAge != "" {
+		maxQuoteAge, err = time.ParseDuration(a.cfg.Scanner.MaxQuoteAge)
+		if err != nil {
+			a.log.Error("invalid scanner maxQuoteAge",
+				slog.String("value", a.cfg.Scanner.MaxQuoteAge),
+				slog.Any("err", err))
+			a.cancel()
+			return
+		}
+	}
+	// Журнал получает возможности раньше риска и исполнения: сохраняется всё, что нашёл детектор.
+	detectedCh := oppCh
+	if a.journal != nil {
+		detectedCh = make(chan entity.ArbOpportunity, a.cfg.Scanner.Buffers.Opportunities)
+		go a.journalOpportunities(detectedCh, oppCh)
+	}
+
+	var rec *recorder.Recorder
+	if a.cfg.Recorder.Enabled {
+		rec, err = a.startRecorder()
+		if err != nil {
+			a.log.Error("Failed to start recorder", slog.Any("err", err))
+			a.cancel()
+			return
+		}
+	}
+
+	// Последние стаканы — для симуляции исполнения в режиме бумажной торговли.
+	books := execute.NewBookCache()
+
+	// Исполнение запускается до сканера: трекер балансов ограничивает и подбор объёма.
+	var (
+		balanceCap func(entity.ArbOpportunity) float64
+		riskMgr    *risk.Manager
+	)
+	if a.cfg.Execution.Enabled {
+		riskCfg := a.cfg.Execution.Risk
+		riskMgr, err = risk.NewManager(a.log, risk.Limits{
+			MaxTradeNotional:   riskCfg.MaxTradeNotional,
+			MaxExposure:        riskCfg.MaxExposure,
+			MaxDailyLoss:       riskCfg.MaxDailyLoss,
+			MaxTradesPerMinute: riskCfg.MaxTradesPerMinute,
+		})
+		if err != nil {
+			a.log.Error("Failed to create risk manager", slog.Any("err", err))
+			a.cancel()
+			return
+		}
+		balanceCap, err = a.startExecution(adapters, jupiterAdapter, books, riskMgr, oppCh)
+		if err != nil {
+			a.log.Error("Failed to start execution", slog.Any("err", err))
+			a.cancel()
+			return
+		}
+	} else {
+		go a.logOpportunities(oppCh)
+	}
+
+	var detector scan.ArbOpportunityUseCase = scan.NewSizedOpportunityUseCase(a.log,
+		scan.NewFeeAwareOpportunityUseCase(a.log, adapters, scan.OpportunityOptions{
+			MinSpreadPct: a.cfg.Scanner.MinSpreadPct,
+			MaxQuoteAge:  maxQuoteAge,
+		}),
+		adapters,
+		scan.SizingOptions{
+			MinSize:    a.cfg.Scanner.Sizing.MinSize,
+			MaxSize:    a.cfg.Scanner.Sizing.MaxSize,
+			MaxQuote:   a.cfg.Scanner.Sizing.MaxQuote,
+			Iterations: a.cfg.Scanner.Sizing.Iterations,
+			BalanceCap: balanceCap,
+			Filters:    a.markets.Filters,
+		},
+	)
+	// Циклы пока только публикуются в лог: движок исполняет двухходовые сделки.
+	if cyclesCfg := a.cfg.Scanner.Cycles; cyclesCfg.Enabled {
+		cyclesCh := make(chan entity.CycleOpportunity, a.cfg.Scanner.Buffers.Opportunities)
+		go a.logCycles(cyclesCh)
+		detector = scan.NewCycleAwareOpportunityUseCase(detector,
+			scan.NewCycleDetector(a.log, adapters, scan.CycleOptions{
+				MinProfitPct: cyclesCfg.MinProfitPct,
+				MaxQuoteAge:  maxQuoteAge,
+				StartAssets:  cyclesCfg.StartAssets,
+			}),
+			cyclesCh,
+		)
+	}
+
+	// Глубина опроса стаканов — orderLimit каждой биржи.
+	depths := make(map[string]int, len(a.cfg.Exchanges))
+	for name, ex := range a.cfg.Exchanges {
+		depths[name] = ex.OrderLimit
+	}
+
+	scannerSvc, err := scanner.NewService(
 		a.log,
 		interval,
-		1.0, // placeholder: объём сделки в BASE для квотирования DEX
+		a.cfg.Scanner.Sizing.MinSize, // пробные котировки — на минимальный объём сделки
 		a.cfg.Scanner.Pairs,
 		adapters,
 		pricesCh,
 		orderBooksCh,
-		oppCh,
-		nil, // используем DEXPriceUseCase по умолчанию
-		nil, // используем CEXOrderBookUseCase по умолчанию
-		nil, // используем OpportunityUseCase по умолчанию
+		detectedCh,
+		scan.NewPollingDEXPriceUseCase(a.log, scan.DEXPriceOptions{
+			Concurrency: a.cfg.Scanner.Concurrency,
+			CallTimeout: jupCfg.Timeout,
+		}),
+		scan.NewPollingCEXOrderBookUseCase(a.log, scan.CEXOrderBookOptions{
+			ExchangeDepth: depths,
+			Concurrency:   a.cfg.Scanner.Concurrency,
+		}),
+		detector,
 	)
 	if err != nil {
 		a.log.Error("Failed to create scanner service", slog.Any("err", err))
@@ -133,6 +334,9 @@ func (a *App) Run() {
 	// Заглушки консьюмеры
 	go func() {
 		for pp := range pricesCh {
+			if rec != nil {
+				rec.RecordQuote(pp)
+			}
 			a.log.Info("price point",
 				slog.String("pair", pp.Pair),
 				slog.String("exchange", pp.Exchange),
@@ -144,26 +348,59 @@ func (a *App) Run() {
 	}()
 
 	go func() {
-		for opp := range oppCh {
-			a.log.Info("opportunity",
-				slog.String("pair", opp.Pair),
-				slog.String("buy_on", opp.BuyOn),
-				slog.Float64("buy_price", opp.BuyPrice),
-				slog.String("sell_on", opp.SellOn),
-				slog.Float64("sell_price", opp.SellPrice),
-				slog.Float64("net", opp.NetPnl),
-				slog.Float64("spread_pct", opp.SpreadPct),
-				slog.Time("ts", opp.DetectedAt),
+		for ob := range orderBooksCh {
+			books.Update(ob)
+			if rec != nil {
+				rec.RecordBook(ob)
+			}
+			if ob.Error != nil {
+				a.log.Warn("order book error",
+					slog.String("pair", ob.Symbol),
+					slog.String("exchange", ob.Exchange),
+					slog.Any("err", ob.Error),
+				)
+				continue
+			}
+			a.log.Debug("order book",
+				slog.String("pair", ob.Symbol),
+				slog.String("exchange", ob.Exchange),
+				slog.Int("bids", len(ob.Data.Bids)),
+				slog.Int("asks", len(ob.Data.Asks)),
+				slog.Time("ts", ob.Timestamp),
 			)
 		}
 	}()
 
+	// Лучшие цены Binance — в хранилище тикеров с интервалом сканера.
+	if binanceAdapter != nil {
+		poller, err := ticker.NewPoller(a.log, binanceAdapter, a.store, a.cfg.Scanner.Pairs, ticker.Options{
+			Interval:    interval,
+			CallTimeout: a.cfg.Exchanges[config.BinanceExchange].Timeout,
+		})
+		if err != nil {
+			a.log.Error("Failed to create binance book ticker poller", slog.Any("err", err))
+			a.cancel()
+			return
+		}
+		go func() { _ = poller.Run(a.ctx) }()
+	}
+
+	go func() {
+		if err := scannerSvc.Start(a.ctx); err != nil {
+			a.log.Error("scanner failed", slog.Any("err", err))
+			a.cancel()
+		}
+	}()
+
 	// TODO: вынести порты в конфиг
 	grpcServer := grpc.NewServer(a, grpc.ServerConfig{Port: "9090"}, a.log)
 
 	go func() {
-		httpServer := http.NewHTTPServer(a.store)
-		if err := httpServer.Run(":8080"); err != nil {
+		httpServer := http.NewHTTPServer(a.store, riskMgr, a.journal, http.ServerConfig{
+			Token:       a.cfg.HTTP.Token,
+			AllowResume: a.cfg.HTTP.AllowResume,
+		})
+		if err := httpServer.Run(httpAddr); err != nil {
 			a.log.Errorf("HTTP server error: %v", err)
 			a.cancel()
 		}
@@ -189,3 +426,180 @@ func (a *App) Run() {
 
 	a.log.Info("App stopped gracefully")
 }
+
+// logOpportunities логирует найденные возможности, когда исполнение выключено.
+func (a *App) logOpportunities(oppCh <-chan entity.ArbOpportunity) {
+	for opp := range oppCh {
+		a.log.Info("opportunity",
+			slog.String("pair", opp.Pair),
+			slog.String("buy_on", opp.BuyOn),
+			slog.Float64("buy_price", opp.BuyPrice),
+			slog.String("sell_on", opp.SellOn),
+			slog.Float64("sell_price", opp.SellPrice),
+			slog.Float64("net", opp.NetPnl),
+			slog.Float64("spread_pct", opp.SpreadPct),
+			slog.Float64("size", opp.Size),
+			slog.Float64("expected_pnl", opp.ExpectedPnl),
+			slog.Time("ts", opp.DetectedAt),
+		)
+	}
+}
+
+func (a *App) logCycles(cyclesCh <-chan entity.CycleOpportunity) {
+	for c := range cyclesCh {
+		a.log.Info("cycle opportunity",
+			slog.String("path", c.Path()),
+			slog.Int("legs", len(c.Legs)),
+			slog.Float64("return", c.Return),
+			slog.Float64("profit_pct", c.ProfitPct),
+			slog.Time("ts", c.DetectedAt),
+		)
+	}
+}
+
+// journalOpportunities сохраняет возможности в журнал и передаёт их дальше в out.
+// Запись идёт после передачи, чтобы не задерживать исполнение.
+func (a *App) journalOpportunities(in <-chan entity.ArbOpportunity, out chan<- entity.ArbOpportunity) {
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case opp := <-in:
+			select {
+			case <-a.ctx.Done():
+				return
+			case out <- opp:
+			}
+			if err := a.journal.SaveOpportunity(opp); err != nil {
+				a.log.Warn("failed to journal opportunity", slog.Any("err", err))
+			}
+		}
+	}
+}
+
+// startRecorder запускает запись истории: котировки и стаканы передаются из потребителей
+// каналов сканера, тикеры — по подписке на хранилище.
+func (a *App) startRecorder() (*recorder.Recorder, error) {
+	var rotate time.Duration
+	if a.cfg.Recorder.Rotate != "" {
+		var err error
+		if rotate, err = time.ParseDuration(a.cfg.Recorder.Rotate); err != nil {
+			return nil, fmt.Errorf("invalid recorder rotate %q: %w", a.cfg.Recorder.Rotate, err)
+		}
+	}
+	rec, err := recorder.NewRecorder(a.log, recorder.Options{Dir: a.cfg.Recorder.Dir, Rotate: rotate})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		if err := rec.Run(a.ctx); err != nil {
+			a.log.Error("recorder failed", slog.Any("err", err))
+		}
+	}()
+
+	sub := a.store.AddSubscriber()
+	go func() {
+		<-a.ctx.Done()
+		sub.Close()
+	}()
+	go func() {
+		for {
+			ev, ok := sub.Recv()
+			if !ok {
+				return
+			}
+			rec.RecordTicker(ev.Ticker)
+		}
+	}()
+	return rec, nil
+}
+
+// newRaydiumAdapter создаёт адаптер пулов Raydium AMM v4: листинги реестра задают
+// mint-адреса BASE/QUOTE и адрес пула, состояние читается через RPC Solana.
+func (a *App) newRaydiumAdapter(cfg config.Exchange) (*raydium.Adapter, error) {
+	for _, l := range a.markets.Listings(config.RaydiumExchange) {
+		if l.Address == "" {
+			return nil, fmt.Errorf("missing pool address for pair %q", l.Pair())
+		}
+	}
+	client, err := blockchain.NewSolanaClient(a.log, cfg.RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("solana client: %w", err)
+	}
+	go func() {
+		<-a.ctx.Done()
+		client.Close()
+	}()
+	return raydium.NewAdapter(a.log, client, a.markets), nil
+}
+
+// newBinanceAdapter создаёт адаптер Binance-совместимой биржи. Символы пар берутся
+// из реестра инструментов; при наличии ключей загружаются комиссии аккаунта.
+func (a *App) newBinanceAdapter(cfg config.Exchange) (*binance.Adapter, error) {
+	ad, err := binance.NewAdapter(a.log, binance.Config{
+		BaseURL:   cfg.BaseURL,
+		APIKey:    cfg.APIKey,
+		SecretKey: cfg.SecretKey,
+		Timeout:   cfg.Timeout,
+		Markets:   a.markets,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cfg.APIKey != "" {
+		if err := ad.RefreshFees(a.ctx); err != nil {
+			a.log.Warn("failed to load binance fees, using defaults", slog.Any("err", err))
+		}
+	}
+	return ad, nil
+}
+
+// newTokenRegistry создаёт список токенов Jupiter с кешем на диске и ручными записями,
+// загружает его и запускает периодическое обновление. Недоступный список не мешает
+// запуску: decimals из листингов и ручные записи работают и без него.
+func (a *App) newTokenRegistry(cfg config.TokensConfig) (*jupiterapi.TokenRegistry, error) {
+	refresh, err := parseOptionalDuration(cfg.Refresh)
+	if err != nil {
+		return nil, fmt.Errorf("tokens refresh: %w", err)
+	}
+	if refresh <= 0 {
+		refresh = defaultTokensRefresh
+	}
+
+	overrides := make([]jupiterapi.TokenEntry, 0, len(cfg.Overrides))
+	for _, t := range cfg.Overrides {
+		overrides = append(overrides, jupiterapi.TokenEntry{Symbol: t.Symbol, Address: t.Mint, Decimals: t.Decimals})
+	}
+	tokens := jupiterapi.NewTokenRegistry(a.log, jupiterapi.TokenRegistryConfig{
+		URL:       cfg.ListURL,
+		CachePath: cfg.Cache,
+		Overrides: overrides,
+	})
+	if err := tokens.Load(a.ctx); err != nil {
+		a.log.Warn("failed to load jupiter token list", slog.Any("err", err))
+	}
+
+	go tokens.Refresh(a.ctx, refresh)
+	return tokens, nil
+}
+
+// httpListenAddr возвращает адрес HTTP API. Слушать не только loopback можно лишь с токеном:
+// POST /risk/* управляют торговлей.
+func httpListenAddr(cfg config.HTTPConfig) (string, error) {
+	addr := cfg.Addr
+	if addr == "" {
+		addr = defaultHTTPAddr
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("http addr %q: %w", addr, err)
+	}
+	if cfg.Token != "" || host == "localhost" {
+		return addr, nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return "", fmt.Errorf("http addr %q is not loopback: set http.token", addr)
+	}
+	return addr, nil
+}
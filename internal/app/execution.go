@@ -0,0 +1,364 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/adapter/jupiter"
+	"github.com/dimryb/cross-arb/internal/api/mexc/trade"
+	"github.com/dimryb/cross-arb/internal/config"
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/market"
+	"github.com/dimryb/cross-arb/internal/risk"
+	"github.com/dimryb/cross-arb/internal/service/balance"
+	"github.com/dimryb/cross-arb/internal/service/rebalance"
+	blockchain "github.com/dimryb/cross-arb/internal/solana"
+	"github.com/dimryb/cross-arb/internal/usecase/execute"
+	"github.com/dimryb/cross-arb/internal/usecase/swap"
+	"github.com/dimryb/cross-arb/internal/wallet"
+	"github.com/gagliardetto/solana-go"
+)
+
+// balanceRefreshTimeout ограничивает обновление остатков после исполнения: движок ждёт его
+// перед следующей возможностью.
+const balanceRefreshTimeout = 5 * time.Second
+
+// startExecution собирает движок исполнения и запускает его на потоке возможностей
+// вместе с трекером балансов, ограничивающим объём сделок, и (для реальной торговли)
+// ребалансировщиком остатков. Возможности проходят через менеджер риска riskMgr,
+// записи об исполнении учитываются в нём и логируются.
+// В режиме бумажной торговли ордера симулируются по стаканам из books и котировкам
+// Jupiter на виртуальных балансах.
+//
+// Возвращает ограничение объёма по балансам, чтобы применять его и при подборе в сканере.
+func (a *App) startExecution(
+	adapters []i.EXAdapter,
+	jupiterAdapter *jupiter.Adapter,
+	books *execute.BookCache,
+	riskMgr *risk.Manager,
+	oppCh <-chan entity.ArbOpportunity,
+) (func(entity.ArbOpportunity) float64, error) {
+	cfg := a.cfg.Execution
+
+	legTimeout, err := parseOptionalDuration(cfg.LegTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("execution legTimeout: %w", err)
+	}
+	maxAge, err := parseOptionalDuration(cfg.MaxOpportunityAge)
+	if err != nil {
+		return nil, fmt.Errorf("execution maxOpportunityAge: %w", err)
+	}
+	balanceInterval, err := parseOptionalDuration(cfg.Balances.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("execution balances interval: %w", err)
+	}
+
+	var (
+		legs    map[string]execute.Leg
+		sources []balance.Source
+		account *execute.PaperAccount
+		live    *liveVenues
+	)
+	if cfg.Paper.Enabled {
+		account = execute.NewPaperAccount(cfg.Paper.Balances)
+		legs = paperLegs(adapters, account, books)
+		for venue := range cfg.Paper.Balances {
+			sources = append(sources, paperBalanceSource{venue: venue, account: account})
+		}
+		a.log.Warn("paper trading enabled: orders are simulated")
+	} else {
+		live, err = a.newLiveVenues(jupiterAdapter)
+		if err != nil {
+			return nil, err
+		}
+		legs, sources = live.legs, live.sources
+	}
+
+	tracker, err := balance.NewTracker(a.log, sources, balance.Options{
+		Interval: balanceInterval,
+		Headroom: cfg.Balances.Headroom,
+	})
+	if err != nil {
+		return nil, err
+	}
+	go func() { _ = tracker.Run(a.ctx) }()
+
+	balanceCap := tracker.Cap
+	switch {
+	case !cfg.Rebalance.Enabled:
+	case live == nil:
+		a.log.Warn("rebalancing is not available in paper trading mode")
+	default:
+		rebalancer, err := a.newRebalancer(tracker, live)
+		if err != nil {
+			return nil, err
+		}
+		go func() { _ = rebalancer.Run(a.ctx) }()
+
+		// Пока перевод в пути, направление, продолжающее перекос, не торгуется.
+		balanceCap = func(opp entity.ArbOpportunity) float64 {
+			if rebalancer.Paused(opp) {
+				return 0
+			}
+			return tracker.Cap(opp)
+		}
+	}
+
+	engine, err := execute.NewEngine(a.log, legs, adapters, execute.Options{
+		Mode:              execute.Mode(cfg.Mode),
+		LegTimeout:        legTimeout,
+		MaxOpportunityAge: maxAge,
+		Hedge:             cfg.Hedge,
+		MaxHedgeLoss:      cfg.MaxHedgeLoss,
+		BalanceCap:        balanceCap,
+		// Остатки изменились сделкой: следующая возможность урезается по свежим.
+		AfterExecute: func(ctx context.Context, _ entity.Execution) {
+			ctx, cancel := context.WithTimeout(ctx, balanceRefreshTimeout)
+			defer cancel()
+			if err := tracker.Refresh(ctx); err != nil {
+				a.log.Warn("balance refresh after execution failed", slog.Any("err", err))
+			}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	admittedCh := make(chan entity.ArbOpportunity)
+	go func() {
+		if err := riskMgr.Filter(a.ctx, oppCh, admittedCh); err != nil && a.ctx.Err() == nil {
+			a.log.Error("risk filter failed", slog.Any("err", err))
+			a.cancel()
+		}
+	}()
+
+	execCh := make(chan entity.Execution)
+	go func() {
+		if err := engine.Run(a.ctx, admittedCh, execCh); err != nil && a.ctx.Err() == nil {
+			a.log.Error("execution engine failed", slog.Any("err", err))
+			a.cancel()
+		}
+	}()
+
+	go func() {
+		marks := make(map[string]float64)
+		for {
+			select {
+			case <-a.ctx.Done():
+				return
+			case ex := <-execCh:
+				riskMgr.Record(ex)
+				if a.journal != nil {
+					if err := a.journal.SaveExecution(ex); err != nil {
+						a.log.Warn("failed to journal execution", slog.String("id", ex.ID), slog.Any("err", err))
+					}
+				}
+				attrs := []any{
+					slog.String("id", ex.ID),
+					slog.String("pair", ex.Opportunity.Pair),
+					slog.String("status", string(ex.Status)),
+					slog.Float64("bought", ex.Buy.Filled),
+					slog.Float64("sold", ex.Sell.Filled),
+					slog.Float64("realized_pnl", ex.RealizedPnl),
+					slog.Float64("unhedged", ex.Unhedged),
+				}
+				if ex.Hedge != nil {
+					attrs = append(attrs,
+						slog.String("hedge_on", ex.Hedge.Exchange),
+						slog.String("hedge_side", string(ex.Hedge.Side)),
+						slog.Float64("hedge_filled", ex.Hedge.Filled),
+					)
+				}
+				a.log.Info("execution", attrs...)
+
+				if account != nil {
+					// Оценка по середине цен возможности; QUOTE-актив — 1.
+					if inst, err := market.ParsePair(ex.Opportunity.Pair); err == nil {
+						marks[inst.Base] = (ex.Opportunity.BuyPrice + ex.Opportunity.SellPrice) / 2
+						marks[inst.Quote] = 1
+					}
+					a.log.Info("paper account",
+						slog.Float64("pnl", account.PnL(marks)),
+						slog.Any("balances", account.Balances()),
+					)
+				}
+			}
+		}
+	}()
+	return balanceCap, nil
+}
+
+// liveVenues — клиенты реальной торговли и построенные на них исполнители и источники остатков.
+type liveVenues struct {
+	legs    map[string]execute.Leg
+	sources []balance.Source
+
+	mexc   *trade.Client
+	solana *blockchain.Client
+	signer *wallet.PhantomWallet
+	mints  map[string]string // актив → mint
+}
+
+// newLiveVenues создаёт исполнителей реальных ордеров (MEXC по API-ключам, Jupiter с кошельком Solana)
+// и источники их остатков: спотовый счёт MEXC и SOL/SPL-токены кошелька.
+func (a *App) newLiveVenues(jupiterAdapter *jupiter.Adapter) (*liveVenues, error) {
+	mexcCfg := a.cfg.Exchanges[config.MexcExchange]
+	mexcTrader, err := trade.NewClient(a.log, trade.Config{
+		APIKey:    mexcCfg.APIKey,
+		SecretKey: mexcCfg.SecretKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mexc trade client: %w", err)
+	}
+
+	jupCfg := a.cfg.Exchanges[config.JupExchange]
+	signer, err := wallet.NewPhantomWallet(jupCfg.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("solana wallet: %w", err)
+	}
+	swapper, err := swap.NewSwapper(a.log, jupCfg.BaseURL, jupCfg.RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("swapper: %w", err)
+	}
+	solClient, err := blockchain.NewSolanaClient(a.log, jupCfg.RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("solana client: %w", err)
+	}
+
+	// Активы кошелька — по mint из листингов Jupiter; нативный SOL учитывается вместе с wSOL.
+	mints := make(map[string]string)
+	var nativeAsset string
+	for _, l := range a.markets.Listings(config.JupExchange) {
+		base, quote := l.Instrument.Base, l.Instrument.Quote
+		mints[base], mints[quote] = l.BaseMint, l.QuoteMint
+		switch solana.SolMint.String() {
+		case l.BaseMint:
+			nativeAsset = base
+		case l.QuoteMint:
+			nativeAsset = quote
+		}
+	}
+
+	mexcLeg := execute.NewMexcLeg(mexcTrader, a.markets.SymbolFunc(config.MexcExchange))
+	mexcLeg.SetFilters(func(pair string) (market.Filters, bool) {
+		return a.markets.Filters(config.MexcExchange, pair)
+	})
+
+	return &liveVenues{
+		legs: map[string]execute.Leg{
+			config.MexcExchange: mexcLeg,
+			config.JupExchange:  execute.NewJupiterLeg(a.log, jupiterAdapter, swapper, signer, solClient),
+		},
+		sources: []balance.Source{
+			balance.NewMexcSource(config.MexcExchange, mexcTrader),
+			balance.NewSolanaSource(config.JupExchange, solClient, signer.PublicKey(), mints,
+				nativeAsset, a.cfg.Execution.Balances.SolReserve),
+		},
+		mexc:   mexcTrader,
+		solana: solClient,
+		signer: signer,
+		mints:  mints,
+	}, nil
+}
+
+// newRebalancer создаёт ребалансировщик остатков между MEXC и кошельком Solana.
+func (a *App) newRebalancer(balances rebalance.BalanceView, live *liveVenues) (*rebalance.Rebalancer, error) {
+	cfg := a.cfg.Execution.Rebalance
+	interval, err := parseOptionalDuration(cfg.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("rebalance interval: %w", err)
+	}
+	pendingTimeout, err := parseOptionalDuration(cfg.PendingTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("rebalance pendingTimeout: %w", err)
+	}
+
+	assets := make(map[string]rebalance.Asset, len(cfg.Assets))
+	for asset, ac := range cfg.Assets {
+		assets[asset] = rebalance.Asset{Target: ac.Target, MinAmount: ac.MinAmount, Network: ac.Network}
+	}
+	opts := rebalance.Options{
+		CEX:            config.MexcExchange,
+		Wallet:         config.JupExchange,
+		WalletAddress:  live.signer.PublicKey().String(),
+		Assets:         assets,
+		Threshold:      cfg.Threshold,
+		Execute:        cfg.Execute,
+		Interval:       interval,
+		PendingTimeout: pendingTimeout,
+	}
+	if a.journal != nil {
+		opts.Store = a.journal
+	}
+	return rebalance.NewRebalancer(a.log, balances, live.mexc, walletSender{live: live}, opts)
+}
+
+// walletSender отправляет активы с кошелька Solana по их mint из пар Jupiter.
+type walletSender struct {
+	live *liveVenues
+}
+
+func (w walletSender) Send(ctx context.Context, asset, to string, amount float64) (string, error) {
+	mint, ok := w.live.mints[asset]
+	if !ok {
+		return "", fmt.Errorf("unknown mint for %s", asset)
+	}
+	toKey, err := solana.PublicKeyFromBase58(to)
+	if err != nil {
+		return "", fmt.Errorf("deposit address %q: %w", to, err)
+	}
+	mintKey, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		return "", fmt.Errorf("mint %q: %w", mint, err)
+	}
+	sig, err := w.live.solana.Transfer(ctx, w.live.signer, toKey, mintKey, amount)
+	if err != nil {
+		// Транзакция могла уйти в сеть: сигнатура нужна, чтобы найти зачисление.
+		if !sig.IsZero() {
+			return sig.String(), err
+		}
+		return "", err
+	}
+	return sig.String(), nil
+}
+
+// paperBalanceSource отдаёт трекеру виртуальные остатки бумажного счёта по бирже.
+type paperBalanceSource struct {
+	venue   string
+	account *execute.PaperAccount
+}
+
+func (p paperBalanceSource) Venue() string { return p.venue }
+
+func (p paperBalanceSource) Balances(context.Context) (map[string]float64, error) {
+	return p.account.Balances()[p.venue], nil
+}
+
+// paperLegs создаёт симуляторы для всех бирж: адаптеры с исполнимыми котировками
+// обмена (Jupiter) симулируются по котировке обмена, прочие DEX (пулы AMM) — по
+// эффективной цене Quote, остальные — по последнему стакану.
+func paperLegs(adapters []i.EXAdapter, account *execute.PaperAccount, books *execute.BookCache) map[string]execute.Leg {
+	legs := make(map[string]execute.Leg, len(adapters))
+	for _, ad := range adapters {
+		switch q := ad.(type) {
+		case execute.SwapQuoter:
+			legs[ad.Name()] = execute.NewPaperSwapLeg(account, ad, q)
+		case i.DEXAdapter:
+			legs[ad.Name()] = execute.NewPaperQuoteLeg(account, q)
+		default:
+			legs[ad.Name()] = execute.NewPaperBookLeg(account, ad, books)
+		}
+	}
+	return legs
+}
+
+// parseOptionalDuration разбирает длительность; пустая строка означает значение по умолчанию (0).
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
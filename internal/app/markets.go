@@ -0,0 +1,68 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dimryb/cross-arb/internal/config"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+// newMarkets строит реестр инструментов по конфигурации площадок.
+//
+//   - Jupiter и Raydium: пары из exchanges.<dex>.pairs, base/quote — mint-адреса
+//     (обязательны), decimals и адрес пула — необязательны.
+//   - CEX: пары из exchanges.<cex>.pairs задают тикеры активов на бирже (символ —
+//     base+quote), остальные пары сканера получают символ по умолчанию.
+func newMarkets(cfg *config.CrossArbConfig) (*market.Registry, error) {
+	reg := market.NewRegistry()
+
+	names := make([]string, 0, len(cfg.Exchanges))
+	for name := range cfg.Exchanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, venue := range names {
+		ex := cfg.Exchanges[venue]
+		dex := venue == config.JupExchange || venue == config.RaydiumExchange
+
+		for pair, pc := range ex.Pairs {
+			inst, err := market.ParsePair(pair)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", venue, err)
+			}
+			l := market.Listing{Venue: venue, Instrument: inst}
+			if dex {
+				if pc.Base == "" || pc.Quote == "" {
+					return nil, fmt.Errorf("%s: missing mint address for pair %q", venue, pair)
+				}
+				l.BaseMint, l.QuoteMint = pc.Base, pc.Quote
+				l.BaseDecimals, l.QuoteDecimals = pc.BaseDecimals, pc.QuoteDecimals
+				l.Address = pc.Pool
+			} else if pc.Base != "" && pc.Quote != "" {
+				l.Symbol = strings.ToUpper(pc.Base + pc.Quote)
+			}
+			if err := reg.Add(l); err != nil {
+				return nil, err
+			}
+		}
+		if dex {
+			continue
+		}
+		for _, pair := range cfg.Scanner.Pairs {
+			inst, err := market.ParsePair(pair)
+			if err != nil {
+				return nil, fmt.Errorf("scanner: %w", err)
+			}
+			if _, ok := reg.Listing(venue, pair); ok {
+				continue
+			}
+			if err := reg.Add(market.Listing{Venue: venue, Instrument: inst}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return reg, nil
+}
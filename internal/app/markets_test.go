@@ -0,0 +1,42 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/dimryb/cross-arb/internal/config"
+)
+
+func TestNewMarkets(t *testing.T) {
+	cfg := &config.CrossArbConfig{
+		Exchanges: map[string]config.Exchange{
+			config.MexcExchange: {},
+			config.BinanceExchange: {Pairs: map[string]config.PairConfig{
+				"SOL/USD": {Base: "SOL", Quote: "FDUSD"},
+			}},
+			config.JupExchange: {Pairs: map[string]config.PairConfig{
+				"SOL/USDT": {Base: "So11111111111111111111111111111111111111112", Quote: "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB", QuoteDecimals: 6},
+			}},
+		},
+		Scanner: config.ScannerConfig{Pairs: []string{"SOL/USDT", "SOL/USD"}},
+	}
+	reg, err := newMarkets(cfg)
+	if err != nil {
+		t.Fatalf("newMarkets: %v", err)
+	}
+
+	if got := reg.Symbol(config.BinanceExchange, "SOL/USD"); got != "SOLFDUSD" {
+		t.Errorf("binance SOL/USD symbol = %s", got)
+	}
+	if got := len(reg.Listings(config.MexcExchange)); got != 2 {
+		t.Errorf("mexc listings = %d, want 2 scanner pairs", got)
+	}
+	jup := reg.Listings(config.JupExchange)
+	if len(jup) != 1 || jup[0].BaseMint != "So11111111111111111111111111111111111111112" || jup[0].QuoteDecimals != 6 {
+		t.Errorf("jupiter listings = %+v", jup)
+	}
+
+	cfg.Exchanges[config.JupExchange].Pairs["JUP/USDT"] = config.PairConfig{Quote: "x"}
+	if _, err := newMarkets(cfg); err == nil {
+		t.Error("expected error for DEX pair without mints")
+	}
+}
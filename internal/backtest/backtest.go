@@ -0,0 +1,246 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/recorder"
+	"github.com/dimryb/cross-arb/internal/usecase/scan"
+)
+
+// EventSource — источник записанных событий (recorder.Reader).
+type EventSource interface {
+	// Next возвращает следующее событие или io.EOF.
+	Next() (recorder.Event, error)
+}
+
+// Options — параметры прогона.
+type Options struct {
+	// Speed — скорость воспроизведения относительно реального времени (10 — в 10 раз быстрее).
+	// 0 — без пауз, с максимальной скоростью.
+	Speed float64
+	// Size — объём сделки в BASE для гипотетического PnL: объём, под который записаны котировки.
+	Size float64
+	// FeeMultipliers — множители taker-комиссий, для каждого из которых прогоняется свой
+	// детектор. При пустом значении — только фактические комиссии (1).
+	FeeMultipliers []float64
+	// Detector — параметры детектора; EventTime включается всегда.
+	Detector scan.OpportunityOptions
+}
+
+// PairStats — итоги по паре и направлению.
+type PairStats struct {
+	Pair          string  `json:"pair"`
+	BuyOn         string  `json:"buyOn"`
+	SellOn        string  `json:"sellOn"`
+	Opportunities int     `json:"opportunities"`
+	Pnl           float64 `json:"pnl"`
+	MaxSpreadPct  float64 `json:"maxSpreadPct"`
+}
+
+// Scenario — итоги прогона с одним множителем комиссий.
+type Scenario struct {
+	FeeMultiplier float64     `json:"feeMultiplier"`
+	Opportunities int         `json:"opportunities"`
+	Pnl           float64     `json:"pnl"` // Гипотетический PnL: NetPnl * Size по всем возможностям, QUOTE
+	Pairs         []PairStats `json:"pairs"`
+}
+
+// Report — итоги воспроизведения истории.
+type Report struct {
+	From      time.Time             `json:"from"`
+	To        time.Time             `json:"to"`
+	Events    map[recorder.Kind]int `json:"events"`
+	Scenarios []Scenario            `json:"scenarios"`
+}
+
+// Run воспроизводит события src через детектор FeeAwareOpportunityUseCase — по одному
+// на каждый множитель комиссий — и собирает отчёт. Котировки подаются в детектор
+// в порядке записи, возраст котировок отсчитывается от их собственного времени.
+// Тикеры и стаканы только учитываются в статистике событий.
+func Run(ctx context.Context, log i.Logger, src EventSource, adapters []i.EXAdapter, opts Options) (Report, error) {
+	if opts.Size <= 0 {
+		return Report{}, fmt.Errorf("size must be positive, got %v", opts.Size)
+	}
+	if opts.Speed < 0 {
+		return Report{}, fmt.Errorf("speed must not be negative, got %v", opts.Speed)
+	}
+	multipliers := opts.FeeMultipliers
+	if len(multipliers) == 0 {
+		multipliers = []float64{1}
+	}
+	detectorOpts := opts.Detector
+	detectorOpts.EventTime = true
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	runs := make([]*scenarioRun, len(multipliers))
+	for n, k := range multipliers {
+		if k < 0 {
+			return Report{}, fmt.Errorf("fee multiplier must not be negative, got %v", k)
+		}
+		scaled := make([]i.EXAdapter, len(adapters))
+		for j, ad := range adapters {
+			scaled[j] = scaledFees{EXAdapter: ad, k: k}
+		}
+		runs[n] = startScenario(runCtx, k, opts.Size, scan.NewFeeAwareOpportunityUseCase(log, scaled, detectorOpts))
+	}
+
+	report := Report{Events: make(map[recorder.Kind]int)}
+	err := replay(runCtx, src, opts.Speed, func(ev recorder.Event) error {
+		report.Events[ev.Kind]++
+		if report.From.IsZero() || ev.Time.Before(report.From) {
+			report.From = ev.Time
+		}
+		if ev.Time.After(report.To) {
+			report.To = ev.Time
+		}
+		if ev.Kind != recorder.KindQuote || ev.Quote == nil {
+			return nil
+		}
+		for _, r := range runs {
+			select {
+			case <-runCtx.Done():
+				return runCtx.Err()
+			case r.in <- *ev.Quote:
+			}
+		}
+		return nil
+	})
+
+	for _, r := range runs {
+		close(r.in)
+	}
+	for _, r := range runs {
+		if werr := r.wait(); werr != nil && err == nil {
+			err = werr
+		}
+		report.Scenarios = append(report.Scenarios, r.result())
+	}
+	return report, err
+}
+
+// replay читает события и вызывает handle, выдерживая паузы между ними с учётом speed.
+func replay(ctx context.Context, src EventSource, speed float64, handle func(recorder.Event) error) error {
+	var last time.Time
+	for {
+		ev, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if speed > 0 && !last.IsZero() {
+			if gap := ev.Time.Sub(last); gap > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Duration(float64(gap) / speed)):
+				}
+			}
+		}
+		if ev.Time.After(last) {
+			last = ev.Time
+		}
+
+		if err := handle(ev); err != nil {
+			return err
+		}
+	}
+}
+
+// scenarioRun — детектор одного сценария и сборщик его возможностей.
+type scenarioRun struct {
+	in   chan entity.ExecutableQuote
+	size float64
+	done chan struct{}
+	err  error
+
+	mu       sync.Mutex
+	scenario Scenario
+	pairs    map[string]*PairStats
+}
+
+func startScenario(ctx context.Context, k, size float64, detector scan.ArbOpportunityUseCase) *scenarioRun {
+	r := &scenarioRun{
+		in:       make(chan entity.ExecutableQuote),
+		size:     size,
+		done:     make(chan struct{}),
+		scenario: Scenario{FeeMultiplier: k},
+		pairs:    make(map[string]*PairStats),
+	}
+	out := make(chan entity.ArbOpportunity)
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for opp := range out {
+			r.add(opp)
+		}
+	}()
+	go func() {
+		defer close(r.done)
+		r.err = detector.Detect(ctx, r.in, out)
+		close(out)
+		<-collected
+	}()
+	return r
+}
+
+func (r *scenarioRun) add(opp entity.ArbOpportunity) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := opp.Pair + "|" + opp.BuyOn + "|" + opp.SellOn
+	st, ok := r.pairs[key]
+	if !ok {
+		st = &PairStats{Pair: opp.Pair, BuyOn: opp.BuyOn, SellOn: opp.SellOn}
+		r.pairs[key] = st
+	}
+	pnl := opp.NetPnl * r.size
+	st.Opportunities++
+	st.Pnl += pnl
+	st.MaxSpreadPct = max(st.MaxSpreadPct, opp.SpreadPct)
+	r.scenario.Opportunities++
+	r.scenario.Pnl += pnl
+}
+
+func (r *scenarioRun) wait() error {
+	<-r.done
+	if errors.Is(r.err, context.Canceled) {
+		return nil
+	}
+	return r.err
+}
+
+func (r *scenarioRun) result() Scenario {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.scenario
+	for _, st := range r.pairs {
+		s.Pairs = append(s.Pairs, *st)
+	}
+	sort.Slice(s.Pairs, func(a, b int) bool { return s.Pairs[a].Pnl > s.Pairs[b].Pnl })
+	return s
+}
+
+// scaledFees масштабирует комиссии адаптера для анализа чувствительности.
+type scaledFees struct {
+	i.EXAdapter
+	k float64
+}
+
+func (s scaledFees) TradingFee(pair string) (maker, taker float64) {
+	maker, taker = s.EXAdapter.TradingFee(pair)
+	return maker * s.k, taker * s.k
+}
@@ -0,0 +1,93 @@
+package backtest
+
+import (
+	"context"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/logger"
+	"github.com/dimryb/cross-arb/internal/recorder"
+	"github.com/dimryb/cross-arb/internal/usecase/scan"
+)
+
+type feeAdapter struct {
+	name  string
+	taker float64
+}
+
+func (f *feeAdapter) Name() string                         { return f.name }
+func (f *feeAdapter) TradingFee(string) (float64, float64) { return f.taker, f.taker }
+func (f *feeAdapter) Close() error                         { return nil }
+
+type sliceSource []recorder.Event
+
+func (s *sliceSource) Next() (recorder.Event, error) {
+	if len(*s) == 0 {
+		return recorder.Event{}, io.EOF
+	}
+	ev := (*s)[0]
+	*s = (*s)[1:]
+	return ev, nil
+}
+
+func quote(exchange string, bid, ask float64, ts time.Time) recorder.Event {
+	return recorder.Event{Kind: recorder.KindQuote, Time: ts, Quote: &entity.ExecutableQuote{
+		Exchange: exchange, Pair: "SOL/USDT", Bid: bid, Ask: ask, Timestamp: ts,
+	}}
+}
+
+func TestRun_FeeSensitivity(t *testing.T) {
+	// История годичной давности: детектор не должен сравнивать её с текущим временем.
+	t0 := time.Now().Add(-365 * 24 * time.Hour)
+	src := sliceSource{
+		{Kind: recorder.KindTicker, Time: t0, Ticker: &entity.TickerData{Symbol: "SOLUSDT"}},
+		quote("mexc", 99.9, 100, t0),
+		quote("jupiter", 100.5, 100.6, t0.Add(time.Second)),    // 100.5 - 100*1.002 = 0.3
+		quote("jupiter", 100.15, 100.2, t0.Add(2*time.Second)), // 100.15 - 100.2 = -0.05 при 1x
+		quote("jupiter", 101, 101.2, t0.Add(time.Minute)),      // котировка mexc устарела
+	}
+
+	report, err := Run(context.Background(), logger.New("error"), &src,
+		[]i.EXAdapter{&feeAdapter{name: "mexc", taker: 0.002}, &feeAdapter{name: "jupiter"}},
+		Options{Size: 2, FeeMultipliers: []float64{0, 1}, Detector: scan.OpportunityOptions{MaxQuoteAge: 5 * time.Second}},
+	)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if report.Events[recorder.KindQuote] != 4 || report.Events[recorder.KindTicker] != 1 || !report.To.Equal(t0.Add(time.Minute)) {
+		t.Errorf("events = %v, span %v..%v", report.Events, report.From, report.To)
+	}
+	if len(report.Scenarios) != 2 {
+		t.Fatalf("scenarios = %+v", report.Scenarios)
+	}
+
+	noFees, actual := report.Scenarios[0], report.Scenarios[1]
+	if noFees.Opportunities != 2 || math.Abs(noFees.Pnl-2*(0.5+0.15)) > 1e-9 {
+		t.Errorf("zero fees = %+v", noFees)
+	}
+	if actual.Opportunities != 1 || math.Abs(actual.Pnl-0.6) > 1e-9 {
+		t.Errorf("actual fees = %+v", actual)
+	}
+	if len(actual.Pairs) != 1 || actual.Pairs[0].BuyOn != "mexc" || actual.Pairs[0].SellOn != "jupiter" {
+		t.Errorf("pairs = %+v", actual.Pairs)
+	}
+}
+
+func TestRun_Speed(t *testing.T) {
+	t0 := time.Now()
+	src := sliceSource{quote("mexc", 99, 100, t0), quote("jupiter", 99, 100, t0.Add(time.Second))}
+
+	started := time.Now()
+	_, err := Run(context.Background(), logger.New("error"), &src, nil, Options{Size: 1, Speed: 10})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed < 100*time.Millisecond {
+		t.Errorf("replay at 10x took %v, want at least 100ms", elapsed)
+	}
+}
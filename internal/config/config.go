@@ -11,14 +11,44 @@ import (
 const (
 	MexcExchange = "mexc"
 	JupExchange  = "jupiter"
+	// BinanceExchange — Binance или биржа с совместимым REST API (адрес — baseUrl).
+	BinanceExchange = "binance"
+	// RaydiumExchange — котировки напрямую из пулов Raydium AMM v4 (адрес пула — pool).
+	RaydiumExchange = "raydium"
 )
 
 type (
 	CrossArbConfig struct {
 		Log       Log                 `yaml:"log"`
 		Exchanges map[string]Exchange `yaml:"exchanges"`
-		Symbols   []string            `yaml:"symbols"`
 		Scanner   ScannerConfig       `yaml:"scanner"`
+		Execution ExecutionConfig     `yaml:"execution"`
+		Journal   JournalConfig       `yaml:"journal"`
+		Recorder  RecorderConfig      `yaml:"recorder"`
+		HTTP      HTTPConfig          `yaml:"http"`
+	}
+
+	// HTTPConfig — HTTP API: тикеры, состояние риска, журнал и аварийная остановка.
+	HTTPConfig struct {
+		Addr string `yaml:"addr"` // Пусто — 127.0.0.1:8080
+		// Token — токен (заголовок Authorization: Bearer) для POST /risk/*; обязателен,
+		// если сервер слушает не только loopback.
+		Token string `yaml:"token" env:"HTTP_TOKEN"`
+		// AllowResume разрешает снимать аварийную остановку через POST /risk/resume.
+		AllowResume bool `yaml:"allowResume"`
+	}
+
+	// RecorderConfig — запись тикеров, котировок и стаканов для воспроизведения в cmd/backtest.
+	RecorderConfig struct {
+		Enabled bool   `yaml:"enabled"`
+		Dir     string `yaml:"dir"`
+		Rotate  string `yaml:"rotate"` // Длительность одного файла
+	}
+
+	// JournalConfig — журнал возможностей и исполнений во встроенной базе.
+	JournalConfig struct {
+		Enabled bool   `yaml:"enabled"`
+		Path    string `yaml:"path"`
 	}
 
 	Log struct {
@@ -32,17 +62,43 @@ type (
 		SecretKey         string                `yaml:"secretKey" env:"SECRET_KEY"`
 		BaseURL           string                `yaml:"baseUrl"`
 		BaseURLAdapter    string                `yaml:"baseUrlAdapter"`
+		WSURL             string                `yaml:"wsUrl"`
 		Timeout           time.Duration         `yaml:"timeout" env:"TIMEOUT"`
 		Enabled           bool                  `yaml:"enabled"`
 		OrderLimit        int                   `yaml:"orderLimit"`
 		MaxPriceDiff      float64               `yaml:"maxPriceDiff"`
 		MinQtyImprovement float64               `yaml:"minQtyImprovement"`
+		SlippageBps       int                   `yaml:"slippageBps"`
+		MaxPriceImpactPct float64               `yaml:"maxPriceImpactPct"`
+		RPCURL            string                `yaml:"rpcUrl"`
+		PrivateKey        string                `yaml:"privateKey" env:"SOLANA_PRIVATE_KEY"`
 		Pairs             map[string]PairConfig `yaml:"pairs"`
+		FiltersRefresh    string                `yaml:"filtersRefresh"` // CEX: период обновления exchangeInfo
+		Tokens            TokensConfig          `yaml:"tokens"`         // jupiter: список токенов
 	}
 
+	// TokensConfig — список токенов Jupiter: источник, кеш на диске и ручные записи.
+	TokensConfig struct {
+		ListURL   string        `yaml:"listUrl"`   // Пусто — список Jupiter по умолчанию
+		Cache     string        `yaml:"cache"`     // Файл кеша на случай недоступности списка; пусто — без кеша
+		Refresh   string        `yaml:"refresh"`   // Период обновления списка
+		Overrides []TokenConfig `yaml:"overrides"` // Токены, которых нет в списке или чьи записи нужно заменить
+	}
+
+	// TokenConfig — токен Solana, заданный вручную.
+	TokenConfig struct {
+		Symbol   string `yaml:"symbol"`
+		Mint     string `yaml:"mint"`
+		Decimals uint8  `yaml:"decimals"`
+	}
+
+	// PairConfig — пара на площадке: тикеры активов (CEX) или mint-адреса (DEX).
 	PairConfig struct {
-		Base  string `yaml:"base"`
-		Quote string `yaml:"quote"`
+		Base          string `yaml:"base"`
+		Quote         string `yaml:"quote"`
+		BaseDecimals  uint8  `yaml:"baseDecimals"`  // DEX: знаков у BASE; 0 — из списка токенов
+		QuoteDecimals uint8  `yaml:"quoteDecimals"` // DEX: знаков у QUOTE; 0 — из списка токенов
+		Pool          string `yaml:"pool"`          // Адрес пула AMM (raydium)
 	}
 
 	ScannerBuffers struct {
@@ -53,9 +109,82 @@ type (
 
 	ScannerConfig struct {
 		Interval         string         `yaml:"interval"`
+		Concurrency      int            `yaml:"concurrency"`
+		MinSpreadPct     float64        `yaml:"minSpreadPct"`
+		MaxQuoteAge      string         `yaml:"maxQuoteAge"`
 		Pairs            []string       `yaml:"pairs"`
 		LogOpportunities bool           `yaml:"logOpportunities"`
 		Buffers          ScannerBuffers `yaml:"buffers"`
+		Sizing           SizingConfig   `yaml:"sizing"`
+		Cycles           CyclesConfig   `yaml:"cycles"`
+	}
+
+	// CyclesConfig — поиск многоходовых циклов обменов (например, USDT→SOL→USDC→USDT).
+	CyclesConfig struct {
+		Enabled      bool     `yaml:"enabled"`
+		MinProfitPct float64  `yaml:"minProfitPct"`
+		StartAssets  []string `yaml:"startAssets"` // С каких активов начинать цикл в отчёте
+	}
+
+	// SizingConfig — ограничения подбора объёма сделки (в BASE и QUOTE).
+	SizingConfig struct {
+		MinSize    float64 `yaml:"minSize"`
+		MaxSize    float64 `yaml:"maxSize"`
+		MaxQuote   float64 `yaml:"maxQuote"`
+		Iterations int     `yaml:"iterations"`
+	}
+
+	// ExecutionConfig — параметры исполнения найденных возможностей.
+	ExecutionConfig struct {
+		Enabled           bool            `yaml:"enabled"`
+		Mode              string          `yaml:"mode"`
+		LegTimeout        string          `yaml:"legTimeout"`
+		MaxOpportunityAge string          `yaml:"maxOpportunityAge"`
+		Hedge             bool            `yaml:"hedge"`
+		MaxHedgeLoss      float64         `yaml:"maxHedgeLoss"`
+		Paper             PaperConfig     `yaml:"paper"`
+		Balances          BalancesConfig  `yaml:"balances"`
+		Rebalance         RebalanceConfig `yaml:"rebalance"`
+		Risk              RiskConfig      `yaml:"risk"`
+	}
+
+	// RiskConfig — лимиты риска между детектором и исполнением; 0 — без ограничения.
+	RiskConfig struct {
+		MaxTradeNotional   float64            `yaml:"maxTradeNotional"`   // QUOTE на сделку
+		MaxExposure        map[string]float64 `yaml:"maxExposure"`        // Открытая позиция по активу
+		MaxDailyLoss       float64            `yaml:"maxDailyLoss"`       // Реализованный убыток за сутки (UTC), QUOTE
+		MaxTradesPerMinute int                `yaml:"maxTradesPerMinute"` // Сделок за скользящую минуту
+	}
+
+	// RebalanceConfig — перераспределение остатков между MEXC и кошельком Solana.
+	RebalanceConfig struct {
+		Enabled        bool                            `yaml:"enabled"`
+		Execute        bool                            `yaml:"execute"` // false — только предлагать переводы в лог
+		Interval       string                          `yaml:"interval"`
+		PendingTimeout string                          `yaml:"pendingTimeout"`
+		Threshold      float64                         `yaml:"threshold"` // Допустимое отклонение доли на MEXC от целевой
+		Assets         map[string]RebalanceAssetConfig `yaml:"assets"`
+	}
+
+	// RebalanceAssetConfig — целевая доля актива на MEXC и параметры его перевода.
+	RebalanceAssetConfig struct {
+		Target    float64 `yaml:"target"`
+		MinAmount float64 `yaml:"minAmount"`
+		Network   string  `yaml:"network"`
+	}
+
+	// BalancesConfig — отслеживание остатков на биржах, ограничивающих объём сделок.
+	BalancesConfig struct {
+		Interval   string  `yaml:"interval"`
+		Headroom   float64 `yaml:"headroom"`   // Доля остатка, не используемая для сделок
+		SolReserve float64 `yaml:"solReserve"` // SOL, оставляемый на комиссии сети
+	}
+
+	// PaperConfig — бумажная торговля: сделки симулируются по живым котировкам
+	// на виртуальных балансах (биржа → актив → количество), ключи не нужны.
+	PaperConfig struct {
+		Enabled  bool                          `yaml:"enabled"`
+		Balances map[string]map[string]float64 `yaml:"balances"`
 	}
 )
 
@@ -1,28 +1,48 @@
 package http
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/repository"
+	"github.com/dimryb/cross-arb/internal/risk"
 )
 
+// defaultJournalLimit — число записей журнала в ответе, если limit не задан.
+const defaultJournalLimit = 500
+
 type Server struct {
-	store i.TickerStore
+	store   i.TickerStore
+	risk    *risk.Manager
+	journal *repository.Journal
+	cfg     ServerConfig
 }
 
-func NewHTTPServer(store i.TickerStore) *Server {
-	return &Server{store: store}
+// ServerConfig — доступ к управляющим эндпоинтам /risk/*.
+type ServerConfig struct {
+	// Token — если задан, POST /risk/* требуют заголовок Authorization: Bearer <Token>.
+	Token string
+	// AllowResume регистрирует POST /risk/resume; без него остановку снимает только перезапуск.
+	AllowResume bool
 }
 
-func (s *Server) Run(addr string) error {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/tickers", s.handleTickers)
+// NewHTTPServer создаёт HTTP-сервер. riskMgr и journal необязательны: без них
+// эндпоинты /risk и /journal не регистрируются.
+func NewHTTPServer(store i.TickerStore, riskMgr *risk.Manager, journal *repository.Journal, cfg ServerConfig) *Server {
+	return &Server{store: store, risk: riskMgr, journal: journal, cfg: cfg}
+}
 
+func (s *Server) Run(addr string) error {
 	server := &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      s.Handler(),
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
@@ -31,11 +51,124 @@ func (s *Server) Run(addr string) error {
 	return server.ListenAndServe()
 }
 
+// Handler возвращает маршрутизатор сервера.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tickers", s.handleTickers)
+	if s.risk != nil {
+		mux.HandleFunc("GET /risk", s.handleRiskState)
+		mux.HandleFunc("POST /risk/kill", s.authorized(s.handleRiskKill))
+		if s.cfg.AllowResume {
+			mux.HandleFunc("POST /risk/resume", s.authorized(s.handleRiskResume))
+		}
+	}
+	if s.journal != nil {
+		mux.HandleFunc("GET /journal/opportunities", s.handleJournalOpportunities)
+		mux.HandleFunc("GET /journal/executions", s.handleJournalExecutions)
+		mux.HandleFunc("GET /journal/fills", s.handleJournalFills)
+	}
+	return mux
+}
+
+// authorized пропускает запрос к next, только если он несёт токен из ServerConfig.
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	if s.cfg.Token == "" {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
 func (s *Server) handleTickers(w http.ResponseWriter, _ *http.Request) {
-	tickers := s.store.GetAll()
+	writeJSON(w, s.store.GetAll())
+}
+
+func (s *Server) handleRiskState(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, s.risk.State())
+}
+
+// handleRiskKill включает аварийную остановку; причина — параметр reason.
+func (s *Server) handleRiskKill(w http.ResponseWriter, r *http.Request) {
+	reason := r.URL.Query().Get("reason")
+	if reason == "" {
+		reason = "manual"
+	}
+	s.risk.Kill(reason)
+	writeJSON(w, s.risk.State())
+}
+
+func (s *Server) handleRiskResume(w http.ResponseWriter, _ *http.Request) {
+	s.risk.Resume()
+	writeJSON(w, s.risk.State())
+}
+
+func (s *Server) handleJournalOpportunities(w http.ResponseWriter, r *http.Request) {
+	serveJournal(w, r, s.journal.Opportunities)
+}
+
+func (s *Server) handleJournalExecutions(w http.ResponseWriter, r *http.Request) {
+	serveJournal(w, r, s.journal.Executions)
+}
+
+func (s *Server) handleJournalFills(w http.ResponseWriter, r *http.Request) {
+	serveJournal(w, r, s.journal.Fills)
+}
+
+func serveJournal[T any](w http.ResponseWriter, r *http.Request, query func(repository.Query) ([]T, error)) {
+	q, err := parseJournalQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	records, err := query(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if records == nil {
+		records = []T{}
+	}
+	writeJSON(w, records)
+}
+
+// parseJournalQuery разбирает параметры pair, venue, orderId, from, to (RFC 3339) и limit.
+func parseJournalQuery(v url.Values) (repository.Query, error) {
+	q := repository.Query{
+		Pair:    v.Get("pair"),
+		Venue:   v.Get("venue"),
+		OrderID: v.Get("orderId"),
+		Limit:   defaultJournalLimit,
+	}
+	for name, dst := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		raw := v.Get(name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return q, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*dst = t
+	}
+	if raw := v.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return q, fmt.Errorf("invalid limit %q", raw)
+		}
+		q.Limit = n
+	}
+	return q, nil
+}
 
+func writeJSON(w http.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(tickers); err != nil {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 	}
 }
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dimryb/cross-arb/internal/logger"
+	"github.com/dimryb/cross-arb/internal/risk"
+	"github.com/dimryb/cross-arb/internal/storage"
+)
+
+func newTestServer(t *testing.T, cfg ServerConfig) (http.Handler, *risk.Manager) {
+	t.Helper()
+	riskMgr, err := risk.NewManager(logger.New("error"), risk.Limits{})
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	return NewHTTPServer(storage.NewTickerStore(), riskMgr, nil, cfg).Handler(), riskMgr
+}
+
+func post(h http.Handler, path, token string) int {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestServer_RiskControlRequiresToken(t *testing.T) {
+	h, riskMgr := newTestServer(t, ServerConfig{Token: "secret", AllowResume: true})
+
+	for _, token := range []string{"", "wrong"} {
+		if code := post(h, "/risk/kill", token); code != http.StatusUnauthorized {
+			t.Errorf("kill with token %q: status = %d, want 401", token, code)
+		}
+	}
+	if riskMgr.State().Killed {
+		t.Fatal("unauthorized request must not engage kill switch")
+	}
+
+	if code := post(h, "/risk/kill", "secret"); code != http.StatusOK || !riskMgr.State().Killed {
+		t.Fatalf("kill: status = %d, killed = %v", code, riskMgr.State().Killed)
+	}
+	if code := post(h, "/risk/resume", "wrong"); code != http.StatusUnauthorized || !riskMgr.State().Killed {
+		t.Errorf("resume with wrong token: status = %d, killed = %v", code, riskMgr.State().Killed)
+	}
+	if code := post(h, "/risk/resume", "secret"); code != http.StatusOK || riskMgr.State().Killed {
+		t.Errorf("resume: status = %d, killed = %v", code, riskMgr.State().Killed)
+	}
+}
+
+func TestServer_ResumeDisabledByDefault(t *testing.T) {
+	h, riskMgr := newTestServer(t, ServerConfig{})
+	riskMgr.Kill("test")
+
+	if code := post(h, "/risk/resume", ""); code == http.StatusOK || !riskMgr.State().Killed {
+		t.Errorf("resume: status = %d, killed = %v, want rejected", code, riskMgr.State().Killed)
+	}
+}
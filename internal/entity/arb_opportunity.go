@@ -5,14 +5,18 @@ import "time"
 // ArbOpportunity описывает арбитражную возможность между биржами.
 // Содержит места покупки/продажи, соответствующие цены, валовую/чистую прибыль
 // и относительный спред. Значения NetPnl/SpreadPct предполагают учёт комиссий.
+// Цены и PnL указаны на 1 BASE; при известном объёме сделки Size они соответствуют
+// исполнению именно этого объёма, а ExpectedPnl — ожидаемая прибыль по всей сделке.
 type ArbOpportunity struct {
-	Pair       string
-	BuyOn      string
-	BuyPrice   float64
-	SellOn     string
-	SellPrice  float64
-	GrossPnl   float64 // Разница в цене без учёта комиссий
-	NetPnl     float64 // Прибыль после вычета комиссий
-	SpreadPct  float64 // NetPnl / BuyPrice, в процентах
-	DetectedAt time.Time
+	Pair        string
+	BuyOn       string
+	BuyPrice    float64
+	SellOn      string
+	SellPrice   float64
+	GrossPnl    float64 // Разница в цене без учёта комиссий
+	NetPnl      float64 // Прибыль после вычета комиссий
+	SpreadPct   float64 // NetPnl / BuyPrice, в процентах
+	Size        float64 // Объём сделки в BASE; 0 — объём не подбирался
+	ExpectedPnl float64 // NetPnl * Size, в QUOTE
+	DetectedAt  time.Time
 }
@@ -0,0 +1,13 @@
+package entity
+
+// Balances — доступные остатки по биржам и активам: биржа → актив → количество.
+type Balances map[string]map[string]float64
+
+// Get возвращает остаток актива на бирже и признак того, что биржа учитывается.
+func (b Balances) Get(venue, asset string) (amount float64, tracked bool) {
+	assets, ok := b[venue]
+	if !ok {
+		return 0, false
+	}
+	return assets[asset], true
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// CycleLeg — один ход цикла: обмен актива From на актив To по паре Pair на бирже Exchange.
+type CycleLeg struct {
+	Exchange string
+	Pair     string
+	Side     Side // BUY — From является QUOTE пары, SELL — From является BASE
+	From     string
+	To       string
+	Price    float64 // Цена исполнения в QUOTE за 1 BASE
+	Fee      float64 // Taker-комиссия биржи
+	Rate     float64 // Получено To за 1 From с учётом комиссии
+}
+
+// CycleOpportunity описывает многоходовую арбитражную возможность: цепочку обменов,
+// которая начинается и заканчивается активом Asset, например USDT→SOL→USDC→USDT.
+// Return — произведение Rate всех ходов, т.е. сколько Asset возвращается на 1 Asset;
+// ProfitPct = (Return - 1) * 100. Подразумевается, что на каждой бирже заранее есть
+// остаток актива, отдаваемого на соответствующем ходу (как и у ArbOpportunity).
+type CycleOpportunity struct {
+	Asset      string
+	Legs       []CycleLeg
+	Return     float64
+	ProfitPct  float64
+	DetectedAt time.Time
+}
+
+// Path возвращает цикл в виде "USDT →jupiter→ SOL →mexc→ USDC →jupiter→ USDT".
+func (c CycleOpportunity) Path() string {
+	var b strings.Builder
+	b.WriteString(c.Asset)
+	for _, leg := range c.Legs {
+		b.WriteString(" →" + leg.Exchange + "→ " + leg.To)
+	}
+	return b.String()
+}
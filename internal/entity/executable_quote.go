@@ -3,7 +3,8 @@ package entity
 import "time"
 
 // ExecutableQuote представляет исполнимую котировку для пары.
-// Значения Bid/Ask выражены в QUOTE за 1 BASE. Для DEX котировка рассчитывается
+// Значения Bid/Ask выражены в QUOTE за 1 BASE: Bid — цена продажи BASE (выручка),
+// Ask — цена покупки (стоимость), как у стакана CEX. Для DEX котировка рассчитывается
 // под заданный объём, для CEX может агрегировать уровни стакана для покрытия объёма.
 type ExecutableQuote struct {
 	Exchange  string
@@ -0,0 +1,76 @@
+package entity
+
+import "time"
+
+// ExecutionStatus — итог исполнения арбитражной сделки.
+type ExecutionStatus string
+
+const (
+	// ExecutionFilled — обе ноги исполнены в полном объёме.
+	ExecutionFilled ExecutionStatus = "filled"
+	// ExecutionPartial — обе ноги исполнены, но хотя бы одна не полностью или объёмы не совпали.
+	ExecutionPartial ExecutionStatus = "partial"
+	// ExecutionOneLegFailed — одна нога исполнена (полностью или частично), другая — нет.
+	ExecutionOneLegFailed ExecutionStatus = "one_leg_failed"
+	// ExecutionFailed — не исполнена ни одна нога.
+	ExecutionFailed ExecutionStatus = "failed"
+	// ExecutionHedged — объёмы ног не совпали, открытая позиция закрыта хеджирующим ордером.
+	ExecutionHedged ExecutionStatus = "hedged"
+	// ExecutionHedgeFailed — объёмы ног не совпали, хедж не исполнен или исполнен не полностью
+	// (в том числе из-за ограничения убытка); позиция остаётся открытой.
+	ExecutionHedgeFailed ExecutionStatus = "hedge_failed"
+	// ExecutionUncertain — исход хотя бы одной ноги неизвестен (например, транзакция
+	// отправлена, но не подтверждена); позиция не хеджируется и требует ручной проверки.
+	ExecutionUncertain ExecutionStatus = "uncertain"
+)
+
+// Side — направление ордера относительно BASE.
+type Side string
+
+const (
+	SideBuy  Side = "BUY"
+	SideSell Side = "SELL"
+)
+
+// ExecutionLeg — исполнение одной ноги сделки на конкретной бирже.
+type ExecutionLeg struct {
+	Exchange  string
+	Side      Side
+	OrderID   string  // Идентификатор ордера или сигнатура транзакции
+	Requested float64 // Запрошенный объём в BASE
+	Filled    float64 // Исполненный объём в BASE
+	Quote     float64 // Потрачено (покупка) или получено (продажа) QUOTE
+	Fee       float64 // Комиссия в QUOTE
+	// Estimated — Filled и Quote оценены по котировке: фактическое исполнение узнать не удалось.
+	Estimated bool
+	// Uncertain — исход ордера неизвестен: Filled и Quote не достоверны.
+	Uncertain bool
+	Error     error
+}
+
+// AvgPrice возвращает среднюю цену исполнения в QUOTE за 1 BASE.
+func (l ExecutionLeg) AvgPrice() float64 {
+	if l.Filled <= 0 {
+		return 0
+	}
+	return l.Quote / l.Filled
+}
+
+// Execution — запись об исполнении арбитражной возможности.
+// RealizedPnl считается по совпавшему объёму обеих ног за вычетом комиссий;
+// Unhedged — открытая позиция в BASE (Buy.Filled - Sell.Filled), которая в PnL не входит.
+//
+// Hedge заполняется, если объёмы ног не совпали и движок закрывал разницу: хедж
+// учитывается в PnL и Unhedged вместе с ногой той же стороны.
+type Execution struct {
+	ID          string
+	Opportunity ArbOpportunity
+	Buy         ExecutionLeg
+	Sell        ExecutionLeg
+	Hedge       *ExecutionLeg
+	Status      ExecutionStatus
+	RealizedPnl float64
+	Unhedged    float64
+	StartedAt   time.Time
+	FinishedAt  time.Time
+}
@@ -1,14 +1,19 @@
 package entity
 
+import "time"
+
 type Order struct {
 	Price    float64
 	Quantity float64
 }
 
+// OrderBookResult — результат загрузки стакана: биржа, символ (пара), данные и возможная ошибка.
 type OrderBookResult struct {
-	Symbol string
-	Data   OrderBook
-	Error  error
+	Exchange  string
+	Symbol    string
+	Data      OrderBook
+	Error     error
+	Timestamp time.Time
 }
 
 type OrderBook struct {
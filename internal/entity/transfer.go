@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// TransferStatus — состояние перевода между биржами.
+type TransferStatus string
+
+const (
+	// TransferProposed — перевод предложен, но не отправлен (режим без исполнения).
+	TransferProposed TransferStatus = "proposed"
+	// TransferPending — перевод отправлен и ещё не зачислен получателю.
+	TransferPending TransferStatus = "pending"
+	// TransferCredited — средства зачислены на бирже назначения.
+	TransferCredited TransferStatus = "credited"
+	// TransferFailed — перевод отклонён, отменён или не зачислен за отведённое время.
+	TransferFailed TransferStatus = "failed"
+)
+
+// Transfer — перевод актива между биржами для ребалансировки остатков.
+type Transfer struct {
+	ID        string // Идентификатор вывода на бирже-источнике или сигнатура транзакции
+	Asset     string
+	From      string
+	To        string
+	Amount    float64
+	TxID      string // Хэш транзакции в сети, по которому ищется зачисление
+	Status    TransferStatus
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
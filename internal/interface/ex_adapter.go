@@ -28,7 +28,8 @@ type EXAdapter interface {
 // Объем-зависимое квотирование: возвращает эффективные котировки для заданного объёма baseAmount.
 type DEXAdapter interface {
 	EXAdapter
-	// Quote возвращает эффективные котировки bid/ask в QUOTE за BASE для указанного объёма baseAmount (в BASE).
+	// Quote возвращает эффективные котировки bid/ask в QUOTE за BASE для указанного объёма baseAmount (в BASE):
+	// bid — выручка за продажу baseAmount BASE, ask — стоимость его покупки.
 	// Реализация должна учесть маршрутизацию/слиппедж. Для малых объёмов результат может совпадать с top-of-book.
 	Quote(ctx context.Context, pair string, baseAmount float64) (bid, ask float64, err error)
 }
@@ -0,0 +1,79 @@
+package market
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+)
+
+var (
+	// ErrBelowMinQty — количество ордера меньше минимального для символа.
+	ErrBelowMinQty = errors.New("quantity below minimum")
+	// ErrBelowMinNotional — сумма ордера меньше минимальной для символа.
+	ErrBelowMinNotional = errors.New("notional below minimum")
+)
+
+// Filters — торговые ограничения инструмента на CEX (из ExchangeInfo); 0 — без ограничения.
+type Filters struct {
+	TickSize    float64 // Шаг цены, QUOTE
+	StepSize    float64 // Шаг количества, BASE
+	MinQty      float64 // Минимальное количество, BASE
+	MinNotional float64 // Минимальная сумма ордера, QUOTE
+}
+
+// FloorQty округляет количество вниз до шага StepSize.
+func (f Filters) FloorQty(qty float64) float64 {
+	return floorTo(qty, f.StepSize)
+}
+
+// RoundLimit округляет предельную цену ордера до шага TickSize в безопасную сторону:
+// цену покупки (максимум) — вниз, цену продажи (минимум) — вверх.
+func (f Filters) RoundLimit(side entity.Side, price float64) float64 {
+	if side == entity.SideSell {
+		return ceilTo(price, f.TickSize)
+	}
+	return floorTo(price, f.TickSize)
+}
+
+// Check проверяет количество qty при цене price на MinQty и MinNotional.
+func (f Filters) Check(price, qty float64) error {
+	if qty <= 0 || qty < f.MinQty {
+		return fmt.Errorf("%w: %v < %v", ErrBelowMinQty, qty, f.MinQty)
+	}
+	if notional := price * qty; notional < f.MinNotional {
+		return fmt.Errorf("%w: %v < %v", ErrBelowMinNotional, notional, f.MinNotional)
+	}
+	return nil
+}
+
+// stepEpsilon — допуск на ошибку представления float: 2.9999999999 при шаге 1 даёт 3.
+const stepEpsilon = 1e-9
+
+func floorTo(v, step float64) float64 {
+	if step <= 0 {
+		return v
+	}
+	return roundToStepDecimals(math.Floor(v/step+stepEpsilon)*step, step)
+}
+
+func ceilTo(v, step float64) float64 {
+	if step <= 0 {
+		return v
+	}
+	return roundToStepDecimals(math.Ceil(v/step-stepEpsilon)*step, step)
+}
+
+// roundToStepDecimals убирает хвост float после умножения на шаг (0.30000000000000004 → 0.3),
+// чтобы значение уходило на биржу с допустимым числом знаков.
+func roundToStepDecimals(v, step float64) float64 {
+	decimals := 0
+	if _, frac, ok := strings.Cut(strconv.FormatFloat(step, 'f', -1, 64), "."); ok {
+		decimals = len(frac)
+	}
+	scale := math.Pow10(decimals)
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,47 @@
+package market
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+)
+
+func TestFilters_Rounding(t *testing.T) {
+	f := Filters{TickSize: 0.01, StepSize: 0.001}
+	for _, tc := range []struct{ in, want float64 }{
+		{1.23456, 1.234},
+		{0.1 + 0.2, 0.3}, // 0.30000000000000004
+		{0.7, 0.7},       // 0.7/0.001 = 699.9999999999999
+		{0.0004, 0},
+	} {
+		if got := f.FloorQty(tc.in); got != tc.want {
+			t.Errorf("FloorQty(%v) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+	if got := f.RoundLimit(entity.SideBuy, 100.129); got != 100.12 {
+		t.Errorf("buy limit = %v, want 100.12", got)
+	}
+	if got := f.RoundLimit(entity.SideSell, 100.121); got != 100.13 {
+		t.Errorf("sell limit = %v, want 100.13", got)
+	}
+	if got := (Filters{StepSize: 0.25}).FloorQty(1.6); got != 1.5 {
+		t.Errorf("FloorQty with step 0.25 = %v, want 1.5", got)
+	}
+	if got := (Filters{}).FloorQty(1.23456); got != 1.23456 {
+		t.Errorf("FloorQty without step = %v", got)
+	}
+}
+
+func TestFilters_Check(t *testing.T) {
+	f := Filters{MinQty: 0.01, MinNotional: 5}
+	if err := f.Check(100, 0.05); err != nil {
+		t.Errorf("Check: %v", err)
+	}
+	if err := f.Check(100, 0.005); !errors.Is(err, ErrBelowMinQty) {
+		t.Errorf("err = %v, want ErrBelowMinQty", err)
+	}
+	if err := f.Check(100, 0.04); !errors.Is(err, ErrBelowMinNotional) {
+		t.Errorf("err = %v, want ErrBelowMinNotional", err)
+	}
+}
@@ -0,0 +1,40 @@
+// Package market — реестр торговых инструментов: канонические пары "BASE/QUOTE"
+// и их представление на каждой площадке (символ биржи, mint-адреса и decimals
+// токенов Solana, адрес пула, торговые фильтры CEX).
+//
+// Адаптеры переводят пару в формат площадки только через реестр: символ вида
+// "SOLUSDT" получается из зарегистрированного листинга, а не угадыванием по суффиксу.
+package market
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Instrument — канонический инструмент: базовый и котируемый активы.
+type Instrument struct {
+	Base  string
+	Quote string
+}
+
+// Pair возвращает каноническую запись пары, например "SOL/USDT".
+func (i Instrument) Pair() string { return i.Base + "/" + i.Quote }
+
+// String удовлетворяет fmt.Stringer.
+func (i Instrument) String() string { return i.Pair() }
+
+// ParsePair разбирает пару "SOL/USDT". Допускаются разделители "-" и "_" и любой
+// регистр: "sol-usdt" даёт тот же инструмент. Символы без разделителя ("SOLUSDT")
+// не разбираются — их переводит в пару только реестр.
+func ParsePair(pair string) (Instrument, error) {
+	s := strings.ToUpper(strings.TrimSpace(pair))
+	sep := strings.IndexAny(s, "/-_")
+	if sep < 0 {
+		return Instrument{}, fmt.Errorf("invalid pair %q: want BASE/QUOTE", pair)
+	}
+	inst := Instrument{Base: s[:sep], Quote: s[sep+1:]}
+	if inst.Base == "" || inst.Quote == "" || strings.ContainsAny(inst.Quote, "/-_") {
+		return Instrument{}, fmt.Errorf("invalid pair %q: want BASE/QUOTE", pair)
+	}
+	return inst, nil
+}
@@ -0,0 +1,169 @@
+package market
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Listing — инструмент на конкретной площадке.
+type Listing struct {
+	Venue      string
+	Instrument Instrument
+	// Symbol — символ площадки ("SOLUSDT"). При пустом значении — Base+Quote.
+	Symbol string
+	// BaseMint и QuoteMint — mint-адреса SPL-токенов (DEX на Solana).
+	BaseMint  string
+	QuoteMint string
+	// BaseDecimals и QuoteDecimals — знаков после запятой у токенов; 0 — неизвестно.
+	BaseDecimals  uint8
+	QuoteDecimals uint8
+	// Address — адрес пула или рынка на площадке (например, пул Raydium).
+	Address string
+	Filters Filters
+}
+
+// Pair возвращает каноническую пару листинга.
+func (l Listing) Pair() string { return l.Instrument.Pair() }
+
+// Registry хранит листинги по площадкам. Безопасен для конкурентного использования.
+//
+// Методы чтения допускают nil-реестр: тогда ни один листинг не зарегистрирован и
+// Symbol строит символ по умолчанию (Base+Quote).
+type Registry struct {
+	mu       sync.RWMutex
+	listings map[string]map[string]*Listing // площадка → пара → листинг
+	symbols  map[string]map[string]string   // площадка → символ → пара
+}
+
+// NewRegistry создаёт пустой реестр.
+func NewRegistry() *Registry {
+	return &Registry{
+		listings: make(map[string]map[string]*Listing),
+		symbols:  make(map[string]map[string]string),
+	}
+}
+
+// Add регистрирует листинг. Повторная регистрация пары на площадке заменяет листинг;
+// символ не может принадлежать двум парам одной площадки.
+func (r *Registry) Add(l Listing) error {
+	if l.Venue == "" {
+		return fmt.Errorf("listing %s: empty venue", l.Pair())
+	}
+	if l.Instrument.Base == "" || l.Instrument.Quote == "" {
+		return fmt.Errorf("listing on %s: empty base or quote", l.Venue)
+	}
+	if l.Symbol == "" {
+		l.Symbol = l.Instrument.Base + l.Instrument.Quote
+	}
+	pair := l.Pair()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if other, ok := r.symbols[l.Venue][l.Symbol]; ok && other != pair {
+		return fmt.Errorf("symbol %s on %s already belongs to %s", l.Symbol, l.Venue, other)
+	}
+	if r.listings[l.Venue] == nil {
+		r.listings[l.Venue] = make(map[string]*Listing)
+		r.symbols[l.Venue] = make(map[string]string)
+	}
+	if prev, ok := r.listings[l.Venue][pair]; ok {
+		delete(r.symbols[l.Venue], prev.Symbol)
+	}
+	r.listings[l.Venue][pair] = &l
+	r.symbols[l.Venue][l.Symbol] = pair
+	return nil
+}
+
+// Listing возвращает листинг пары на площадке. pair принимается в любой записи,
+// допустимой для ParsePair.
+func (r *Registry) Listing(venue, pair string) (Listing, bool) {
+	if r == nil {
+		return Listing{}, false
+	}
+	inst, err := ParsePair(pair)
+	if err != nil {
+		return Listing{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	l, ok := r.listings[venue][inst.Pair()]
+	if !ok {
+		return Listing{}, false
+	}
+	return *l, true
+}
+
+// Lookup возвращает листинг по символу площадки ("SOLUSDT").
+func (r *Registry) Lookup(venue, symbol string) (Listing, bool) {
+	if r == nil {
+		return Listing{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pair, ok := r.symbols[venue][symbol]
+	if !ok {
+		return Listing{}, false
+	}
+	return *r.listings[venue][pair], true
+}
+
+// Symbol переводит пару в символ площадки. Для незарегистрированной пары символ
+// строится по умолчанию (Base+Quote: "sol-usdt" → "SOLUSDT"); строка, которая не
+// является парой, считается уже символом площадки и только приводится к верхнему регистру.
+func (r *Registry) Symbol(venue, pair string) string {
+	if l, ok := r.Listing(venue, pair); ok {
+		return l.Symbol
+	}
+	if inst, err := ParsePair(pair); err == nil {
+		return inst.Base + inst.Quote
+	}
+	return strings.ToUpper(strings.TrimSpace(pair))
+}
+
+// SymbolFunc возвращает Symbol, привязанный к площадке venue.
+func (r *Registry) SymbolFunc(venue string) func(pair string) string {
+	return func(pair string) string { return r.Symbol(venue, pair) }
+}
+
+// SetFilters обновляет торговые фильтры листинга с символом symbol.
+// Возвращает false, если символ на площадке не зарегистрирован.
+func (r *Registry) SetFilters(venue, symbol string, f Filters) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pair, ok := r.symbols[venue][symbol]
+	if !ok {
+		return false
+	}
+	r.listings[venue][pair].Filters = f
+	return true
+}
+
+// Filters возвращает торговые фильтры пары на площадке; ok == false, если пара не
+// зарегистрирована или фильтры ещё не загружены.
+func (r *Registry) Filters(venue, pair string) (Filters, bool) {
+	l, ok := r.Listing(venue, pair)
+	if !ok || l.Filters == (Filters{}) {
+		return Filters{}, false
+	}
+	return l.Filters, true
+}
+
+// Listings возвращает листинги площадки, упорядоченные по паре.
+func (r *Registry) Listings(venue string) []Listing {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Listing, 0, len(r.listings[venue]))
+	for _, l := range r.listings[venue] {
+		out = append(out, *l)
+	}
+	sort.Slice(out, func(x, y int) bool { return out[x].Pair() < out[y].Pair() })
+	return out
+}
@@ -0,0 +1,81 @@
+package market
+
+import (
+	"testing"
+)
+
+func TestParsePair(t *testing.T) {
+	for _, in := range []string{"SOL/USDT", "sol-usdt", " Sol_USDT "} {
+		inst, err := ParsePair(in)
+		if err != nil || inst != (Instrument{Base: "SOL", Quote: "USDT"}) {
+			t.Errorf("ParsePair(%q) = %+v, %v", in, inst, err)
+		}
+	}
+	for _, in := range []string{"SOLUSDT", "/USDT", "SOL/", "SOL/USDT/X", ""} {
+		if _, err := ParsePair(in); err == nil {
+			t.Errorf("ParsePair(%q): expected error", in)
+		}
+	}
+}
+
+func TestRegistry_Symbols(t *testing.T) {
+	reg := NewRegistry()
+	sol := Instrument{Base: "SOL", Quote: "USD"}
+	if err := reg.Add(Listing{Venue: "binance", Instrument: sol, Symbol: "SOLFDUSD"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Add(Listing{Venue: "mexc", Instrument: Instrument{Base: "SOL", Quote: "USDT"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct{ venue, pair, want string }{
+		{"binance", "sol/usd", "SOLFDUSD"},
+		{"mexc", "SOL/USDT", "SOLUSDT"}, // символ по умолчанию при регистрации
+		{"mexc", "ETH/USDT", "ETHUSDT"}, // не зарегистрирована
+		{"mexc", "solusdt", "SOLUSDT"},  // уже символ биржи
+	} {
+		if got := reg.Symbol(tc.venue, tc.pair); got != tc.want {
+			t.Errorf("Symbol(%s, %s) = %s, want %s", tc.venue, tc.pair, got, tc.want)
+		}
+	}
+
+	if l, ok := reg.Lookup("binance", "SOLFDUSD"); !ok || l.Instrument != sol {
+		t.Errorf("Lookup = %+v, %v", l, ok)
+	}
+	if _, ok := reg.Lookup("mexc", "SOLFDUSD"); ok {
+		t.Error("symbol must be scoped to its venue")
+	}
+
+	// Символ не может принадлежать двум парам; повторная регистрация пары его освобождает.
+	if err := reg.Add(Listing{Venue: "binance", Instrument: Instrument{Base: "SOL", Quote: "FDUSD"}, Symbol: "SOLFDUSD"}); err == nil {
+		t.Error("expected duplicate symbol error")
+	}
+	if err := reg.Add(Listing{Venue: "binance", Instrument: sol, Symbol: "SOLUSD"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reg.Lookup("binance", "SOLFDUSD"); ok {
+		t.Error("old symbol still registered")
+	}
+
+	var none *Registry
+	if got := none.Symbol("mexc", "SOL/USDT"); got != "SOLUSDT" {
+		t.Errorf("nil registry Symbol = %s", got)
+	}
+}
+
+func TestRegistry_SetFilters(t *testing.T) {
+	reg := NewRegistry()
+	if err := reg.Add(Listing{Venue: "mexc", Instrument: Instrument{Base: "SOL", Quote: "USDT"}}); err != nil {
+		t.Fatal(err)
+	}
+	f := Filters{TickSize: 0.01, StepSize: 0.001, MinNotional: 1}
+	if !reg.SetFilters("mexc", "SOLUSDT", f) {
+		t.Fatal("SetFilters: symbol not found")
+	}
+	if reg.SetFilters("mexc", "BTCUSDT", f) {
+		t.Error("SetFilters for unknown symbol must fail")
+	}
+	if l, _ := reg.Listing("mexc", "SOL/USDT"); l.Filters != f {
+		t.Errorf("filters = %+v, want %+v", l.Filters, f)
+	}
+}
@@ -0,0 +1,190 @@
+package orderbook
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+)
+
+// maxBuffered — сколько диффов держим, пока ждём снапшот.
+const maxBuffered = 4096
+
+var (
+	// ErrGap — пропуск в последовательности обновлений; стакан требует ресинхронизации.
+	ErrGap = errors.New("order book update gap")
+	// ErrNotSynced — стакан ещё не инициализирован снапшотом.
+	ErrNotSynced = errors.New("order book not synced")
+)
+
+// Snapshot — полный снимок стакана (например, ответ REST /depth) с номером последнего обновления.
+type Snapshot struct {
+	LastUpdateID int64
+	Bids         []entity.Order
+	Asks         []entity.Order
+}
+
+// Diff — инкрементальное обновление стакана, покрывающее номера [FirstUpdateID, FinalUpdateID].
+// Для бирж с одним номером версии на сообщение FirstUpdateID == FinalUpdateID.
+// Нулевой объём уровня означает его удаление.
+type Diff struct {
+	FirstUpdateID int64
+	FinalUpdateID int64
+	Bids          []entity.Order
+	Asks          []entity.Order
+}
+
+// Book — локальный стакан одного символа: снапшот плюс последовательно применённые диффы.
+//
+// До получения снапшота диффы буферизуются. После снапшота каждый дифф обязан
+// продолжать последовательность (FirstUpdateID <= last+1 <= FinalUpdateID);
+// иначе стакан сбрасывается в несинхронизированное состояние и возвращается ErrGap.
+// Все методы потокобезопасны; читатели получают согласованную копию.
+type Book struct {
+	mu           sync.RWMutex
+	bids         map[float64]float64
+	asks         map[float64]float64
+	lastUpdateID int64
+	synced       bool
+	buffer       []Diff
+}
+
+// NewBook создаёт пустой несинхронизированный стакан.
+func NewBook() *Book {
+	return &Book{
+		bids: make(map[float64]float64),
+		asks: make(map[float64]float64),
+	}
+}
+
+// Apply применяет дифф. Устаревшие диффы (уже покрытые стаканом) молча игнорируются.
+// Возвращает ErrGap при обнаружении пропуска — вызывающая сторона должна запросить новый снапшот.
+func (b *Book) Apply(d Diff) error {
+	if d.FinalUpdateID < d.FirstUpdateID {
+		return fmt.Errorf("invalid diff range [%d, %d]", d.FirstUpdateID, d.FinalUpdateID)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.synced {
+		if len(b.buffer) >= maxBuffered {
+			b.buffer = b.buffer[1:]
+		}
+		b.buffer = append(b.buffer, d)
+		return nil
+	}
+
+	return b.applyLocked(d)
+}
+
+// Reset инициализирует стакан снапшотом и применяет накопленные диффы.
+// Если буфер не стыкуется со снапшотом, стакан остаётся несинхронизированным и возвращается ErrGap.
+func (b *Book) Reset(s Snapshot) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bids = make(map[float64]float64, len(s.Bids))
+	b.asks = make(map[float64]float64, len(s.Asks))
+	applySide(b.bids, s.Bids)
+	applySide(b.asks, s.Asks)
+	b.lastUpdateID = s.LastUpdateID
+	b.synced = true
+
+	buffered := b.buffer
+	b.buffer = nil
+	for _, d := range buffered {
+		if err := b.applyLocked(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invalidate переводит стакан в несинхронизированное состояние (например, после разрыва соединения).
+func (b *Book) Invalidate() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.invalidateLocked()
+}
+
+// Synced сообщает, синхронизирован ли стакан.
+func (b *Book) Synced() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.synced
+}
+
+// LastUpdateID возвращает номер последнего применённого обновления.
+func (b *Book) LastUpdateID() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.lastUpdateID
+}
+
+// Top возвращает копию верхних limit уровней (bids по убыванию, asks по возрастанию).
+// При limit <= 0 возвращается весь стакан. Для несинхронизированного стакана — ErrNotSynced.
+func (b *Book) Top(limit int) (entity.OrderBook, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if !b.synced {
+		return entity.OrderBook{}, ErrNotSynced
+	}
+	return entity.OrderBook{
+		Bids: sortedSide(b.bids, func(x, y float64) bool { return x > y }, limit),
+		Asks: sortedSide(b.asks, func(x, y float64) bool { return x < y }, limit),
+	}, nil
+}
+
+func (b *Book) applyLocked(d Diff) error {
+	if d.FinalUpdateID <= b.lastUpdateID {
+		return nil
+	}
+	if d.FirstUpdateID > b.lastUpdateID+1 {
+		expected := b.lastUpdateID + 1
+		b.invalidateLocked()
+		return fmt.Errorf("%w: expected %d, got [%d, %d]", ErrGap, expected, d.FirstUpdateID, d.FinalUpdateID)
+	}
+
+	applySide(b.bids, d.Bids)
+	applySide(b.asks, d.Asks)
+	b.lastUpdateID = d.FinalUpdateID
+	return nil
+}
+
+func (b *Book) invalidateLocked() {
+	b.synced = false
+	b.buffer = nil
+	b.bids = make(map[float64]float64)
+	b.asks = make(map[float64]float64)
+}
+
+func applySide(side map[float64]float64, levels []entity.Order) {
+	for _, l := range levels {
+		if l.Quantity <= 0 {
+			delete(side, l.Price)
+			continue
+		}
+		side[l.Price] = l.Quantity
+	}
+}
+
+func sortedSide(side map[float64]float64, less func(x, y float64) bool, limit int) []entity.Order {
+	prices := make([]float64, 0, len(side))
+	for p := range side {
+		prices = append(prices, p)
+	}
+	sort.Slice(prices, func(a, c int) bool { return less(prices[a], prices[c]) })
+	if limit > 0 && len(prices) > limit {
+		prices = prices[:limit]
+	}
+
+	levels := make([]entity.Order, 0, len(prices))
+	for _, p := range prices {
+		levels = append(levels, entity.Order{Price: p, Quantity: side[p]})
+	}
+	return levels
+}
@@ -0,0 +1,181 @@
+package orderbook
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+func level(price, qty float64) []entity.Order {
+	return []entity.Order{{Price: price, Quantity: qty}}
+}
+
+func TestBook_BuffersUntilSnapshot(t *testing.T) {
+	b := NewBook()
+
+	// Диффы до снапшота: 10 уже покрыт снапшотом, 11 и 12 должны примениться.
+	for _, d := range []Diff{
+		{FirstUpdateID: 10, FinalUpdateID: 10, Bids: level(97, 1)},
+		{FirstUpdateID: 11, FinalUpdateID: 11, Bids: level(99, 2)},
+		{FirstUpdateID: 12, FinalUpdateID: 12, Asks: level(101, 0)},
+	} {
+		if err := b.Apply(d); err != nil {
+			t.Fatalf("Apply before snapshot: %v", err)
+		}
+	}
+	if _, err := b.Top(0); !errors.Is(err, ErrNotSynced) {
+		t.Fatalf("Top before snapshot err = %v, want ErrNotSynced", err)
+	}
+
+	err := b.Reset(Snapshot{
+		LastUpdateID: 10,
+		Bids:         level(98, 5),
+		Asks:         []entity.Order{{Price: 101, Quantity: 1}, {Price: 102, Quantity: 3}},
+	})
+	if err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+
+	book, err := b.Top(0)
+	if err != nil {
+		t.Fatalf("Top: %v", err)
+	}
+	if len(book.Bids) != 2 || book.Bids[0].Price != 99 || book.Bids[1].Price != 98 {
+		t.Errorf("bids = %+v, want [99 98] (diff 10 is stale)", book.Bids)
+	}
+	if len(book.Asks) != 1 || book.Asks[0].Price != 102 {
+		t.Errorf("asks = %+v, want [102]", book.Asks)
+	}
+	if got := b.LastUpdateID(); got != 12 {
+		t.Errorf("LastUpdateID = %d, want 12", got)
+	}
+}
+
+func TestBook_GapInvalidates(t *testing.T) {
+	b := NewBook()
+	if err := b.Reset(Snapshot{LastUpdateID: 5, Bids: level(10, 1)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.Apply(Diff{FirstUpdateID: 3, FinalUpdateID: 5, Bids: level(9, 1)}); err != nil {
+		t.Fatalf("stale diff: %v", err)
+	}
+	if err := b.Apply(Diff{FirstUpdateID: 8, FinalUpdateID: 9}); !errors.Is(err, ErrGap) {
+		t.Fatalf("Apply with gap err = %v, want ErrGap", err)
+	}
+	if b.Synced() {
+		t.Error("book must be unsynced after gap")
+	}
+	if _, err := b.Top(1); !errors.Is(err, ErrNotSynced) {
+		t.Errorf("Top after gap err = %v, want ErrNotSynced", err)
+	}
+}
+
+func TestBook_TopLimit(t *testing.T) {
+	b := NewBook()
+	err := b.Reset(Snapshot{
+		LastUpdateID: 1,
+		Bids:         []entity.Order{{Price: 1, Quantity: 1}, {Price: 3, Quantity: 1}, {Price: 2, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	book, _ := b.Top(2)
+	if len(book.Bids) != 2 || book.Bids[0].Price != 3 || book.Bids[1].Price != 2 {
+		t.Errorf("bids = %+v, want [3 2]", book.Bids)
+	}
+}
+
+func TestSyncer_ResyncsAfterGap(t *testing.T) {
+	var calls atomic.Int32
+	snap := func(_ context.Context, symbol string) (Snapshot, error) {
+		n := calls.Add(1)
+		if symbol != "SOLUSDT" {
+			t.Errorf("snapshot symbol = %q", symbol)
+		}
+		if n == 2 {
+			return Snapshot{}, errors.New("temporary failure")
+		}
+		return Snapshot{LastUpdateID: int64(n) * 100, Bids: level(float64(n), 1)}, nil
+	}
+
+	s, err := NewSyncer(logger.New("error"), SyncerConfig{
+		Symbols:   []string{"solusdt"},
+		Snapshot:  snap,
+		ResyncMin: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Run(ctx) }()
+
+	waitSynced := func(what string, cond func(entity.OrderBook) bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if b, ok := s.OrderBook("SOLUSDT", 0); ok && cond(b) {
+				return
+			}
+			time.Sleep(2 * time.Millisecond)
+		}
+		t.Fatalf("timeout waiting for %s", what)
+	}
+
+	waitSynced("initial snapshot", func(b entity.OrderBook) bool { return b.Bids[0].Price == 1 })
+
+	// Пропуск версии: стакан сбрасывается, первая попытка ресинхронизации падает, вторая успешна.
+	s.Apply("SOLUSDT", Diff{FirstUpdateID: 150, FinalUpdateID: 150})
+	waitSynced("resync", func(b entity.OrderBook) bool { return b.Bids[0].Price == 3 })
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("snapshot calls = %d, want 3", got)
+	}
+}
+
+func TestSyncer_QueuesEveryInvalidatedSymbol(t *testing.T) {
+	symbols := []string{"AUSDT", "BUSDT", "CUSDT", "DUSDT"}
+	var calls atomic.Int32
+	snap := func(context.Context, string) (Snapshot, error) {
+		calls.Add(1)
+		return Snapshot{LastUpdateID: 100, Bids: level(1, 1)}, nil
+	}
+	s, err := NewSyncer(logger.New("error"), SyncerConfig{Symbols: symbols, Snapshot: snap})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Повторные запросы одного символа до запуска Run — больше, чем символов всего:
+	// они не должны вытеснить запросы остальных.
+	for range 2 * len(symbols) {
+		s.requestResync("BUSDT")
+	}
+	s.InvalidateAll()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Run(ctx) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for _, symbol := range symbols {
+		for {
+			if _, ok := s.OrderBook(symbol, 0); ok {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s not synced", symbol)
+			}
+			time.Sleep(2 * time.Millisecond)
+		}
+	}
+	if got := calls.Load(); got != int32(len(symbols)) {
+		t.Errorf("snapshot calls = %d, want one per symbol", got)
+	}
+}
@@ -0,0 +1,223 @@
+package orderbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+)
+
+const (
+	defaultResyncMin = 500 * time.Millisecond
+	defaultResyncMax = 30 * time.Second
+)
+
+// SnapshotFunc загружает снапшот стакана символа (например, через REST Depth).
+type SnapshotFunc func(ctx context.Context, symbol string) (Snapshot, error)
+
+// SyncerConfig — параметры синхронизатора.
+type SyncerConfig struct {
+	// Symbols — символы, для которых поддерживаются стаканы.
+	Symbols []string
+	// Snapshot — источник снапшотов. Обязателен.
+	Snapshot SnapshotFunc
+	// ResyncMin/ResyncMax — границы экспоненциальной задержки повторного запроса снапшота.
+	ResyncMin time.Duration
+	ResyncMax time.Duration
+	// OnUpdate вызывается после каждого успешно применённого диффа или снапшота. Необязателен.
+	// Вызывается синхронно — обработчик не должен блокироваться.
+	OnUpdate func(symbol string)
+}
+
+// Syncer поддерживает набор стаканов: применяет диффы, при пропусках и разрывах
+// запрашивает новые снапшоты и отдаёт согласованные top-N представления.
+//
+// Запросы ресинхронизации копятся в очереди без повторов: у каждого символа не больше
+// одного ожидающего запроса, и ни один запрос не теряется.
+type Syncer struct {
+	log   i.Logger
+	cfg   SyncerConfig
+	books map[string]*Book
+
+	mu      sync.Mutex
+	queue   []string            // символы в порядке запросов
+	pending map[string]struct{} // символы в queue
+	wake    chan struct{}       // сигнал Run о новых запросах
+}
+
+// NewSyncer создаёт синхронизатор. Снапшоты запрашиваются в Run.
+func NewSyncer(log i.Logger, cfg SyncerConfig) (*Syncer, error) {
+	if cfg.Snapshot == nil {
+		return nil, errors.New("snapshot func must not be nil")
+	}
+	if len(cfg.Symbols) == 0 {
+		return nil, errors.New("no symbols provided")
+	}
+	if cfg.ResyncMin <= 0 {
+		cfg.ResyncMin = defaultResyncMin
+	}
+	if cfg.ResyncMax < cfg.ResyncMin {
+		cfg.ResyncMax = max(defaultResyncMax, cfg.ResyncMin)
+	}
+
+	books := make(map[string]*Book, len(cfg.Symbols))
+	for _, s := range cfg.Symbols {
+		books[normalize(s)] = NewBook()
+	}
+
+	return &Syncer{
+		log:     log.Named("orderbook"),
+		cfg:     cfg,
+		books:   books,
+		pending: make(map[string]struct{}, len(books)),
+		wake:    make(chan struct{}, 1),
+	}, nil
+}
+
+// Apply применяет дифф к стакану символа. При пропуске запрашивает ресинхронизацию.
+// Диффы неизвестных символов игнорируются.
+func (s *Syncer) Apply(symbol string, d Diff) {
+	symbol = normalize(symbol)
+	b, ok := s.books[symbol]
+	if !ok {
+		return
+	}
+
+	wasSynced := b.Synced()
+	if err := b.Apply(d); err != nil {
+		s.log.Warn("order book gap, resyncing", "symbol", symbol, "err", err)
+		s.requestResync(symbol)
+		return
+	}
+	if wasSynced && s.cfg.OnUpdate != nil {
+		s.cfg.OnUpdate(symbol)
+	}
+}
+
+// InvalidateAll сбрасывает все стаканы и запрашивает для них новые снапшоты.
+// Вызывается, например, после переподключения потока диффов.
+func (s *Syncer) InvalidateAll() {
+	for symbol, b := range s.books {
+		b.Invalidate()
+		s.requestResync(symbol)
+	}
+}
+
+// OrderBook возвращает top-N стакан символа; ok == false, если стакан не синхронизирован.
+func (s *Syncer) OrderBook(symbol string, limit int) (entity.OrderBook, bool) {
+	b, ok := s.books[normalize(symbol)]
+	if !ok {
+		return entity.OrderBook{}, false
+	}
+	book, err := b.Top(limit)
+	if err != nil {
+		return entity.OrderBook{}, false
+	}
+	return book, true
+}
+
+// Run запрашивает снапшоты для всех символов, а затем обслуживает запросы ресинхронизации
+// до отмены ctx. Возвращает ctx.Err().
+func (s *Syncer) Run(ctx context.Context) error {
+	for symbol := range s.books {
+		s.requestResync(symbol)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.wake:
+			for {
+				symbol, ok := s.nextResync()
+				if !ok {
+					break
+				}
+				if s.books[symbol].Synced() {
+					continue
+				}
+				if err := s.syncWithRetry(ctx, symbol); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// syncWithRetry загружает снапшот и применяет буфер, повторяя с экспоненциальной задержкой.
+func (s *Syncer) syncWithRetry(ctx context.Context, symbol string) error {
+	b := s.books[symbol]
+	backoff := s.cfg.ResyncMin
+	for {
+		err := s.sync(ctx, symbol, b)
+		if err == nil {
+			if s.cfg.OnUpdate != nil {
+				s.cfg.OnUpdate(symbol)
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.log.Warn("order book sync failed", "symbol", symbol, "err", err, "backoff", backoff)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, s.cfg.ResyncMax)
+	}
+}
+
+func (s *Syncer) sync(ctx context.Context, symbol string, b *Book) error {
+	snap, err := s.cfg.Snapshot(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	if err := b.Reset(snap); err != nil {
+		return err
+	}
+	s.log.Debug("order book synced", "symbol", symbol, "last_update_id", snap.LastUpdateID)
+	return nil
+}
+
+// requestResync ставит символ в очередь ресинхронизации, если он ещё не ожидает в ней.
+func (s *Syncer) requestResync(symbol string) {
+	s.mu.Lock()
+	if _, ok := s.pending[symbol]; !ok {
+		s.pending[symbol] = struct{}{}
+		s.queue = append(s.queue, symbol)
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+		// Run уже разбудят: сигнал ожидает обработки.
+	}
+}
+
+// nextResync извлекает следующий символ из очереди ресинхронизации.
+// Символ снимается с ожидания до загрузки снапшота, поэтому разрыв,
+// случившийся во время ресинхронизации, снова поставит его в очередь.
+func (s *Syncer) nextResync() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return "", false
+	}
+	symbol := s.queue[0]
+	s.queue = s.queue[1:]
+	delete(s.pending, symbol)
+	return symbol, true
+}
+
+func normalize(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
@@ -0,0 +1,105 @@
+package orderbook
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+)
+
+// ErrInsufficientDepth — стакана не хватает, чтобы исполнить запрошенный объём.
+var ErrInsufficientDepth = errors.New("insufficient order book depth")
+
+// Fill — результат прохода по одной стороне стакана на заданный объём BASE.
+type Fill struct {
+	// AvgPrice — средневзвешенная по объёму цена исполнения (VWAP), QUOTE за 1 BASE.
+	AvgPrice float64
+	// WorstPrice — цена самого дальнего затронутого уровня.
+	WorstPrice float64
+	// Qty — исполнимый объём BASE; меньше запрошенного, если стакана не хватило.
+	Qty float64
+	// Notional — суммарная стоимость исполнения в QUOTE.
+	Notional float64
+	// Levels — количество затронутых уровней.
+	Levels int
+}
+
+// Filled сообщает, покрыт ли объём qty полностью.
+func (f Fill) Filled(qty float64) bool {
+	return qty > 0 && f.Qty >= qty*(1-fillEpsilon)
+}
+
+// fillEpsilon — допуск на погрешность float при сравнении исполненного объёма с запрошенным.
+const fillEpsilon = 1e-9
+
+// Walk проходит уровни одной стороны стакана (в порядке приоритета: лучшая цена первой)
+// и набирает объём qty. Уровни с неположительной ценой или объёмом пропускаются.
+func Walk(levels []entity.Order, qty float64) Fill {
+	var f Fill
+	if qty <= 0 {
+		return f
+	}
+
+	remaining := qty
+	for _, l := range levels {
+		if l.Price <= 0 || l.Quantity <= 0 {
+			continue
+		}
+		take := min(l.Quantity, remaining)
+		f.Qty += take
+		f.Notional += take * l.Price
+		f.WorstPrice = l.Price
+		f.Levels++
+
+		remaining -= take
+		if remaining <= qty*fillEpsilon {
+			break
+		}
+	}
+	if f.Qty > 0 {
+		f.AvgPrice = f.Notional / f.Qty
+	}
+	return f
+}
+
+// Sell — исполнение рыночной продажи qty BASE по бидам стакана.
+func Sell(book entity.OrderBook, qty float64) Fill {
+	return Walk(book.Bids, qty)
+}
+
+// Buy — исполнение рыночной покупки qty BASE по аскам стакана.
+func Buy(book entity.OrderBook, qty float64) Fill {
+	return Walk(book.Asks, qty)
+}
+
+// ExecutableQuote строит исполнимую котировку CEX под объём baseAmount:
+// Bid — VWAP продажи по бидам, Ask — VWAP покупки по аскам, BidQty/AskQty — исполнимый объём.
+// Так котировки CEX сравнимы с котировками DEX, рассчитанными под тот же объём.
+// Если хотя бы одна сторона не покрывает baseAmount целиком, возвращается ErrInsufficientDepth.
+func ExecutableQuote(
+	exchange, pair string,
+	book entity.OrderBook,
+	baseAmount float64,
+	ts time.Time,
+) (entity.ExecutableQuote, error) {
+	if baseAmount <= 0 {
+		return entity.ExecutableQuote{}, fmt.Errorf("base amount must be positive, got %v", baseAmount)
+	}
+
+	bid, ask := Sell(book, baseAmount), Buy(book, baseAmount)
+	if !bid.Filled(baseAmount) || !ask.Filled(baseAmount) {
+		return entity.ExecutableQuote{}, fmt.Errorf("%w: %s %s: want %v, bids %v, asks %v",
+			ErrInsufficientDepth, exchange, pair, baseAmount, bid.Qty, ask.Qty)
+	}
+
+	return entity.ExecutableQuote{
+		Exchange:  exchange,
+		Pair:      pair,
+		Bid:       bid.AvgPrice,
+		Ask:       ask.AvgPrice,
+		BidQty:    bid.Qty,
+		AskQty:    ask.Qty,
+		Timestamp: ts,
+	}, nil
+}
@@ -0,0 +1,59 @@
+package orderbook
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+)
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+var depthBook = entity.OrderBook{
+	Bids: []entity.Order{{Price: 100, Quantity: 1}, {Price: 99, Quantity: 2}, {Price: 98, Quantity: 5}},
+	Asks: []entity.Order{{Price: 101, Quantity: 0.5}, {Price: 0, Quantity: 9}, {Price: 102, Quantity: 3}},
+}
+
+func TestWalk_MultipleLevels(t *testing.T) {
+	f := Sell(depthBook, 2.5)
+	// 1*100 + 1.5*99 = 248.5
+	if !approx(f.Qty, 2.5) || !approx(f.Notional, 248.5) || !approx(f.AvgPrice, 248.5/2.5) {
+		t.Errorf("sell fill = %+v", f)
+	}
+	if f.WorstPrice != 99 || f.Levels != 2 {
+		t.Errorf("worst = %v, levels = %d, want 99, 2", f.WorstPrice, f.Levels)
+	}
+
+	f = Buy(depthBook, 1)
+	// Уровень с нулевой ценой пропускается: 0.5*101 + 0.5*102.
+	if !approx(f.AvgPrice, 101.5) || f.WorstPrice != 102 || f.Levels != 2 {
+		t.Errorf("buy fill = %+v", f)
+	}
+}
+
+func TestWalk_PartialFill(t *testing.T) {
+	f := Buy(depthBook, 10)
+	if !approx(f.Qty, 3.5) || f.Filled(10) {
+		t.Errorf("fill = %+v, want partial 3.5", f)
+	}
+}
+
+func TestExecutableQuote(t *testing.T) {
+	ts := time.Unix(100, 0)
+	q, err := ExecutableQuote("mexc", "SOL/USDT", depthBook, 1, ts)
+	if err != nil {
+		t.Fatalf("ExecutableQuote: %v", err)
+	}
+	want := entity.ExecutableQuote{
+		Exchange: "mexc", Pair: "SOL/USDT", Bid: 100, Ask: 101.5, BidQty: 1, AskQty: 1, Timestamp: ts,
+	}
+	if q != want {
+		t.Errorf("quote = %+v, want %+v", q, want)
+	}
+
+	if _, err := ExecutableQuote("mexc", "SOL/USDT", depthBook, 5, ts); !errors.Is(err, ErrInsufficientDepth) {
+		t.Errorf("err = %v, want ErrInsufficientDepth", err)
+	}
+}
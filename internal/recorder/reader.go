@@ -0,0 +1,133 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reader последовательно читает записанные события из каталога истории в порядке файлов.
+// Внутри файла события идут в порядке записи.
+type Reader struct {
+	files    []string
+	from, to time.Time
+
+	file *os.File
+	gz   *gzip.Reader
+	dec  *json.Decoder
+}
+
+// OpenDir открывает историю каталога dir. Непустые from и to ограничивают события
+// интервалом [from, to); файлы, целиком лежащие вне интервала, не читаются.
+func OpenDir(dir string, from, to time.Time) (*Reader, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read history dir: %w", err)
+	}
+
+	type named struct {
+		path    string
+		started time.Time
+	}
+	var files []named
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, fileExt) {
+			continue
+		}
+		started, err := time.Parse(fileLayout, strings.TrimSuffix(name, fileExt))
+		if err != nil {
+			continue
+		}
+		files = append(files, named{path: filepath.Join(dir, name), started: started})
+	}
+	sort.Slice(files, func(a, b int) bool { return files[a].started.Before(files[b].started) })
+
+	r := &Reader{from: from, to: to}
+	for n, f := range files {
+		if !to.IsZero() && !f.started.Before(to) {
+			break
+		}
+		// Файл заканчивается не позже начала следующего.
+		if !from.IsZero() && n+1 < len(files) && !files[n+1].started.After(from) {
+			continue
+		}
+		r.files = append(r.files, f.path)
+	}
+	return r, nil
+}
+
+// Next возвращает следующее событие или io.EOF, когда история закончилась.
+// Оборванный хвост файла (запись прервана аварийно) считается его концом.
+func (r *Reader) Next() (Event, error) {
+	for {
+		if r.dec == nil {
+			if len(r.files) == 0 {
+				return Event{}, io.EOF
+			}
+			path := r.files[0]
+			r.files = r.files[1:]
+			err := r.open(path)
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				continue // Файл создан, но данные в него не успели попасть.
+			}
+			if err != nil {
+				return Event{}, err
+			}
+		}
+
+		var ev Event
+		err := r.dec.Decode(&ev)
+		switch {
+		case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+			if err := r.closeFile(); err != nil {
+				return Event{}, err
+			}
+			continue
+		case err != nil:
+			return Event{}, fmt.Errorf("decode %s: %w", r.file.Name(), err)
+		}
+
+		if !r.from.IsZero() && ev.Time.Before(r.from) || !r.to.IsZero() && !ev.Time.Before(r.to) {
+			continue
+		}
+		return ev, nil
+	}
+}
+
+// Close освобождает открытый файл.
+func (r *Reader) Close() error {
+	r.files = nil
+	return r.closeFile()
+}
+
+func (r *Reader) open(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open history file: %w", err)
+	}
+	gz, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("open history file %s: %w", path, err)
+	}
+	r.file, r.gz, r.dec = f, gz, json.NewDecoder(gz)
+	return nil
+}
+
+func (r *Reader) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file, r.gz, r.dec = nil, nil, nil
+	return err
+}
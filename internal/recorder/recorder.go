@@ -0,0 +1,226 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+)
+
+const (
+	defaultRotate = time.Hour
+	defaultBuffer = 4096
+
+	// flushInterval — как часто сжатые данные сбрасываются на диск: при аварийном
+	// завершении теряется не больше этого интервала.
+	flushInterval = time.Second
+
+	fileExt    = ".jsonl.gz"
+	fileLayout = "20060102T150405"
+)
+
+// Kind — тип записанного события.
+type Kind string
+
+const (
+	KindTicker Kind = "ticker"
+	KindQuote  Kind = "quote"
+	KindBook   Kind = "book"
+)
+
+// Book — результат загрузки стакана в записи; ошибка хранится текстом.
+type Book struct {
+	Exchange string           `json:"exchange"`
+	Symbol   string           `json:"symbol"`
+	Data     entity.OrderBook `json:"data"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// Event — одно записанное событие рынка. Заполнено поле, соответствующее Kind.
+type Event struct {
+	Kind   Kind                    `json:"kind"`
+	Time   time.Time               `json:"time"`
+	Ticker *entity.TickerData      `json:"ticker,omitempty"`
+	Quote  *entity.ExecutableQuote `json:"quote,omitempty"`
+	Book   *Book                   `json:"book,omitempty"`
+}
+
+// Options — параметры записи.
+type Options struct {
+	// Dir — каталог файлов истории.
+	Dir string
+	// Rotate — длительность одного файла. При 0 — 1 час.
+	Rotate time.Duration
+	// Buffer — размер очереди событий; при переполнении события отбрасываются. При 0 — 4096.
+	Buffer int
+}
+
+// Recorder пишет тикеры, котировки и стаканы в сжатые файлы JSON Lines (gzip),
+// по файлу на каждый интервал Rotate; имя файла — время его первого события (UTC).
+//
+// Методы Record* не блокируют конвейер сканера: события ставятся в очередь, которую
+// разбирает Run, а при её переполнении отбрасываются (число отброшенных — в логе).
+type Recorder struct {
+	log     i.Logger
+	opts    Options
+	events  chan Event
+	dropped atomic.Int64
+	now     func() time.Time
+}
+
+// NewRecorder создаёт запись в каталог opts.Dir, создавая его при необходимости.
+func NewRecorder(log i.Logger, opts Options) (*Recorder, error) {
+	if opts.Dir == "" {
+		return nil, errors.New("recorder dir must not be empty")
+	}
+	if opts.Rotate <= 0 {
+		opts.Rotate = defaultRotate
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = defaultBuffer
+	}
+	if err := os.MkdirAll(opts.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create recorder dir: %w", err)
+	}
+	return &Recorder{
+		log:    log.Named("recorder"),
+		opts:   opts,
+		events: make(chan Event, opts.Buffer),
+		now:    time.Now,
+	}, nil
+}
+
+// RecordTicker ставит в очередь тикер; время события — текущее.
+func (r *Recorder) RecordTicker(t entity.TickerData) {
+	r.enqueue(Event{Kind: KindTicker, Time: r.now(), Ticker: &t})
+}
+
+// RecordQuote ставит в очередь исполнимую котировку.
+func (r *Recorder) RecordQuote(q entity.ExecutableQuote) {
+	r.enqueue(Event{Kind: KindQuote, Time: q.Timestamp, Quote: &q})
+}
+
+// RecordBook ставит в очередь результат загрузки стакана.
+func (r *Recorder) RecordBook(res entity.OrderBookResult) {
+	b := &Book{Exchange: res.Exchange, Symbol: res.Symbol, Data: res.Data}
+	if res.Error != nil {
+		b.Error = res.Error.Error()
+	}
+	r.enqueue(Event{Kind: KindBook, Time: res.Timestamp, Book: b})
+}
+
+func (r *Recorder) enqueue(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = r.now()
+	}
+	select {
+	case r.events <- ev:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Run пишет события из очереди до отмены ctx, после чего дописывает уже
+// поставленные в очередь события и закрывает текущий файл.
+func (r *Recorder) Run(ctx context.Context) error {
+	w := &rotatingWriter{dir: r.opts.Dir, rotate: r.opts.Rotate}
+	defer func() {
+		if err := w.close(); err != nil {
+			r.log.Error("failed to close history file", "err", err)
+		}
+	}()
+
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case ev := <-r.events:
+					if err := w.write(ev); err != nil {
+						return err
+					}
+				default:
+					return nil
+				}
+			}
+		case ev := <-r.events:
+			if err := w.write(ev); err != nil {
+				return err
+			}
+		case <-flush.C:
+			if err := w.flush(); err != nil {
+				return err
+			}
+			if n := r.dropped.Swap(0); n > 0 {
+				r.log.Warn("history events dropped", "count", n)
+			}
+		}
+	}
+}
+
+// rotatingWriter пишет события в текущий файл и открывает новый по истечении rotate.
+type rotatingWriter struct {
+	dir    string
+	rotate time.Duration
+
+	file    *os.File
+	gz      *gzip.Writer
+	buf     *bufio.Writer
+	enc     *json.Encoder
+	started time.Time
+}
+
+func (w *rotatingWriter) write(ev Event) error {
+	if w.file == nil || ev.Time.Sub(w.started) >= w.rotate {
+		if err := w.open(ev.Time); err != nil {
+			return err
+		}
+	}
+	return w.enc.Encode(ev)
+}
+
+func (w *rotatingWriter) open(started time.Time) error {
+	if err := w.close(); err != nil {
+		return err
+	}
+	name := filepath.Join(w.dir, started.UTC().Format(fileLayout)+fileExt)
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open history file: %w", err)
+	}
+	w.file, w.gz, w.started = f, gzip.NewWriter(f), started
+	w.buf = bufio.NewWriter(w.gz)
+	w.enc = json.NewEncoder(w.buf)
+	return nil
+}
+
+func (w *rotatingWriter) flush() error {
+	if w.file == nil {
+		return nil
+	}
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	return w.gz.Flush()
+}
+
+func (w *rotatingWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := errors.Join(w.buf.Flush(), w.gz.Close(), w.file.Close())
+	w.file, w.gz, w.buf, w.enc = nil, nil, nil, nil
+	return err
+}
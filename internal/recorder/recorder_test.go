@@ -0,0 +1,111 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+func readAll(t *testing.T, dir string, from, to time.Time) []Event {
+	t.Helper()
+	r, err := OpenDir(dir, from, to)
+	if err != nil {
+		t.Fatalf("OpenDir: %v", err)
+	}
+	defer r.Close()
+
+	var events []Event
+	for {
+		ev, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		events = append(events, ev)
+	}
+}
+
+func TestRecorder_RoundTripWithRotation(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecorder(logger.New("error"), Options{Dir: dir, Rotate: time.Minute})
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	rec.now = func() time.Time { return t0 }
+
+	rec.RecordTicker(entity.TickerData{Symbol: "SOLUSDT", Exchange: "mexc", BidPrice: 99})
+	rec.RecordQuote(entity.ExecutableQuote{Exchange: "jupiter", Pair: "SOL/USDT", Bid: 100, Ask: 101, Timestamp: t0.Add(30 * time.Second)})
+	rec.RecordBook(entity.OrderBookResult{Exchange: "mexc", Symbol: "SOL/USDT", Error: errors.New("timeout"), Timestamp: t0.Add(90 * time.Second)})
+	rec.RecordQuote(entity.ExecutableQuote{Exchange: "mexc", Pair: "SOL/USDT", Bid: 99, Ask: 99.5, Timestamp: t0.Add(3 * time.Minute)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // Run дописывает очередь и закрывает файл.
+	if err := rec.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+fileExt))
+	if len(files) != 3 {
+		t.Errorf("files = %v, want 3", files)
+	}
+
+	events := readAll(t, dir, time.Time{}, time.Time{})
+	if len(events) != 4 {
+		t.Fatalf("got %d events, want 4", len(events))
+	}
+	if events[0].Kind != KindTicker || events[0].Ticker.BidPrice != 99 {
+		t.Errorf("ticker event = %+v", events[0])
+	}
+	if events[1].Kind != KindQuote || events[1].Quote.Ask != 101 || !events[1].Quote.Timestamp.Equal(t0.Add(30*time.Second)) {
+		t.Errorf("quote event = %+v", events[1])
+	}
+	if events[2].Kind != KindBook || events[2].Book.Error != "timeout" {
+		t.Errorf("book event = %+v", events[2])
+	}
+
+	ranged := readAll(t, dir, t0.Add(time.Minute), t0.Add(2*time.Minute))
+	if len(ranged) != 1 || ranged[0].Kind != KindBook {
+		t.Errorf("ranged = %+v, want the book event", ranged)
+	}
+}
+
+func TestReader_TruncatedTail(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecorder(logger.New("error"), Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for n := range 50 {
+		rec.RecordQuote(entity.ExecutableQuote{Exchange: "mexc", Pair: "SOL/USDT", Bid: float64(n), Timestamp: t0.Add(time.Duration(n) * time.Second)})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := rec.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// Обрезаем файл, как при аварийном завершении записи.
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+fileExt))
+	info, err := os.Stat(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(files[0], info.Size()-8); err != nil {
+		t.Fatal(err)
+	}
+
+	if events := readAll(t, dir, time.Time{}, time.Time{}); len(events) == 0 {
+		t.Error("no events read from truncated file")
+	}
+}
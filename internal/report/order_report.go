@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/dimryb/cross-arb/internal/entity"
+	"github.com/dimryb/cross-arb/internal/market"
 )
 
 func PrintOrderBookReport(results []entity.OrderBookResult) {
@@ -16,24 +17,21 @@ func PrintOrderBookReport(results []entity.OrderBookResult) {
 				fmt.Printf("  [%s] Error: %v\n", r.Symbol, r.Error)
 				continue
 			}
-			baseAsset := extractBaseAsset(r.Symbol)
-			fmt.Printf("[%s] ASK (Можно купить):\n", baseAsset)
+			inst, err := market.ParsePair(r.Symbol)
+			if err != nil {
+				fmt.Printf("  [%s] Error: %v\n", r.Symbol, err)
+				continue
+			}
+			fmt.Printf("[%s] ASK (Можно купить):\n", inst.Base)
 			for _, ask := range r.Data.Asks {
 				fmt.Printf("Купить по цене: %.2f %s | Доступное количество: %.3f %s\n",
-					ask.Price, "USDT", ask.Quantity, baseAsset)
+					ask.Price, inst.Quote, ask.Quantity, inst.Base)
 			}
-			fmt.Printf("[%s] BID (Можно Продать):\n", baseAsset)
+			fmt.Printf("[%s] BID (Можно Продать):\n", inst.Base)
 			for _, bid := range r.Data.Bids {
 				fmt.Printf("Продать по цене: %.2f %s | Доступное количество: %.3f %s\n",
-					bid.Price, "USDT", bid.Quantity, baseAsset)
+					bid.Price, inst.Quote, bid.Quantity, inst.Base)
 			}
 		}
 	}
 }
-
-func extractBaseAsset(symbol string) string {
-	if len(symbol) > 4 && symbol[len(symbol)-4:] == "USDT" {
-		return symbol[:len(symbol)-4]
-	}
-	return symbol
-}
@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+)
+
+var (
+	opportunitiesBucket = []byte("opportunities")
+	executionsBucket    = []byte("executions")
+	fillsBucket         = []byte("fills")
+	// transfersBucket — переводы ребалансировки в пути, по одному на актив.
+	transfersBucket = []byte("transfers")
+)
+
+// LegRole — роль ноги в исполнении.
+type LegRole string
+
+const (
+	RoleBuy   LegRole = "buy"
+	RoleSell  LegRole = "sell"
+	RoleHedge LegRole = "hedge"
+)
+
+// FillRecord — исполнение одной ноги (в том числе неудачное) в журнале.
+type FillRecord struct {
+	ExecutionID string      `json:"executionId"`
+	Role        LegRole     `json:"role"`
+	Pair        string      `json:"pair"`
+	Exchange    string      `json:"exchange"`
+	Side        entity.Side `json:"side"`
+	OrderID     string      `json:"orderId,omitempty"` // Идентификатор ордера или сигнатура транзакции Solana
+	Requested   float64     `json:"requested"`
+	Filled      float64     `json:"filled"`
+	Quote       float64     `json:"quote"`
+	Fee         float64     `json:"fee"`
+	Estimated   bool        `json:"estimated,omitempty"` // Filled и Quote оценены по котировке
+	Uncertain   bool        `json:"uncertain,omitempty"` // исход ордера неизвестен
+	Error       string      `json:"error,omitempty"`
+	Time        time.Time   `json:"time"`
+}
+
+// ExecutionRecord — запись об исполнении в журнале.
+type ExecutionRecord struct {
+	ID          string                 `json:"id"`
+	Opportunity entity.ArbOpportunity  `json:"opportunity"`
+	Buy         FillRecord             `json:"buy"`
+	Sell        FillRecord             `json:"sell"`
+	Hedge       *FillRecord            `json:"hedge,omitempty"`
+	Status      entity.ExecutionStatus `json:"status"`
+	RealizedPnl float64                `json:"realizedPnl"`
+	Unhedged    float64                `json:"unhedged"`
+	StartedAt   time.Time              `json:"startedAt"`
+	FinishedAt  time.Time              `json:"finishedAt"`
+}
+
+// Query — фильтр выборки из журнала. Пустые поля не ограничивают выборку.
+type Query struct {
+	Pair  string
+	Venue string // Биржа покупки или продажи; для ног — биржа исполнения
+	From  time.Time
+	To    time.Time // Не включительно
+	// OrderID — идентификатор ордера или сигнатура транзакции; учитывается только для ног.
+	OrderID string
+	// Limit — максимальное число записей, 0 — без ограничения.
+	Limit int
+}
+
+// Journal — журнал найденных возможностей, исполнений и их ног во встроенной базе bbolt.
+//
+// Записи хранятся в порядке времени (обнаружения возможности, начала исполнения,
+// завершения исполнения ноги), поэтому выборка по интервалу не читает журнал целиком.
+// Безопасен для конкурентного использования.
+type Journal struct {
+	db *bolt.DB
+}
+
+// Open открывает журнал по пути path, создавая файл и каталог при необходимости.
+func Open(path string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("create journal dir: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open journal %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{opportunitiesBucket, executionsBucket, fillsBucket, transfersBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("init journal: %w", err)
+	}
+	return &Journal{db: db}, nil
+}
+
+// Close закрывает базу.
+func (j *Journal) Close() error {
+	return j.db.Close()
+}
+
+// SaveOpportunity сохраняет найденную возможность.
+func (j *Journal) SaveOpportunity(opp entity.ArbOpportunity) error {
+	return j.db.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(opportunitiesBucket), opp.DetectedAt, opp)
+	})
+}
+
+// SaveExecution сохраняет исполнение и его ноги одной транзакцией.
+func (j *Journal) SaveExecution(exec entity.Execution) error {
+	rec := ExecutionRecord{
+		ID:          exec.ID,
+		Opportunity: exec.Opportunity,
+		Buy:         fillRecord(exec, RoleBuy, exec.Buy),
+		Sell:        fillRecord(exec, RoleSell, exec.Sell),
+		Status:      exec.Status,
+		RealizedPnl: exec.RealizedPnl,
+		Unhedged:    exec.Unhedged,
+		StartedAt:   exec.StartedAt,
+		FinishedAt:  exec.FinishedAt,
+	}
+	if exec.Hedge != nil {
+		hedge := fillRecord(exec, RoleHedge, *exec.Hedge)
+		rec.Hedge = &hedge
+	}
+
+	return j.db.Update(func(tx *bolt.Tx) error {
+		if err := put(tx.Bucket(executionsBucket), exec.StartedAt, rec); err != nil {
+			return err
+		}
+		fills := tx.Bucket(fillsBucket)
+		for _, f := range []*FillRecord{&rec.Buy, &rec.Sell, rec.Hedge} {
+			// Нога, которая не отправлялась (последовательный режим без покупки), не сохраняется.
+			if f == nil || (f.Requested <= 0 && f.OrderID == "") {
+				continue
+			}
+			if err := put(fills, f.Time, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func fillRecord(exec entity.Execution, role LegRole, leg entity.ExecutionLeg) FillRecord {
+	f := FillRecord{
+		ExecutionID: exec.ID,
+		Role:        role,
+		Pair:        exec.Opportunity.Pair,
+		Exchange:    leg.Exchange,
+		Side:        leg.Side,
+		OrderID:     leg.OrderID,
+		Requested:   leg.Requested,
+		Filled:      leg.Filled,
+		Quote:       leg.Quote,
+		Fee:         leg.Fee,
+		Estimated:   leg.Estimated,
+		Uncertain:   leg.Uncertain,
+		Time:        exec.FinishedAt,
+	}
+	if leg.Error != nil {
+		f.Error = leg.Error.Error()
+	}
+	return f
+}
+
+// Opportunities возвращает возможности по фильтру в порядке обнаружения.
+func (j *Journal) Opportunities(q Query) ([]entity.ArbOpportunity, error) {
+	return scan(j.db, opportunitiesBucket, q, func(o entity.ArbOpportunity) bool {
+		return matchPair(q, o.Pair) && matchVenue(q, o.BuyOn, o.SellOn)
+	})
+}
+
+// Executions возвращает исполнения по фильтру в порядке начала.
+func (j *Journal) Executions(q Query) ([]ExecutionRecord, error) {
+	return scan(j.db, executionsBucket, q, func(r ExecutionRecord) bool {
+		return matchPair(q, r.Opportunity.Pair) && matchVenue(q, r.Opportunity.BuyOn, r.Opportunity.SellOn)
+	})
+}
+
+// Fills возвращает ноги исполнений по фильтру в порядке завершения.
+func (j *Journal) Fills(q Query) ([]FillRecord, error) {
+	return scan(j.db, fillsBucket, q, func(f FillRecord) bool {
+		return matchPair(q, f.Pair) && matchVenue(q, f.Exchange) && (q.OrderID == "" || q.OrderID == f.OrderID)
+	})
+}
+
+// SaveTransfer сохраняет перевод ребалансировки в статусе entity.TransferPending;
+// перевод в другом статусе удаляется. Ключ — актив: в пути не более одного перевода актива.
+func (j *Journal) SaveTransfer(t entity.Transfer) error {
+	return j.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(transfersBucket)
+		if t.Status != entity.TransferPending {
+			return b.Delete([]byte(t.Asset))
+		}
+		data, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(t.Asset), data)
+	})
+}
+
+// PendingTransfers возвращает сохранённые переводы в пути, упорядоченные по активу.
+func (j *Journal) PendingTransfers() ([]entity.Transfer, error) {
+	var out []entity.Transfer
+	err := j.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(transfersBucket).ForEach(func(_, v []byte) error {
+			var t entity.Transfer
+			if err := json.Unmarshal(v, &t); err != nil {
+				return fmt.Errorf("decode %s record: %w", transfersBucket, err)
+			}
+			out = append(out, t)
+			return nil
+		})
+	})
+	return out, err
+}
+
+func matchPair(q Query, pair string) bool {
+	return q.Pair == "" || q.Pair == pair
+}
+
+func matchVenue(q Query, venues ...string) bool {
+	if q.Venue == "" {
+		return true
+	}
+	for _, v := range venues {
+		if v == q.Venue {
+			return true
+		}
+	}
+	return false
+}
+
+// put сохраняет v под ключом из времени ts и порядкового номера в бакете:
+// ключи упорядочены по времени, а записи с одинаковым временем не перезаписывают друг друга.
+func put(b *bolt.Bucket, ts time.Time, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, timeKey(ts))
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return b.Put(key, data)
+}
+
+// timeKey переводит время в беззнаковый ключ; время до 1970 года ставится в начало.
+func timeKey(ts time.Time) uint64 {
+	if ts.IsZero() || ts.UnixNano() < 0 {
+		return 0
+	}
+	return uint64(ts.UnixNano())
+}
+
+func scan[T any](db *bolt.DB, bucket []byte, q Query, match func(T) bool) ([]T, error) {
+	if !q.From.IsZero() && !q.To.IsZero() && !q.To.After(q.From) {
+		return nil, errors.New("query: to must be after from")
+	}
+
+	var out []T
+	err := db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucket).Cursor()
+		from := make([]byte, 8)
+		binary.BigEndian.PutUint64(from, timeKey(q.From))
+
+		for k, v := c.Seek(from); k != nil; k, v = c.Next() {
+			if !q.To.IsZero() && binary.BigEndian.Uint64(k) >= timeKey(q.To) {
+				break
+			}
+			var rec T
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return fmt.Errorf("decode %s record: %w", bucket, err)
+			}
+			if !match(rec) {
+				continue
+			}
+			out = append(out, rec)
+			if q.Limit > 0 && len(out) >= q.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	return out, err
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+)
+
+func openTestJournal(t *testing.T) (*Journal, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data", "journal.db")
+	j, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = j.Close() })
+	return j, path
+}
+
+func TestJournal_OpportunitiesByPairVenueAndTime(t *testing.T) {
+	j, _ := openTestJournal(t)
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, o := range []entity.ArbOpportunity{
+		{Pair: "SOL/USDT", BuyOn: "mexc", SellOn: "jupiter", DetectedAt: t0},
+		{Pair: "SOL/USDT", BuyOn: "jupiter", SellOn: "mexc", DetectedAt: t0}, // то же время — не перезаписывает
+		{Pair: "JUP/USDT", BuyOn: "mexc", SellOn: "jupiter", DetectedAt: t0.Add(time.Minute)},
+		{Pair: "SOL/USDT", BuyOn: "binance", SellOn: "jupiter", DetectedAt: t0.Add(2 * time.Minute)},
+	} {
+		if err := j.SaveOpportunity(o); err != nil {
+			t.Fatalf("SaveOpportunity: %v", err)
+		}
+	}
+
+	got, err := j.Opportunities(Query{Pair: "SOL/USDT", Venue: "mexc"})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("by pair and venue = %+v, err = %v", got, err)
+	}
+	got, err = j.Opportunities(Query{From: t0.Add(time.Minute), To: t0.Add(2 * time.Minute)})
+	if err != nil || len(got) != 1 || got[0].Pair != "JUP/USDT" {
+		t.Errorf("by time = %+v, err = %v", got, err)
+	}
+	got, err = j.Opportunities(Query{Limit: 3})
+	if err != nil || len(got) != 3 || !got[2].DetectedAt.Equal(t0.Add(time.Minute)) {
+		t.Errorf("limited = %+v, err = %v", got, err)
+	}
+	if _, err := j.Opportunities(Query{From: t0, To: t0}); err == nil {
+		t.Error("expected error for empty range")
+	}
+}
+
+func TestJournal_ExecutionsAndFills(t *testing.T) {
+	j, path := openTestJournal(t)
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	opp := entity.ArbOpportunity{Pair: "SOL/USDT", BuyOn: "mexc", SellOn: "jupiter", Size: 2, DetectedAt: t0}
+	err := j.SaveExecution(entity.Execution{
+		ID:          "x1",
+		Opportunity: opp,
+		Buy:         entity.ExecutionLeg{Exchange: "mexc", Side: entity.SideBuy, OrderID: "C02__1", Requested: 2, Filled: 2, Quote: 200},
+		Sell:        entity.ExecutionLeg{Exchange: "jupiter", Side: entity.SideSell, OrderID: "5sig", Requested: 2, Filled: 1, Quote: 101, Estimated: true},
+		Hedge:       &entity.ExecutionLeg{Exchange: "mexc", Side: entity.SideSell, Requested: 1, Error: errors.New("price beyond limit")},
+		Status:      entity.ExecutionHedgeFailed,
+		Unhedged:    1,
+		StartedAt:   t0,
+		FinishedAt:  t0.Add(time.Second),
+	})
+	if err != nil {
+		t.Fatalf("SaveExecution: %v", err)
+	}
+
+	execs, err := j.Executions(Query{Venue: "jupiter"})
+	if err != nil || len(execs) != 1 {
+		t.Fatalf("executions = %+v, err = %v", execs, err)
+	}
+	if ex := execs[0]; ex.ID != "x1" || ex.Status != entity.ExecutionHedgeFailed || ex.Hedge == nil || ex.Hedge.Error != "price beyond limit" {
+		t.Errorf("execution = %+v", ex)
+	}
+
+	fills, err := j.Fills(Query{Venue: "mexc"})
+	if err != nil || len(fills) != 2 || fills[0].Role != RoleBuy || fills[1].Role != RoleHedge {
+		t.Errorf("mexc fills = %+v, err = %v", fills, err)
+	}
+	fills, err = j.Fills(Query{OrderID: "5sig"})
+	if err != nil || len(fills) != 1 || fills[0].ExecutionID != "x1" || fills[0].Filled != 1 || !fills[0].Estimated {
+		t.Errorf("fill by signature = %+v, err = %v", fills, err)
+	}
+
+	// Журнал переживает перезапуск.
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	j, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer j.Close()
+	if execs, err := j.Executions(Query{Pair: "SOL/USDT"}); err != nil || len(execs) != 1 {
+		t.Errorf("after reopen = %+v, err = %v", execs, err)
+	}
+}
+
+func TestJournal_PendingTransfersSurviveReopen(t *testing.T) {
+	j, path := openTestJournal(t)
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sol := entity.Transfer{ID: "w1", Asset: "SOL", From: "mexc", To: "jupiter", Amount: 4,
+		Status: entity.TransferPending, CreatedAt: created}
+	usdt := entity.Transfer{ID: "sig1", TxID: "sig1", Asset: "USDT", From: "jupiter", To: "mexc", Amount: 400,
+		Status: entity.TransferPending, CreatedAt: created}
+	for _, tr := range []entity.Transfer{sol, usdt} {
+		if err := j.SaveTransfer(tr); err != nil {
+			t.Fatalf("SaveTransfer: %v", err)
+		}
+	}
+	// Завершённый перевод удаляется.
+	usdt.Status = entity.TransferCredited
+	if err := j.SaveTransfer(usdt); err != nil {
+		t.Fatalf("SaveTransfer: %v", err)
+	}
+
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	j, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer j.Close()
+
+	got, err := j.PendingTransfers()
+	if err != nil {
+		t.Fatalf("PendingTransfers: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "w1" || got[0].Amount != 4 || !got[0].CreatedAt.Equal(created) {
+		t.Errorf("pending = %+v, want only SOL w1", got)
+	}
+}
@@ -0,0 +1,262 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+// Reason — код причины отклонения возможности.
+type Reason string
+
+const (
+	ReasonKillSwitch    Reason = "kill_switch"
+	ReasonTradeNotional Reason = "max_trade_notional"
+	ReasonExposure      Reason = "max_exposure"
+	ReasonDailyLoss     Reason = "max_daily_loss"
+	ReasonTradeRate     Reason = "max_trades_per_minute"
+)
+
+// Rejection — отказ в исполнении возможности.
+type Rejection struct {
+	Reason Reason
+	Detail string
+}
+
+func (r *Rejection) Error() string {
+	return fmt.Sprintf("%s: %s", r.Reason, r.Detail)
+}
+
+// Limits — ограничения риска. Нулевое значение ограничения означает его отсутствие.
+type Limits struct {
+	// MaxTradeNotional — максимальный объём одной сделки в QUOTE (по цене покупки).
+	MaxTradeNotional float64 `json:"maxTradeNotional"`
+	// MaxExposure — максимальная открытая позиция по активу (BASE) с учётом худшего случая:
+	// новая сделка целиком остаётся без пары.
+	MaxExposure map[string]float64 `json:"maxExposure"`
+	// MaxDailyLoss — максимальный реализованный убыток за сутки (UTC) в QUOTE, положительное число.
+	MaxDailyLoss float64 `json:"maxDailyLoss"`
+	// MaxTradesPerMinute — максимальное число исполненных сделок за скользящую минуту.
+	MaxTradesPerMinute int `json:"maxTradesPerMinute"`
+}
+
+// State — текущее состояние менеджера риска.
+type State struct {
+	Killed           bool               `json:"killed"`
+	KillReason       string             `json:"killReason,omitempty"`
+	Day              string             `json:"day"`
+	DailyPnl         float64            `json:"dailyPnl"`
+	Exposure         map[string]float64 `json:"exposure"`
+	TradesLastMinute int                `json:"tradesLastMinute"`
+	Rejections       map[Reason]int     `json:"rejections"`
+	Limits           Limits             `json:"limits"`
+}
+
+// Manager стоит между детектором и исполнением: допускает возможности в пределах
+// лимитов и учитывает результаты исполнения (реализованный PnL и открытые позиции).
+// Безопасен для конкурентного использования.
+type Manager struct {
+	log    i.Logger
+	limits Limits
+	now    func() time.Time
+
+	mu         sync.Mutex
+	killed     bool
+	killReason string
+	day        time.Time
+	dailyPnl   float64
+	exposure   map[string]float64
+	trades     []time.Time
+	rejections map[Reason]int
+}
+
+// NewManager создаёт менеджер риска.
+func NewManager(log i.Logger, limits Limits) (*Manager, error) {
+	if limits.MaxTradeNotional < 0 || limits.MaxDailyLoss < 0 || limits.MaxTradesPerMinute < 0 {
+		return nil, errors.New("risk limits must not be negative")
+	}
+	for asset, v := range limits.MaxExposure {
+		if v < 0 {
+			return nil, fmt.Errorf("max exposure of %s must not be negative", asset)
+		}
+	}
+	return &Manager{
+		log:        log.Named("risk"),
+		limits:     limits,
+		now:        time.Now,
+		exposure:   make(map[string]float64),
+		rejections: make(map[Reason]int),
+	}, nil
+}
+
+// Filter пропускает из in в out только допущенные возможности до отмены ctx или закрытия in.
+// Отклонённые логируются с кодом причины.
+func (m *Manager) Filter(ctx context.Context, in <-chan entity.ArbOpportunity, out chan<- entity.ArbOpportunity) error {
+	if out == nil {
+		return errors.New("out must not be nil")
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case opp, ok := <-in:
+			if !ok {
+				return nil
+			}
+			if err := m.Admit(opp); err != nil {
+				m.log.Info("opportunity rejected", "pair", opp.Pair, "buy_on", opp.BuyOn, "sell_on", opp.SellOn, "err", err)
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case out <- opp:
+			}
+		}
+	}
+}
+
+// Admit проверяет возможность по лимитам. Отказ возвращается как *Rejection.
+// Сделкой для ограничения частоты возможность становится только в Record: пропущенная
+// исполнителем (устаревшая, без объёма) лимит не расходует.
+func (m *Manager) Admit(opp entity.ArbOpportunity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.rollDay(now)
+	m.pruneTrades(now)
+
+	if err := m.check(opp); err != nil {
+		var rej *Rejection
+		if errors.As(err, &rej) {
+			m.rejections[rej.Reason]++
+		}
+		return err
+	}
+	return nil
+}
+
+func (m *Manager) check(opp entity.ArbOpportunity) error {
+	l := m.limits
+	if m.killed {
+		return &Rejection{Reason: ReasonKillSwitch, Detail: m.killReason}
+	}
+	if l.MaxDailyLoss > 0 && m.dailyPnl <= -l.MaxDailyLoss {
+		return &Rejection{Reason: ReasonDailyLoss, Detail: fmt.Sprintf("daily pnl %.4f", m.dailyPnl)}
+	}
+	if l.MaxTradesPerMinute > 0 && len(m.trades) >= l.MaxTradesPerMinute {
+		return &Rejection{Reason: ReasonTradeRate, Detail: fmt.Sprintf("%d trades in the last minute", len(m.trades))}
+	}
+	if notional := opp.Size * opp.BuyPrice; l.MaxTradeNotional > 0 && notional > l.MaxTradeNotional {
+		return &Rejection{Reason: ReasonTradeNotional, Detail: fmt.Sprintf("notional %.4f", notional)}
+	}
+	if inst, err := market.ParsePair(opp.Pair); err == nil {
+		if limit, set := l.MaxExposure[inst.Base]; set {
+			if worst := math.Abs(m.exposure[inst.Base]) + opp.Size; worst > limit {
+				return &Rejection{Reason: ReasonExposure, Detail: fmt.Sprintf("%s worst-case exposure %.4f", inst.Base, worst)}
+			}
+		}
+	}
+	return nil
+}
+
+// Record учитывает результат исполнения: сделку для ограничения частоты, реализованный PnL
+// за сутки и открытую позицию по BASE.
+// Исполнение с неизвестным исходом ноги включает аварийную остановку: позицию нужно
+// проверить вручную.
+func (m *Manager) Record(exec entity.Execution) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.rollDay(now)
+	m.pruneTrades(now)
+	m.trades = append(m.trades, now)
+	m.dailyPnl += exec.RealizedPnl
+	if inst, err := market.ParsePair(exec.Opportunity.Pair); err == nil && exec.Unhedged != 0 {
+		m.exposure[inst.Base] += exec.Unhedged
+	}
+	if exec.Status == entity.ExecutionUncertain {
+		m.kill("execution " + exec.ID + " has a leg with unknown outcome")
+	}
+}
+
+// Kill включает аварийную остановку: все возможности отклоняются до Resume.
+func (m *Manager) Kill(reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.kill(reason)
+}
+
+func (m *Manager) kill(reason string) {
+	m.killed, m.killReason = true, reason
+	m.log.Warn("kill switch engaged", "reason", reason)
+}
+
+// Resume снимает аварийную остановку.
+func (m *Manager) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.killed, m.killReason = false, ""
+	m.log.Warn("kill switch released")
+}
+
+// SetExposure задаёт открытую позицию по активу (например, после ручного закрытия).
+func (m *Manager) SetExposure(asset string, amount float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exposure[asset] = amount
+}
+
+// State возвращает снимок состояния.
+func (m *Manager) State() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.rollDay(now)
+	m.pruneTrades(now)
+
+	s := State{
+		Killed:           m.killed,
+		KillReason:       m.killReason,
+		Day:              m.day.Format(time.DateOnly),
+		DailyPnl:         m.dailyPnl,
+		Exposure:         make(map[string]float64, len(m.exposure)),
+		TradesLastMinute: len(m.trades),
+		Rejections:       make(map[Reason]int, len(m.rejections)),
+		Limits:           m.limits,
+	}
+	for k, v := range m.exposure {
+		s.Exposure[k] = v
+	}
+	for k, v := range m.rejections {
+		s.Rejections[k] = v
+	}
+	return s
+}
+
+// rollDay сбрасывает дневной PnL при смене суток (UTC).
+func (m *Manager) rollDay(now time.Time) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	if !day.Equal(m.day) {
+		m.day, m.dailyPnl = day, 0
+	}
+}
+
+// pruneTrades оставляет сделки за последнюю минуту.
+func (m *Manager) pruneTrades(now time.Time) {
+	cut := 0
+	for cut < len(m.trades) && now.Sub(m.trades[cut]) >= time.Minute {
+		cut++
+	}
+	m.trades = m.trades[cut:]
+}
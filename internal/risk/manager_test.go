@@ -0,0 +1,143 @@
+package risk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+func newTestManager(t *testing.T, limits Limits) (*Manager, *time.Time) {
+	t.Helper()
+	m, err := NewManager(logger.New("error"), limits)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+func opp(size float64) entity.ArbOpportunity {
+	return entity.ArbOpportunity{Pair: "SOL/USDT", BuyOn: "mexc", SellOn: "jupiter", BuyPrice: 100, SellPrice: 101, Size: size}
+}
+
+func reasonOf(t *testing.T, err error) Reason {
+	t.Helper()
+	var rej *Rejection
+	if !errors.As(err, &rej) {
+		t.Fatalf("err = %v, want *Rejection", err)
+	}
+	return rej.Reason
+}
+
+func TestManager_Limits(t *testing.T) {
+	m, _ := newTestManager(t, Limits{MaxTradeNotional: 500, MaxExposure: map[string]float64{"SOL": 4}})
+
+	if err := m.Admit(opp(3)); err != nil {
+		t.Fatalf("Admit: %v", err)
+	}
+	if got := reasonOf(t, m.Admit(opp(6))); got != ReasonTradeNotional {
+		t.Errorf("reason = %s, want %s", got, ReasonTradeNotional)
+	}
+
+	// Открытая позиция 2 SOL: новая сделка на 3 SOL в худшем случае даёт 5 > 4.
+	m.Record(entity.Execution{Opportunity: opp(3), Unhedged: -2})
+	if got := reasonOf(t, m.Admit(opp(3))); got != ReasonExposure {
+		t.Errorf("reason = %s, want %s", got, ReasonExposure)
+	}
+	if err := m.Admit(opp(2)); err != nil {
+		t.Errorf("Admit within exposure: %v", err)
+	}
+
+	s := m.State()
+	if s.Exposure["SOL"] != -2 || s.Rejections[ReasonTradeNotional] != 1 || s.Rejections[ReasonExposure] != 1 {
+		t.Errorf("state = %+v", s)
+	}
+}
+
+func TestManager_TradeRate(t *testing.T) {
+	m, now := newTestManager(t, Limits{MaxTradesPerMinute: 2})
+
+	// Допущенная, но не исполненная возможность лимит не расходует.
+	for range 3 {
+		if err := m.Admit(opp(1)); err != nil {
+			t.Fatalf("Admit: %v", err)
+		}
+	}
+	for range 2 {
+		m.Record(entity.Execution{Opportunity: opp(1), Status: entity.ExecutionFilled})
+	}
+	if got := reasonOf(t, m.Admit(opp(1))); got != ReasonTradeRate {
+		t.Errorf("reason = %s, want %s", got, ReasonTradeRate)
+	}
+
+	*now = now.Add(time.Minute)
+	if err := m.Admit(opp(1)); err != nil {
+		t.Errorf("Admit after a minute: %v", err)
+	}
+}
+
+func TestManager_DailyLossResetsNextDay(t *testing.T) {
+	m, now := newTestManager(t, Limits{MaxDailyLoss: 10})
+
+	m.Record(entity.Execution{Opportunity: opp(1), RealizedPnl: -6})
+	if err := m.Admit(opp(1)); err != nil {
+		t.Fatalf("Admit: %v", err)
+	}
+	m.Record(entity.Execution{Opportunity: opp(1), RealizedPnl: -4})
+	if got := reasonOf(t, m.Admit(opp(1))); got != ReasonDailyLoss {
+		t.Errorf("reason = %s, want %s", got, ReasonDailyLoss)
+	}
+
+	*now = now.Add(12 * time.Hour)
+	if err := m.Admit(opp(1)); err != nil {
+		t.Errorf("Admit next day: %v", err)
+	}
+	if s := m.State(); s.DailyPnl != 0 || s.Day != "2026-01-02" {
+		t.Errorf("state = %+v", s)
+	}
+}
+
+func TestManager_KillSwitch(t *testing.T) {
+	m, _ := newTestManager(t, Limits{})
+
+	m.Kill("manual")
+	if got := reasonOf(t, m.Admit(opp(1))); got != ReasonKillSwitch {
+		t.Errorf("reason = %s, want %s", got, ReasonKillSwitch)
+	}
+	if s := m.State(); !s.Killed || s.KillReason != "manual" {
+		t.Errorf("state = %+v", s)
+	}
+
+	m.Resume()
+	if err := m.Admit(opp(1)); err != nil {
+		t.Errorf("Admit after resume: %v", err)
+	}
+}
+
+func TestManager_UncertainExecutionEngagesKillSwitch(t *testing.T) {
+	m, _ := newTestManager(t, Limits{})
+
+	m.Record(entity.Execution{ID: "x1", Opportunity: opp(1), Status: entity.ExecutionUncertain})
+	if got := reasonOf(t, m.Admit(opp(1))); got != ReasonKillSwitch {
+		t.Errorf("reason = %s, want %s", got, ReasonKillSwitch)
+	}
+	if s := m.State(); !s.Killed || s.KillReason == "" {
+		t.Errorf("state = %+v", s)
+	}
+}
+
+func TestNewManager_RejectsNegativeLimits(t *testing.T) {
+	for name, l := range map[string]Limits{
+		"notional": {MaxTradeNotional: -1},
+		"exposure": {MaxExposure: map[string]float64{"SOL": -1}},
+		"rate":     {MaxTradesPerMinute: -1},
+	} {
+		if _, err := NewManager(logger.New("error"), l); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
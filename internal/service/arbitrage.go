@@ -8,44 +8,69 @@ import (
 	"sync"
 	"time"
 
+	"github.com/dimryb/cross-arb/internal/adapter/mexc"
 	"github.com/dimryb/cross-arb/internal/api/jupiter"
 	spotlist "github.com/dimryb/cross-arb/internal/api/mexc/spot"
 	"github.com/dimryb/cross-arb/internal/api/mexc/utils"
+	mexcws "github.com/dimryb/cross-arb/internal/api/mexc/ws"
 	"github.com/dimryb/cross-arb/internal/config"
 	"github.com/dimryb/cross-arb/internal/entity"
 	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/market"
+	"github.com/dimryb/cross-arb/internal/orderbook"
 	"github.com/dimryb/cross-arb/internal/report"
 )
 
 const (
 	mexcExchange = "mexc"
 	jupExchange  = "jupiter"
+
+	// snapshotDepth — глубина REST-снапшота, на который накладываются диффы.
+	snapshotDepth = 1000
 )
 
 type Arbitrage struct {
-	ctx   context.Context
-	app   i.Application
-	log   i.Logger
-	cfg   *config.CrossArbConfig
-	store i.TickerStore
+	ctx     context.Context
+	app     i.Application
+	log     i.Logger
+	cfg     *config.CrossArbConfig
+	store   i.TickerStore
+	markets *market.Registry
+	tokens  *jupiter.TokenRegistry
 }
 
+// NewArbitrageService создаёт сервис. Отслеживаются инструменты MEXC из реестра markets,
+// mint-адреса и decimals токенов для котировок Jupiter берутся из tokens.
 func NewArbitrageService(
 	ctx context.Context,
 	app i.Application,
 	logger i.Logger,
 	cfg *config.CrossArbConfig,
 	store i.TickerStore,
+	markets *market.Registry,
+	tokens *jupiter.TokenRegistry,
 ) *Arbitrage {
 	return &Arbitrage{
-		ctx:   ctx,
-		app:   app,
-		log:   logger,
-		cfg:   cfg,
-		store: store,
+		ctx:     ctx,
+		app:     app,
+		log:     logger,
+		cfg:     cfg,
+		store:   store,
+		markets: markets,
+		tokens:  tokens,
 	}
 }
 
+// symbols возвращает символы MEXC отслеживаемых инструментов.
+func (m *Arbitrage) symbols() []string {
+	listings := m.markets.Listings(mexcExchange)
+	symbols := make([]string, 0, len(listings))
+	for _, l := range listings {
+		symbols = append(symbols, l.Symbol)
+	}
+	return symbols
+}
+
 func (m *Arbitrage) Run() error {
 	wg := &sync.WaitGroup{}
 
@@ -66,9 +91,10 @@ func (m *Arbitrage) Run() error {
 			case <-m.ctx.Done():
 				return
 			case <-ticker.C:
-				results := make([]entity.Result, len(m.cfg.Symbols))
+				symbols := m.symbols()
+				results := make([]entity.Result, len(symbols))
 				wgSymbols := &sync.WaitGroup{}
-				for ind, symbol := range m.cfg.Symbols {
+				for ind, symbol := range symbols {
 					wgSymbols.Add(1)
 					go func() {
 						defer wgSymbols.Done()
@@ -135,15 +161,16 @@ func (m *Arbitrage) runJupiterClient(wg *sync.WaitGroup) error {
 			case <-m.ctx.Done():
 				return
 			case <-ticker.C:
-				results := make([]entity.Result, len(m.cfg.Symbols))
+				listings := m.markets.Listings(mexcExchange)
+				results := make([]entity.Result, len(listings))
 				wgSymbols := &sync.WaitGroup{}
 
-				for ind, symbol := range m.cfg.Symbols {
+				for ind, l := range listings {
 					wgSymbols.Add(1)
 					go func() {
 						defer wgSymbols.Done()
-						bookTicker, err := getJupiterTicker(jupClient, symbol)
-						processTickerResult(results, ind, symbol, bookTicker, err)
+						bookTicker, err := getJupiterTicker(jupClient, m.tokens, l)
+						processTickerResult(results, ind, l.Symbol, bookTicker, err)
 					}()
 				}
 
@@ -156,24 +183,23 @@ func (m *Arbitrage) runJupiterClient(wg *sync.WaitGroup) error {
 	return nil
 }
 
-// getJupiterTicker запрашивает котировку Jupiter и преобразует её в BookTicker.
-func getJupiterTicker(jc *jupiter.Client, symbol string) (entity.BookTicker, error) {
-	inMint, outMint, err := jupiter.ConvertSpotToMints(symbol)
+// getJupiterTicker запрашивает котировку Jupiter для инструмента листинга MEXC
+// и преобразует её в BookTicker с символом MEXC.
+func getJupiterTicker(jc *jupiter.Client, tokens *jupiter.TokenRegistry, l market.Listing) (entity.BookTicker, error) {
+	inMint, outMint, err := tokens.Mints(l.Pair())
 	if err != nil {
-		return entity.BookTicker{}, fmt.Errorf("unsupported symbol format %q", symbol)
+		return entity.BookTicker{}, fmt.Errorf("unsupported pair %q: %w", l.Pair(), err)
 	}
 
-	base, quote, err := jupiter.ParseSpotSymbol(symbol)
-	if err != nil {
-		return entity.BookTicker{}, err
-	}
+	base, quote := l.Instrument.Base, l.Instrument.Quote
+	symbol := l.Symbol
 
 	// Получаем единичные количества для нормализации
-	baseUnit, err := jupiter.UnitAmount(base)
+	baseUnit, err := tokens.UnitAmount(base)
 	if err != nil {
 		return entity.BookTicker{}, fmt.Errorf("failed to get unit amount for %s: %w", base, err)
 	}
-	quoteUnit, err := jupiter.UnitAmount(quote)
+	quoteUnit, err := tokens.UnitAmount(quote)
 	if err != nil {
 		return entity.BookTicker{}, fmt.Errorf("failed to get unit amount for %s: %w", quote, err)
 	}
@@ -255,6 +281,8 @@ func bookMexcTicker(sc *spotlist.SpotClient, symbol string) (entity.BookTicker,
 	return tickerData, nil
 }
 
+// runMexcOrderBook поддерживает локальные стаканы MEXC (REST-снапшот + WebSocket-диффы)
+// и пересчитывает лучшие цены по каждому обновлению стакана вместо периодического опроса.
 func (m *Arbitrage) runMexcOrderBook(wg *sync.WaitGroup) {
 	mexcCfg, ok := m.cfg.Exchanges[mexcExchange]
 	if !ok || !mexcCfg.Enabled {
@@ -262,38 +290,99 @@ func (m *Arbitrage) runMexcOrderBook(wg *sync.WaitGroup) {
 		return
 	}
 
-	client := utils.NewClient(mexcCfg.APIKey, mexcCfg.SecretKey, m.log)
-	spot := spotlist.NewSpotClient(m.log, mexcCfg.BaseURL, client)
+	adapter := mexc.NewAdapter(m.log, 3*time.Second)
+
+	adapter.SetMarkets(m.markets)
+	symbols := m.symbols()
+	// Уведомления об обновлениях сливаются: достаточно знать, что символ изменился.
+	updates := newDirtySymbols()
+	wsClient, err := mexcws.NewClient(m.log, mexcws.Config{
+		URL:     mexcCfg.WSURL,
+		Symbols: symbols,
+		Snapshot: func(ctx context.Context, symbol string) (orderbook.Snapshot, error) {
+			return adapter.DepthSnapshot(ctx, symbol, snapshotDepth)
+		},
+		OnOrderBookUpdate: updates.mark,
+	})
+	if err != nil {
+		m.log.Errorf("failed to create MEXC ws client: %v", err)
+		return
+	}
 
-	wg.Add(1)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_ = wsClient.Run(m.ctx)
+	}()
 	go func() {
 		defer wg.Done()
-		ticker := time.NewTicker(time.Second)
-		defer ticker.Stop()
-
 		for {
 			select {
 			case <-m.ctx.Done():
 				return
-			case <-ticker.C:
-				results := make([]entity.OrderBookResult, len(m.cfg.Symbols))
-				wgSymbols := &sync.WaitGroup{}
-				for ind, symbol := range m.cfg.Symbols {
-					wgSymbols.Add(1)
-					go func(index int, sym string) {
-						defer wgSymbols.Done()
-						getMexcOrder(spot, results, index, sym, mexcCfg.OrderLimit)
-					}(ind, symbol)
+			case <-updates.wake:
+				for _, symbol := range updates.drain() {
+					result := entity.OrderBookResult{Symbol: symbol, Exchange: mexcExchange, Timestamp: time.Now()}
+					book, ok := wsClient.OrderBook(symbol, mexcCfg.OrderLimit)
+					if !ok {
+						result.Error = fmt.Errorf("%s: %w", symbol, orderbook.ErrNotSynced)
+					}
+					result.Data = book
+
+					results := []entity.OrderBookResult{result}
+					// Отчёт ожидает пару в записи "SOL/USDT".
+					if l, ok := m.markets.Lookup(mexcExchange, symbol); ok {
+						pairResult := result
+						pairResult.Symbol = l.Pair()
+						report.PrintOrderBookReport([]entity.OrderBookResult{pairResult})
+					}
+					m.findBestOrder(results, mexcExchange, mexcCfg.MaxPriceDiff, mexcCfg.MinQtyImprovement)
 				}
-				wgSymbols.Wait()
-
-				report.PrintOrderBookReport(results)
-				m.findBestOrder(results, mexcExchange, mexcCfg.MaxPriceDiff, mexcCfg.MinQtyImprovement)
 			}
 		}
 	}()
 }
 
+// dirtySymbols — множество символов с необработанными обновлениями стакана. Повторные
+// обновления символа сливаются, ни одно не теряется; wake будит обработчик.
+type dirtySymbols struct {
+	mu      sync.Mutex
+	symbols map[string]struct{}
+	wake    chan struct{}
+}
+
+func newDirtySymbols() *dirtySymbols {
+	return &dirtySymbols{
+		symbols: make(map[string]struct{}),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// mark отмечает обновление символа. Никогда не блокируется.
+func (d *dirtySymbols) mark(symbol string) {
+	d.mu.Lock()
+	d.symbols[symbol] = struct{}{}
+	d.mu.Unlock()
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// drain возвращает отмеченные символы и очищает множество.
+func (d *dirtySymbols) drain() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := make([]string, 0, len(d.symbols))
+	for symbol := range d.symbols {
+		out = append(out, symbol)
+	}
+	clear(d.symbols)
+	return out
+}
+
 func (m *Arbitrage) findBestOrder(
 	results []entity.OrderBookResult,
 	exchange string,
@@ -305,6 +394,9 @@ func (m *Arbitrage) findBestOrder(
 			fmt.Printf("  [%s] Error: %v\n", r.Symbol, r.Error)
 			continue
 		}
+		if len(r.Data.Bids) == 0 || len(r.Data.Asks) == 0 {
+			continue
+		}
 
 		var bestBidPrice, bestBidQty float64
 		topBidPrice := r.Data.Bids[0].Price
@@ -356,68 +448,6 @@ func (m *Arbitrage) findBestOrder(
 	}
 }
 
-func getMexcOrder(sc *spotlist.SpotClient, results []entity.OrderBookResult, index int, symbol string, limit int) {
-	book, err := bookMexcOrder(sc, symbol, limit)
-	processOrderResult(results, index, symbol, book, err)
-}
-
-func processOrderResult(results []entity.OrderBookResult, index int, symbol string, book entity.OrderBook, err error) {
-	if err != nil {
-		results[index] = entity.OrderBookResult{
-			Symbol: symbol,
-			Data:   entity.OrderBook{},
-			Error:  err,
-		}
-	} else {
-		results[index] = entity.OrderBookResult{
-			Symbol: symbol,
-			Data:   book,
-			Error:  nil,
-		}
-	}
-}
-
-func bookMexcOrder(sc *spotlist.SpotClient, symbol string, limit int) (entity.OrderBook, error) {
-	params := fmt.Sprintf(`{"symbol":"%s", "limit":"%d"}`, symbol, limit)
-	resp, err := sc.Depth(params)
-	if err != nil {
-		return entity.OrderBook{}, fmt.Errorf("MEXC Depth request failed: %w", err)
-	}
-
-	var raw struct {
-		Bids [][]string `json:"bids"`
-		Asks [][]string `json:"asks"`
-	}
-	err = json.Unmarshal(resp.Body(), &raw)
-	if err != nil {
-		return entity.OrderBook{}, fmt.Errorf("failed to parse MEXC Depth JSON: %w", err)
-	}
-
-	var bids, asks []entity.Order
-	for _, item := range raw.Bids {
-		if len(item) != 2 {
-			continue
-		}
-		price := parseFloat(item[0])
-		qty := parseFloat(item[1])
-		if price > 0 && qty > 0 {
-			bids = append(bids, entity.Order{Price: price, Quantity: qty})
-		}
-	}
-	for _, item := range raw.Asks {
-		if len(item) != 2 {
-			continue
-		}
-		price := parseFloat(item[0])
-		qty := parseFloat(item[1])
-		if price > 0 && qty > 0 {
-			asks = append(asks, entity.Order{Price: price, Quantity: qty})
-		}
-	}
-
-	return entity.OrderBook{Bids: bids, Asks: asks}, nil
-}
-
 func parseFloat(s string) float64 {
 	f, _ := strconv.ParseFloat(s, 64)
 	return f
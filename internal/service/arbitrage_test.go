@@ -0,0 +1,33 @@
+package service
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDirtySymbols_CoalescesWithoutLosingSymbols(t *testing.T) {
+	d := newDirtySymbols()
+	// Обработчик занят: обновлений больше, чем символов, ни одно не теряется.
+	for range 3 {
+		for _, s := range []string{"SOLUSDT", "BTCUSDT", "ETHUSDT"} {
+			d.mark(s)
+		}
+	}
+
+	<-d.wake
+	got := d.drain()
+	slices.Sort(got)
+	if want := []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}; !slices.Equal(got, want) {
+		t.Errorf("drain = %v, want %v", got, want)
+	}
+	if got := d.drain(); len(got) != 0 {
+		t.Errorf("second drain = %v, want empty", got)
+	}
+
+	d.mark("SOLUSDT")
+	select {
+	case <-d.wake:
+	default:
+		t.Fatal("mark after drain must wake the handler")
+	}
+}
@@ -0,0 +1,105 @@
+package balance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dimryb/cross-arb/internal/api/mexc/trade"
+	"github.com/gagliardetto/solana-go"
+)
+
+// AccountReader — чтение спотового счёта MEXC (trade.Client).
+type AccountReader interface {
+	Account(ctx context.Context) (trade.Account, error)
+}
+
+// MexcSource — свободные остатки спотового счёта MEXC. Заблокированные в ордерах
+// средства не учитываются.
+type MexcSource struct {
+	venue  string
+	reader AccountReader
+}
+
+// NewMexcSource создаёт источник для биржи venue.
+func NewMexcSource(venue string, reader AccountReader) *MexcSource {
+	return &MexcSource{venue: venue, reader: reader}
+}
+
+// Venue удовлетворяет интерфейсу Source.
+func (m *MexcSource) Venue() string { return m.venue }
+
+// Balances удовлетворяет интерфейсу Source.
+func (m *MexcSource) Balances(ctx context.Context) (map[string]float64, error) {
+	acc, err := m.reader.Account(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]float64, len(acc.Balances))
+	for _, b := range acc.Balances {
+		out[b.Asset] = float64(b.Free)
+	}
+	return out, nil
+}
+
+// WalletReader — балансы кошелька Solana (solana.Client).
+type WalletReader interface {
+	GetBalance(ctx context.Context, account solana.PublicKey) (uint64, error)
+	TokenBalances(ctx context.Context, owner solana.PublicKey) (map[string]float64, error)
+}
+
+// SolanaSource — остатки кошелька Solana: нативный SOL и SPL-токены из токен-аккаунтов.
+// Активы называются по mints (актив → mint); токены с другими mint не учитываются.
+// Нативный SOL учитывается под именем nativeAsset за вычетом reserve на комиссии сети.
+type SolanaSource struct {
+	venue       string
+	wallet      WalletReader
+	owner       solana.PublicKey
+	mints       map[string]string
+	nativeAsset string
+	reserve     float64
+}
+
+// NewSolanaSource создаёт источник для биржи venue (например, "jupiter").
+func NewSolanaSource(
+	venue string,
+	wallet WalletReader,
+	owner solana.PublicKey,
+	mints map[string]string,
+	nativeAsset string,
+	reserve float64,
+) *SolanaSource {
+	return &SolanaSource{
+		venue:       venue,
+		wallet:      wallet,
+		owner:       owner,
+		mints:       mints,
+		nativeAsset: nativeAsset,
+		reserve:     reserve,
+	}
+}
+
+// Venue удовлетворяет интерфейсу Source.
+func (s *SolanaSource) Venue() string { return s.venue }
+
+// Balances удовлетворяет интерфейсу Source.
+func (s *SolanaSource) Balances(ctx context.Context) (map[string]float64, error) {
+	lamports, err := s.wallet.GetBalance(ctx, s.owner)
+	if err != nil {
+		return nil, fmt.Errorf("sol balance: %w", err)
+	}
+	tokens, err := s.wallet.TokenBalances(ctx, s.owner)
+	if err != nil {
+		return nil, fmt.Errorf("token balances: %w", err)
+	}
+
+	out := make(map[string]float64, len(s.mints)+1)
+	for asset, mint := range s.mints {
+		out[asset] = tokens[mint]
+	}
+	if s.nativeAsset != "" {
+		native := float64(lamports)/float64(solana.LAMPORTS_PER_SOL) - s.reserve
+		// Wrapped SOL на токен-аккаунте складывается с нативным.
+		out[s.nativeAsset] += max(native, 0)
+	}
+	return out, nil
+}
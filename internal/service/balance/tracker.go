@@ -0,0 +1,158 @@
+package balance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+const defaultInterval = 10 * time.Second
+
+// Source загружает доступные остатки одной биржи: актив → количество.
+type Source interface {
+	Venue() string
+	Balances(ctx context.Context) (map[string]float64, error)
+}
+
+// Options — параметры трекера.
+type Options struct {
+	// Interval — период обновления балансов. При 0 — 10 сек.
+	Interval time.Duration
+	// Headroom — доля остатка, не используемая для сделок (запас на комиссии и движение цены).
+	Headroom float64
+}
+
+// Tracker периодически загружает остатки со всех источников и отдаёт единое
+// представление entity.Balances. По нему ограничивается объём сделок.
+//
+// Ошибка источника не сбрасывает его последние известные остатки; биржа, по которой
+// остатки ещё ни разу не загружены, считается пустой.
+type Tracker struct {
+	log     i.Logger
+	sources []Source
+	opts    Options
+
+	mu       sync.RWMutex
+	balances entity.Balances
+	updated  map[string]time.Time
+}
+
+// NewTracker создаёт трекер. Не запускает горутины.
+func NewTracker(log i.Logger, sources []Source, opts Options) (*Tracker, error) {
+	if len(sources) == 0 {
+		return nil, errors.New("no balance sources provided")
+	}
+	if opts.Headroom < 0 || opts.Headroom >= 1 {
+		return nil, fmt.Errorf("headroom must be in [0, 1), got %v", opts.Headroom)
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultInterval
+	}
+
+	balances := make(entity.Balances, len(sources))
+	for _, s := range sources {
+		balances[s.Venue()] = map[string]float64{}
+	}
+	return &Tracker{
+		log:      log.Named("balance"),
+		sources:  sources,
+		opts:     opts,
+		balances: balances,
+		updated:  make(map[string]time.Time, len(sources)),
+	}, nil
+}
+
+// Run обновляет балансы сразу и затем с периодом Interval до отмены ctx.
+func (t *Tracker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(t.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.Refresh(ctx); err != nil && ctx.Err() == nil {
+			t.log.Warn("balance refresh failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh загружает остатки со всех источников параллельно.
+// Возвращает объединённую ошибку источников, которые не удалось опросить.
+func (t *Tracker) Refresh(ctx context.Context) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, s := range t.sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assets, err := s.Balances(ctx)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", s.Venue(), err))
+				mu.Unlock()
+				return
+			}
+			t.mu.Lock()
+			t.balances[s.Venue()] = assets
+			t.updated[s.Venue()] = time.Now()
+			t.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Balances возвращает копию текущих остатков.
+func (t *Tracker) Balances() entity.Balances {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	out := make(entity.Balances, len(t.balances))
+	for venue, assets := range t.balances {
+		m := make(map[string]float64, len(assets))
+		for asset, amount := range assets {
+			m[asset] = amount
+		}
+		out[venue] = m
+	}
+	return out
+}
+
+// Cap возвращает максимальный объём BASE, который позволяют остатки для возможности:
+// QUOTE на бирже покупки (по цене BuyPrice) и BASE на бирже продажи, за вычетом Headroom.
+// Биржи без источника объём не ограничивают.
+func (t *Tracker) Cap(opp entity.ArbOpportunity) float64 {
+	inst, err := market.ParsePair(opp.Pair)
+	if err != nil {
+		return 0
+	}
+	base, quote := inst.Base, inst.Quote
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	limit := math.Inf(1)
+	if amount, tracked := t.balances.Get(opp.BuyOn, quote); tracked {
+		if opp.BuyPrice <= 0 {
+			return 0
+		}
+		limit = min(limit, amount/opp.BuyPrice)
+	}
+	if amount, tracked := t.balances.Get(opp.SellOn, base); tracked {
+		limit = min(limit, amount)
+	}
+	return max(limit*(1-t.opts.Headroom), 0)
+}
@@ -0,0 +1,79 @@
+package balance
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+type fakeSource struct {
+	venue    string
+	balances map[string]float64
+	err      error
+}
+
+func (f *fakeSource) Venue() string { return f.venue }
+
+func (f *fakeSource) Balances(context.Context) (map[string]float64, error) {
+	return f.balances, f.err
+}
+
+func TestTracker_Cap(t *testing.T) {
+	mexc := &fakeSource{venue: "mexc", balances: map[string]float64{"USDT": 500, "SOL": 1}}
+	jup := &fakeSource{venue: "jupiter", balances: map[string]float64{"USDT": 50, "SOL": 3}}
+	tr, err := NewTracker(logger.New("error"), []Source{mexc, jup}, Options{Headroom: 0.1})
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+
+	opp := entity.ArbOpportunity{Pair: "SOL/USDT", BuyOn: "mexc", BuyPrice: 100, SellOn: "jupiter"}
+	if got := tr.Cap(opp); got != 0 {
+		t.Errorf("cap before refresh = %v, want 0", got)
+	}
+
+	if err := tr.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	// min(500/100 USDT на mexc, 3 SOL на jupiter) за вычетом 10%.
+	if got := tr.Cap(opp); math.Abs(got-2.7) > 1e-9 {
+		t.Errorf("cap = %v, want 2.7", got)
+	}
+	// Пара в другой записи разбирается так же.
+	if alt := (entity.ArbOpportunity{Pair: "sol-usdt", BuyOn: "mexc", BuyPrice: 100, SellOn: "jupiter"}); math.Abs(tr.Cap(alt)-2.7) > 1e-9 {
+		t.Errorf("cap for %q = %v, want 2.7", alt.Pair, tr.Cap(alt))
+	}
+	// Обратное направление: 50/100 USDT на jupiter.
+	opp.BuyOn, opp.SellOn = "jupiter", "mexc"
+	if got := tr.Cap(opp); math.Abs(got-0.45) > 1e-9 {
+		t.Errorf("reverse cap = %v, want 0.45", got)
+	}
+	// Биржи без источника объём не ограничивают.
+	opp.BuyOn = "binance"
+	if got := tr.Cap(opp); math.Abs(got-0.9) > 1e-9 {
+		t.Errorf("untracked cap = %v, want 0.9", got)
+	}
+}
+
+func TestTracker_RefreshKeepsLastKnown(t *testing.T) {
+	src := &fakeSource{venue: "mexc", balances: map[string]float64{"USDT": 100}}
+	tr, err := NewTracker(logger.New("error"), []Source{src}, Options{})
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	if err := tr.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	boom := errors.New("boom")
+	src.balances, src.err = nil, boom
+	if err := tr.Refresh(context.Background()); !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want boom", err)
+	}
+	if amount, _ := tr.Balances().Get("mexc", "USDT"); amount != 100 {
+		t.Errorf("USDT = %v, want last known 100", amount)
+	}
+}
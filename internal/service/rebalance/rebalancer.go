@@ -0,0 +1,496 @@
+package rebalance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/api/mexc/trade"
+	"github.com/dimryb/cross-arb/internal/api/mexc/utils"
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+const (
+	defaultInterval       = time.Minute
+	defaultPendingTimeout = 2 * time.Hour
+
+	// matchWindow — перевод без идентификатора (исход отправки неизвестен), не найденный
+	// за это время в истории CEX, считается не отправленным.
+	matchWindow = 10 * time.Minute
+	// clockSkew — допуск при сопоставлении времени перевода со временем записи CEX.
+	clockSkew = time.Minute
+)
+
+// BalanceView — текущие остатки по биржам (balance.Tracker).
+type BalanceView interface {
+	Balances() entity.Balances
+}
+
+// Exchange — эндпоинты вывода и пополнения CEX (trade.Client).
+type Exchange interface {
+	Withdraw(ctx context.Context, req trade.WithdrawRequest) (string, error)
+	WithdrawHistory(ctx context.Context, coin string) ([]trade.Withdrawal, error)
+	DepositAddress(ctx context.Context, coin, network string) (trade.DepositAddress, error)
+	DepositHistory(ctx context.Context, coin string) ([]trade.Deposit, error)
+}
+
+// WalletSender отправляет актив с кошелька на адрес и возвращает хэш транзакции.
+// Если транзакция могла уйти в сеть, но не подтверждена, хэш возвращается вместе с ошибкой.
+type WalletSender interface {
+	Send(ctx context.Context, asset, to string, amount float64) (string, error)
+}
+
+// TransferStore хранит переводы в пути, чтобы они пережили перезапуск (repository.Journal).
+type TransferStore interface {
+	// SaveTransfer сохраняет перевод в статусе TransferPending и удаляет завершённый.
+	SaveTransfer(t entity.Transfer) error
+	// PendingTransfers возвращает сохранённые переводы в пути.
+	PendingTransfers() ([]entity.Transfer, error)
+}
+
+// Asset — целевое распределение актива между CEX и кошельком.
+type Asset struct {
+	// Target — целевая доля остатка на CEX, [0, 1].
+	Target float64
+	// MinAmount — переводы меньше этого объёма не выполняются (комиссии сети и вывода).
+	MinAmount float64
+	// Network — сеть перевода в терминах MEXC (например, "SOL").
+	Network string
+}
+
+// Options — параметры ребалансировщика.
+type Options struct {
+	// CEX и Wallet — имена бирж (как в EXAdapter.Name), между которыми переводятся активы.
+	CEX    string
+	Wallet string
+	// WalletAddress — адрес кошелька для вывода с CEX.
+	WalletAddress string
+	// Assets — отслеживаемые активы.
+	Assets map[string]Asset
+	// Threshold — допустимое отклонение доли на CEX от целевой, после которого нужен перевод.
+	Threshold float64
+	// Execute — отправлять переводы; иначе они только предлагаются в лог.
+	Execute bool
+	// Interval — период проверки. При 0 — 1 мин.
+	Interval time.Duration
+	// PendingTimeout — перевод, не зачисленный за это время, считается неудачным. При 0 — 2 ч.
+	PendingTimeout time.Duration
+	// Store — хранилище переводов в пути. Необязательно: без него после перезапуска
+	// переводы восстанавливаются только по истории CEX.
+	Store TransferStore
+}
+
+// Rebalancer следит за перекосом остатков между CEX и кошельком и возвращает их
+// к целевым долям переводами: с CEX — выводом на кошелёк, с кошелька — отправкой
+// на адрес пополнения CEX.
+//
+// Пока перевод не зачислен, приостанавливается направление торговли, которое
+// расходует переводимый актив на бирже назначения (то есть продолжает перекос):
+// для BASE — продажа на ней, для QUOTE — покупка на ней.
+//
+// Перевод учитывается в пути (и сохраняется в Options.Store) до отправки. Если исход
+// отправки неизвестен (ответ не получен, транзакция не подтверждена), перевод остаётся
+// в пути и сверяется с историей CEX, поэтому повторно не отправляется. При запуске
+// Restore восстанавливает переводы в пути из хранилища и истории CEX.
+type Rebalancer struct {
+	log      i.Logger
+	balances BalanceView
+	exchange Exchange
+	sender   WalletSender
+	opts     Options
+	now      func() time.Time
+
+	mu       sync.RWMutex
+	inFlight map[string]entity.Transfer // по активу; не больше одного перевода на актив
+	// settled — время завершения последнего перевода по активу. Такой актив пропускает
+	// один цикл, чтобы трекер успел обновить остатки после зачисления.
+	settled map[string]time.Time
+}
+
+// NewRebalancer создаёт ребалансировщик. Не запускает горутины.
+func NewRebalancer(
+	log i.Logger,
+	balances BalanceView,
+	exchange Exchange,
+	sender WalletSender,
+	opts Options,
+) (*Rebalancer, error) {
+	if opts.CEX == "" || opts.Wallet == "" {
+		return nil, errors.New("cex and wallet venues must be set")
+	}
+	if len(opts.Assets) == 0 {
+		return nil, errors.New("no assets to rebalance")
+	}
+	for asset, a := range opts.Assets {
+		if a.Target < 0 || a.Target > 1 {
+			return nil, fmt.Errorf("%s target must be in [0, 1], got %v", asset, a.Target)
+		}
+	}
+	if opts.Threshold <= 0 || opts.Threshold >= 1 {
+		return nil, fmt.Errorf("threshold must be in (0, 1), got %v", opts.Threshold)
+	}
+	if opts.Execute && opts.WalletAddress == "" {
+		return nil, errors.New("wallet address is required to execute transfers")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultInterval
+	}
+	if opts.PendingTimeout <= 0 {
+		opts.PendingTimeout = defaultPendingTimeout
+	}
+	return &Rebalancer{
+		log:      log.Named("rebalance"),
+		balances: balances,
+		exchange: exchange,
+		sender:   sender,
+		opts:     opts,
+		now:      time.Now,
+		inFlight: make(map[string]entity.Transfer),
+		settled:  make(map[string]time.Time),
+	}, nil
+}
+
+// Run восстанавливает переводы в пути (Restore) и выполняет Step с периодом Interval
+// до отмены ctx.
+func (r *Rebalancer) Run(ctx context.Context) error {
+	if err := r.Restore(ctx); err != nil {
+		r.log.Error("failed to restore transfers in flight", "err", err)
+	}
+
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			r.Step(ctx)
+		}
+	}
+}
+
+// Step обновляет статусы переводов в пути и запускает новые по активам без них.
+func (r *Rebalancer) Step(ctx context.Context) {
+	for _, t := range r.Pending() {
+		updated, err := r.track(ctx, t)
+		if err != nil {
+			r.log.Warn("transfer status check failed", "asset", t.Asset, "id", t.ID, "err", err)
+			continue
+		}
+		if updated.Status == entity.TransferPending {
+			r.hold(updated)
+			continue
+		}
+		r.log.Info("transfer finished", "asset", t.Asset, "from", t.From, "to", t.To,
+			"amount", t.Amount, "status", updated.Status)
+		r.release(updated)
+		r.mu.Lock()
+		r.settled[t.Asset] = r.now()
+		r.mu.Unlock()
+	}
+
+	for _, t := range r.Plan(r.balances.Balances()) {
+		r.mu.RLock()
+		_, busy := r.inFlight[t.Asset]
+		settled, ok := r.settled[t.Asset]
+		r.mu.RUnlock()
+		if busy || (ok && r.now().Sub(settled) < r.opts.Interval) {
+			continue
+		}
+		if !r.opts.Execute {
+			r.log.Info("transfer proposed", "asset", t.Asset, "from", t.From, "to", t.To, "amount", t.Amount)
+			continue
+		}
+
+		// Перевод учитывается в пути до отправки: сбой после неё не приведёт к повтору.
+		t.Status = entity.TransferPending
+		r.hold(t)
+		sent, maybeSent, err := r.send(ctx, t)
+		switch {
+		case err == nil:
+			r.log.Info("transfer sent", "asset", t.Asset, "from", t.From, "to", t.To, "amount", t.Amount, "id", sent.ID)
+			r.hold(sent)
+		case maybeSent:
+			r.log.Error("transfer outcome unknown, tracking as pending", "asset", t.Asset, "from", t.From,
+				"to", t.To, "amount", t.Amount, "id", sent.ID, "err", err)
+			r.hold(sent)
+		default:
+			r.log.Error("transfer failed", "asset", t.Asset, "from", t.From, "to", t.To, "amount", t.Amount, "err", err)
+			sent.Status = entity.TransferFailed
+			r.release(sent)
+		}
+	}
+}
+
+// Restore восстанавливает переводы в пути после перезапуска: из Options.Store и из истории
+// CEX — незавершённые выводы на WalletAddress и незачисленные депозиты отслеживаемых
+// активов не старше PendingTimeout. Депозит с другого адреса тоже считается переводом
+// в пути: лишнее ожидание безопаснее повторной отправки.
+func (r *Rebalancer) Restore(ctx context.Context) error {
+	if r.opts.Store != nil {
+		saved, err := r.opts.Store.PendingTransfers()
+		if err != nil {
+			return fmt.Errorf("load pending transfers: %w", err)
+		}
+		r.mu.Lock()
+		for _, t := range saved {
+			if _, ok := r.opts.Assets[t.Asset]; ok {
+				r.inFlight[t.Asset] = t
+			}
+		}
+		r.mu.Unlock()
+	}
+
+	assets := make([]string, 0, len(r.opts.Assets))
+	for asset := range r.opts.Assets {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+
+	var errs []error
+	for _, asset := range assets {
+		r.mu.RLock()
+		_, busy := r.inFlight[asset]
+		r.mu.RUnlock()
+		if busy {
+			continue
+		}
+		t, found, err := r.discover(ctx, asset)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", asset, err))
+			continue
+		}
+		if found {
+			r.log.Warn("transfer in flight restored from exchange history", "asset", asset,
+				"from", t.From, "to", t.To, "amount", t.Amount, "id", t.ID)
+			r.hold(t)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Plan возвращает переводы, возвращающие доли активов на CEX к целевым.
+// Переводы упорядочены по активу.
+func (r *Rebalancer) Plan(balances entity.Balances) []entity.Transfer {
+	now := r.now()
+	var plan []entity.Transfer
+	for asset, a := range r.opts.Assets {
+		cex, _ := balances.Get(r.opts.CEX, asset)
+		wallet, _ := balances.Get(r.opts.Wallet, asset)
+		total := cex + wallet
+		if total <= 0 {
+			continue
+		}
+		deviation := cex/total - a.Target
+		if math.Abs(deviation) <= r.opts.Threshold {
+			continue
+		}
+		amount := math.Abs(deviation) * total
+		if amount < a.MinAmount {
+			continue
+		}
+
+		t := entity.Transfer{Asset: asset, Amount: amount, Status: entity.TransferProposed, CreatedAt: now, UpdatedAt: now}
+		t.From, t.To = r.opts.CEX, r.opts.Wallet
+		if deviation < 0 {
+			t.From, t.To = r.opts.Wallet, r.opts.CEX
+		}
+		plan = append(plan, t)
+	}
+	sort.Slice(plan, func(a, b int) bool { return plan[a].Asset < plan[b].Asset })
+	return plan
+}
+
+// Pending возвращает переводы в пути.
+func (r *Rebalancer) Pending() []entity.Transfer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]entity.Transfer, 0, len(r.inFlight))
+	for _, t := range r.inFlight {
+		out = append(out, t)
+	}
+	return out
+}
+
+// Paused сообщает, приостановлено ли направление возможности до зачисления перевода.
+func (r *Rebalancer) Paused(opp entity.ArbOpportunity) bool {
+	inst, err := market.ParsePair(opp.Pair)
+	if err != nil {
+		return false
+	}
+	base, quote := inst.Base, inst.Quote
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if t, ok := r.inFlight[base]; ok && t.To == opp.SellOn {
+		return true
+	}
+	if t, ok := r.inFlight[quote]; ok && t.To == opp.BuyOn {
+		return true
+	}
+	return false
+}
+
+// send отправляет перевод. maybeSent — при ошибке перевод всё же мог быть создан
+// (ответ биржи не получен, транзакция не подтверждена).
+func (r *Rebalancer) send(ctx context.Context, t entity.Transfer) (_ entity.Transfer, maybeSent bool, _ error) {
+	network := r.opts.Assets[t.Asset].Network
+	t.UpdatedAt = r.now()
+	if t.From == r.opts.CEX {
+		id, err := r.exchange.Withdraw(ctx, trade.WithdrawRequest{
+			Coin:    t.Asset,
+			Network: network,
+			Address: r.opts.WalletAddress,
+			Amount:  t.Amount,
+		})
+		if err != nil {
+			return t, !rejected(err), err
+		}
+		t.ID = id
+		return t, true, nil
+	}
+
+	if r.sender == nil {
+		return t, false, errors.New("no wallet sender configured")
+	}
+	addr, err := r.exchange.DepositAddress(ctx, t.Asset, network)
+	if err != nil {
+		return t, false, err
+	}
+	if addr.Memo != "" {
+		return t, false, fmt.Errorf("deposit address of %s requires memo", t.Asset)
+	}
+	tx, err := r.sender.Send(ctx, t.Asset, addr.Address, t.Amount)
+	t.ID, t.TxID = tx, tx
+	return t, tx != "", err
+}
+
+// rejected сообщает, что биржа ответила отказом: заявка точно не создана.
+func rejected(err error) bool {
+	var apiErr *utils.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError
+}
+
+// hold учитывает перевод в пути и сохраняет его.
+func (r *Rebalancer) hold(t entity.Transfer) {
+	r.mu.Lock()
+	r.inFlight[t.Asset] = t
+	r.mu.Unlock()
+	r.save(t)
+}
+
+// release снимает завершённый перевод с учёта.
+func (r *Rebalancer) release(t entity.Transfer) {
+	r.mu.Lock()
+	delete(r.inFlight, t.Asset)
+	r.mu.Unlock()
+	r.save(t)
+}
+
+func (r *Rebalancer) save(t entity.Transfer) {
+	if r.opts.Store == nil {
+		return
+	}
+	if err := r.opts.Store.SaveTransfer(t); err != nil {
+		r.log.Error("failed to save transfer", "asset", t.Asset, "id", t.ID, "status", t.Status, "err", err)
+	}
+}
+
+// discover ищет в истории CEX незавершённый перевод актива между CEX и кошельком.
+func (r *Rebalancer) discover(ctx context.Context, asset string) (entity.Transfer, bool, error) {
+	now := r.now()
+	t := entity.Transfer{Asset: asset, Status: entity.TransferPending, UpdatedAt: now}
+
+	withdrawals, err := r.exchange.WithdrawHistory(ctx, asset)
+	if err != nil {
+		return t, false, err
+	}
+	for _, w := range withdrawals {
+		applied := time.UnixMilli(w.ApplyTime)
+		if w.Address != r.opts.WalletAddress || withdrawFinished(w.Status) || now.Sub(applied) > r.opts.PendingTimeout {
+			continue
+		}
+		t.ID, t.TxID, t.Amount, t.CreatedAt = w.ID, w.TxID, float64(w.Amount), applied
+		t.From, t.To = r.opts.CEX, r.opts.Wallet
+		return t, true, nil
+	}
+
+	deposits, err := r.exchange.DepositHistory(ctx, asset)
+	if err != nil {
+		return t, false, err
+	}
+	for _, d := range deposits {
+		inserted := time.UnixMilli(d.InsertTime)
+		if d.TxID == "" || d.Status.Credited() || now.Sub(inserted) > r.opts.PendingTimeout {
+			continue
+		}
+		t.ID, t.TxID, t.Amount, t.CreatedAt = d.TxID, d.TxID, float64(d.Amount), inserted
+		t.From, t.To = r.opts.Wallet, r.opts.CEX
+		return t, true, nil
+	}
+	return t, false, nil
+}
+
+func withdrawFinished(s trade.WithdrawStatus) bool {
+	return s == trade.WithdrawSuccess || s == trade.WithdrawFailed || s == trade.WithdrawCancel
+}
+
+// matches сообщает, похожа ли запись CEX (объём, время) на перевод t без идентификатора.
+func matches(t entity.Transfer, amount float64, ms int64) bool {
+	return math.Abs(amount-t.Amount) <= t.Amount*1e-6 && !time.UnixMilli(ms).Before(t.CreatedAt.Add(-clockSkew))
+}
+
+// track обновляет статус перевода по истории выводов или депозитов CEX.
+func (r *Rebalancer) track(ctx context.Context, t entity.Transfer) (entity.Transfer, error) {
+	if t.From == r.opts.CEX {
+		hist, err := r.exchange.WithdrawHistory(ctx, t.Asset)
+		if err != nil {
+			return t, err
+		}
+		for _, w := range hist {
+			// Исход отправки был неизвестен — ищем заявку по адресу, объёму и времени.
+			if t.ID == "" && w.Address == r.opts.WalletAddress && matches(t, float64(w.Amount), w.ApplyTime) {
+				t.ID = w.ID
+			}
+			if w.ID != t.ID {
+				continue
+			}
+			t.TxID = w.TxID
+			switch w.Status {
+			case trade.WithdrawSuccess:
+				t.Status = entity.TransferCredited
+			case trade.WithdrawFailed, trade.WithdrawCancel:
+				t.Status = entity.TransferFailed
+			}
+		}
+	} else {
+		hist, err := r.exchange.DepositHistory(ctx, t.Asset)
+		if err != nil {
+			return t, err
+		}
+		for _, d := range hist {
+			if t.TxID == "" && d.TxID != "" && matches(t, float64(d.Amount), d.InsertTime) {
+				t.ID, t.TxID = d.TxID, d.TxID
+			}
+			// MEXC может дописывать к хэшу индекс выхода (":0").
+			if d.TxID != "" && strings.HasPrefix(d.TxID, t.TxID) && d.Status.Credited() {
+				t.Status = entity.TransferCredited
+			}
+		}
+	}
+
+	age := r.now().Sub(t.CreatedAt)
+	if t.Status == entity.TransferPending && (age > r.opts.PendingTimeout || (t.ID == "" && t.TxID == "" && age > matchWindow)) {
+		t.Status = entity.TransferFailed
+	}
+	t.UpdatedAt = r.now()
+	return t, nil
+}
@@ -0,0 +1,234 @@
+package rebalance
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/api/mexc/trade"
+	"github.com/dimryb/cross-arb/internal/api/mexc/utils"
+	"github.com/dimryb/cross-arb/internal/entity"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+type staticBalances entity.Balances
+
+func (s staticBalances) Balances() entity.Balances { return entity.Balances(s) }
+
+type fakeExchange struct {
+	withdrawals []trade.WithdrawRequest
+	withdrawn   []trade.Withdrawal
+	deposits    []trade.Deposit
+	withdrawErr error
+}
+
+func (f *fakeExchange) Withdraw(_ context.Context, req trade.WithdrawRequest) (string, error) {
+	f.withdrawals = append(f.withdrawals, req)
+	if f.withdrawErr != nil {
+		return "", f.withdrawErr
+	}
+	return "w1", nil
+}
+
+func (f *fakeExchange) WithdrawHistory(context.Context, string) ([]trade.Withdrawal, error) {
+	return f.withdrawn, nil
+}
+
+func (f *fakeExchange) DepositAddress(_ context.Context, coin, network string) (trade.DepositAddress, error) {
+	return trade.DepositAddress{Coin: coin, Network: network, Address: "mexc-deposit"}, nil
+}
+
+func (f *fakeExchange) DepositHistory(context.Context, string) ([]trade.Deposit, error) {
+	return f.deposits, nil
+}
+
+type fakeSender struct{ to string }
+
+func (f *fakeSender) Send(_ context.Context, _, to string, _ float64) (string, error) {
+	f.to = to
+	return "sig1", nil
+}
+
+type memStore map[string]entity.Transfer
+
+func (m memStore) SaveTransfer(t entity.Transfer) error {
+	if t.Status != entity.TransferPending {
+		delete(m, t.Asset)
+		return nil
+	}
+	m[t.Asset] = t
+	return nil
+}
+
+func (m memStore) PendingTransfers() ([]entity.Transfer, error) {
+	out := make([]entity.Transfer, 0, len(m))
+	for _, t := range m {
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func newTestRebalancer(t *testing.T, balances entity.Balances, ex *fakeExchange, sender WalletSender) *Rebalancer {
+	t.Helper()
+	r, err := NewRebalancer(logger.New("error"), staticBalances(balances), ex, sender, Options{
+		CEX:           "mexc",
+		Wallet:        "jupiter",
+		WalletAddress: "wallet",
+		Assets: map[string]Asset{
+			"SOL":  {Target: 0.5, MinAmount: 1, Network: "SOL"},
+			"USDT": {Target: 0.5, MinAmount: 10, Network: "SOL"},
+		},
+		Threshold: 0.2,
+		Execute:   true,
+	})
+	if err != nil {
+		t.Fatalf("NewRebalancer: %v", err)
+	}
+	return r
+}
+
+func TestRebalancer_Plan(t *testing.T) {
+	r := newTestRebalancer(t, nil, &fakeExchange{}, nil)
+
+	plan := r.Plan(entity.Balances{
+		"mexc":    {"SOL": 9, "USDT": 100},
+		"jupiter": {"SOL": 1, "USDT": 900},
+	})
+	if len(plan) != 2 {
+		t.Fatalf("plan = %+v, want 2 transfers", plan)
+	}
+	// SOL: доля на mexc 0.9 против 0.5 — вывести 4 SOL на кошелёк.
+	if p := plan[0]; p.Asset != "SOL" || p.From != "mexc" || p.To != "jupiter" || math.Abs(p.Amount-4) > 1e-9 {
+		t.Errorf("SOL transfer = %+v", p)
+	}
+	// USDT: доля 0.1 — 400 USDT с кошелька на mexc.
+	if p := plan[1]; p.Asset != "USDT" || p.From != "jupiter" || p.To != "mexc" || math.Abs(p.Amount-400) > 1e-9 {
+		t.Errorf("USDT transfer = %+v", p)
+	}
+
+	// В пределах порога и меньше минимального объёма — переводов нет.
+	plan = r.Plan(entity.Balances{
+		"mexc":    {"SOL": 0.9, "USDT": 600},
+		"jupiter": {"SOL": 0.1, "USDT": 400},
+	})
+	if len(plan) != 0 {
+		t.Errorf("plan = %+v, want none", plan)
+	}
+}
+
+func TestRebalancer_WithdrawPausesUntilCredited(t *testing.T) {
+	ex := &fakeExchange{}
+	r := newTestRebalancer(t, entity.Balances{"mexc": {"SOL": 9}, "jupiter": {"SOL": 1}}, ex, nil)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	r.Step(context.Background())
+	if len(ex.withdrawals) != 1 || ex.withdrawals[0].Address != "wallet" || ex.withdrawals[0].Network != "SOL" {
+		t.Fatalf("withdrawals = %+v", ex.withdrawals)
+	}
+
+	// Кошелёк ждёт SOL: продажа на нём приостановлена, обратное направление — нет.
+	toWallet := entity.ArbOpportunity{Pair: "SOL/USDT", BuyOn: "mexc", SellOn: "jupiter"}
+	toMexc := entity.ArbOpportunity{Pair: "SOL/USDT", BuyOn: "jupiter", SellOn: "mexc"}
+	if !r.Paused(toWallet) || r.Paused(toMexc) {
+		t.Errorf("paused = %v / %v, want true / false", r.Paused(toWallet), r.Paused(toMexc))
+	}
+
+	ex.withdrawn = []trade.Withdrawal{{ID: "w1", TxID: "tx", Status: trade.WithdrawSuccess}}
+	r.Step(context.Background())
+	if r.Paused(toWallet) || len(r.Pending()) != 0 {
+		t.Error("transfer must be finished after success")
+	}
+	// Остатки ещё не обновлены — повторный вывод в том же цикле не отправляется.
+	if len(ex.withdrawals) != 1 {
+		t.Errorf("withdrawals = %d, want 1", len(ex.withdrawals))
+	}
+}
+
+func TestRebalancer_DepositFromWallet(t *testing.T) {
+	ex := &fakeExchange{}
+	sender := &fakeSender{}
+	r := newTestRebalancer(t, entity.Balances{"mexc": {"USDT": 100}, "jupiter": {"USDT": 900}}, ex, sender)
+
+	r.Step(context.Background())
+	if sender.to != "mexc-deposit" {
+		t.Fatalf("sent to %q, want deposit address", sender.to)
+	}
+	if !r.Paused(entity.ArbOpportunity{Pair: "SOL/USDT", BuyOn: "mexc", SellOn: "jupiter"}) {
+		t.Error("buying on mexc must be paused while USDT is in flight")
+	}
+
+	ex.deposits = []trade.Deposit{{TxID: "sig1:0", Status: trade.DepositSuccess}}
+	r.Step(context.Background())
+	if len(r.Pending()) != 0 {
+		t.Errorf("pending = %+v, want none", r.Pending())
+	}
+}
+
+func TestRebalancer_UnknownWithdrawOutcomeIsNotRetried(t *testing.T) {
+	ex := &fakeExchange{withdrawErr: errors.New("read: connection reset by peer")}
+	r := newTestRebalancer(t, entity.Balances{"mexc": {"SOL": 9}, "jupiter": {"SOL": 1}}, ex, nil)
+	store := memStore{}
+	r.opts.Store = store
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	r.Step(context.Background())
+	if len(r.Pending()) != 1 || len(store) != 1 {
+		t.Fatalf("pending = %+v, stored = %+v, want the transfer held", r.Pending(), store)
+	}
+
+	// Заявка всё же создана: она находится в истории по адресу и объёму, повтора нет.
+	ex.withdrawErr = nil
+	ex.withdrawn = []trade.Withdrawal{{ID: "w7", Address: "wallet", Amount: 4, ApplyTime: now.UnixMilli()}}
+	r.Step(context.Background())
+	if len(ex.withdrawals) != 1 {
+		t.Fatalf("withdrawals = %d, want 1", len(ex.withdrawals))
+	}
+	if p := r.Pending(); len(p) != 1 || p[0].ID != "w7" {
+		t.Errorf("pending = %+v, want matched w7", p)
+	}
+}
+
+func TestRebalancer_RejectedWithdrawIsReleased(t *testing.T) {
+	ex := &fakeExchange{withdrawErr: &utils.APIError{StatusCode: 400, Code: 30004, Msg: "insufficient balance"}}
+	r := newTestRebalancer(t, entity.Balances{"mexc": {"SOL": 9}, "jupiter": {"SOL": 1}}, ex, nil)
+	store := memStore{}
+	r.opts.Store = store
+
+	r.Step(context.Background())
+	if len(r.Pending()) != 0 || len(store) != 0 {
+		t.Errorf("pending = %+v, stored = %+v, want none", r.Pending(), store)
+	}
+}
+
+func TestRebalancer_RestorePreventsResend(t *testing.T) {
+	now := time.Now()
+	balances := entity.Balances{"mexc": {"SOL": 9, "USDT": 100}, "jupiter": {"SOL": 1, "USDT": 900}}
+	ex := &fakeExchange{
+		// Вывод SOL ещё в обработке — найден по истории.
+		withdrawn: []trade.Withdrawal{{ID: "w1", Address: "wallet", Amount: 4, ApplyTime: now.Add(-time.Minute).UnixMilli()}},
+	}
+	sender := &fakeSender{}
+	r := newTestRebalancer(t, balances, ex, sender)
+	// Перевод USDT сохранён до перезапуска.
+	r.opts.Store = memStore{"USDT": {
+		ID: "sig0", TxID: "sig0", Asset: "USDT", From: "jupiter", To: "mexc",
+		Amount: 400, Status: entity.TransferPending, CreatedAt: now.Add(-time.Minute),
+	}}
+	r.now = func() time.Time { return now }
+
+	if err := r.Restore(context.Background()); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if len(r.Pending()) != 2 {
+		t.Fatalf("pending = %+v, want SOL and USDT", r.Pending())
+	}
+
+	r.Step(context.Background())
+	if len(ex.withdrawals) != 0 || sender.to != "" {
+		t.Errorf("withdrawals = %+v, sent to %q, want nothing resent", ex.withdrawals, sender.to)
+	}
+}
@@ -0,0 +1,226 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+type fakeDEX struct{ name string }
+
+func (f *fakeDEX) Name() string                         { return f.name }
+func (f *fakeDEX) TradingFee(string) (float64, float64) { return 0, 0 }
+func (f *fakeDEX) Close() error                         { return nil }
+func (f *fakeDEX) Quote(context.Context, string, float64) (float64, float64, error) {
+	return 100, 101, nil
+}
+
+type fakeCEX struct{ name string }
+
+func (f *fakeCEX) Name() string                         { return f.name }
+func (f *fakeCEX) TradingFee(string) (float64, float64) { return 0.001, 0.001 }
+func (f *fakeCEX) Close() error                         { return nil }
+func (f *fakeCEX) OrderBookDepth(context.Context, string, int) (entity.OrderBook, error) {
+	return entity.OrderBook{}, nil
+}
+
+// emitDEX отправляет одну котировку и ждёт отмены.
+type emitDEX struct{}
+
+func (emitDEX) Stream(
+	ctx context.Context,
+	providers []i.DEXAdapter,
+	pairs []string,
+	_ time.Duration,
+	baseAmount float64,
+	out chan<- entity.ExecutableQuote,
+) error {
+	select {
+	case out <- entity.ExecutableQuote{Exchange: providers[0].Name(), Pair: pairs[0], BidQty: baseAmount}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// failingCEX сразу завершается фатальной ошибкой.
+type failingCEX struct{ err error }
+
+func (f failingCEX) Stream(
+	context.Context, []i.CEXAdapter, []string, time.Duration, chan<- entity.OrderBookResult,
+) error {
+	return f.err
+}
+
+// emitCEX отправляет один стакан и ждёт отмены.
+type emitCEX struct{ book entity.OrderBook }
+
+func (e emitCEX) Stream(
+	ctx context.Context,
+	providers []i.CEXAdapter,
+	pairs []string,
+	_ time.Duration,
+	out chan<- entity.OrderBookResult,
+) error {
+	r := entity.OrderBookResult{Exchange: providers[0].Name(), Symbol: pairs[0], Data: e.book, Timestamp: time.Now()}
+	select {
+	case out <- r:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// recordingDetector пересылает входящие котировки в seen.
+type recordingDetector struct{ seen chan entity.ExecutableQuote }
+
+func (r recordingDetector) Detect(
+	ctx context.Context,
+	in <-chan entity.ExecutableQuote,
+	_ chan<- entity.ArbOpportunity,
+) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case q := <-in:
+			r.seen <- q
+		}
+	}
+}
+
+func TestService_Start_FanOutAndCancel(t *testing.T) {
+	pricesCh := make(chan entity.ExecutableQuote, 1)
+	oppCh := make(chan entity.ArbOpportunity)
+	detector := recordingDetector{seen: make(chan entity.ExecutableQuote, 1)}
+
+	svc, err := NewService(
+		logger.New("error"),
+		time.Second,
+		2,
+		[]string{"SOL/USDT"},
+		[]i.EXAdapter{&fakeDEX{name: "dex"}, &fakeCEX{name: "cex"}},
+		pricesCh,
+		nil,
+		oppCh,
+		emitDEX{},
+		nil,
+		detector,
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- svc.Start(ctx) }()
+
+	for name, ch := range map[string]chan entity.ExecutableQuote{"prices": pricesCh, "detector": detector.seen} {
+		select {
+		case q := <-ch:
+			if q.Exchange != "dex" || q.Pair != "SOL/USDT" || q.BidQty != 2 {
+				t.Errorf("%s: unexpected quote %+v", name, q)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: quote was not delivered", name)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Start returned %v after cancellation, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after cancellation")
+	}
+}
+
+func TestService_Start_ReturnsFirstFatalError(t *testing.T) {
+	boom := errors.New("boom")
+
+	svc, err := NewService(
+		logger.New("error"),
+		time.Second,
+		1,
+		[]string{"SOL/USDT"},
+		[]i.EXAdapter{&fakeDEX{name: "dex"}, &fakeCEX{name: "cex"}},
+		make(chan entity.ExecutableQuote),
+		make(chan entity.OrderBookResult),
+		make(chan entity.ArbOpportunity),
+		nil,
+		failingCEX{err: boom},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- svc.Start(context.Background()) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, boom) {
+			t.Errorf("Start returned %v, want %v", err, boom)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Start did not stop after fatal error")
+	}
+}
+
+func TestService_Start_OrderBookBecomesVWAPQuote(t *testing.T) {
+	orderBooksCh := make(chan entity.OrderBookResult, 1)
+	detector := recordingDetector{seen: make(chan entity.ExecutableQuote, 1)}
+	book := entity.OrderBook{
+		Bids: []entity.Order{{Price: 100, Quantity: 1}, {Price: 98, Quantity: 1}},
+		Asks: []entity.Order{{Price: 101, Quantity: 1}, {Price: 103, Quantity: 1}},
+	}
+
+	svc, err := NewService(
+		logger.New("error"),
+		time.Second,
+		2,
+		[]string{"SOL/USDT"},
+		[]i.EXAdapter{&fakeDEX{name: "dex"}, &fakeCEX{name: "cex"}},
+		make(chan entity.ExecutableQuote, 4),
+		orderBooksCh,
+		make(chan entity.ArbOpportunity),
+		nil,
+		emitCEX{book: book},
+		detector,
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = svc.Start(ctx) }()
+
+	select {
+	case q := <-detector.seen:
+		if q.Exchange != "cex" || q.Pair != "SOL/USDT" || q.Bid != 99 || q.Ask != 102 || q.BidQty != 2 {
+			t.Errorf("unexpected quote %+v, want VWAP bid 99 / ask 102 for 2 BASE", q)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("order book quote was not delivered to detector")
+	}
+
+	select {
+	case r := <-orderBooksCh:
+		if r.Exchange != "cex" {
+			t.Errorf("unexpected order book result %+v", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("order book result was not forwarded")
+	}
+}
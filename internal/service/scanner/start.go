@@ -0,0 +1,188 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/orderbook"
+)
+
+var _ Scanner = (*Service)(nil)
+
+// Start запускает юзкейсы сканера как единую группу и блокируется до её завершения.
+//
+// Адаптеры делятся на DEX и CEX. Котировки DEX раздаются одновременно во внешний
+// pricesCh и в детектор возможностей; стаканы CEX публикуются в orderBooksCh и,
+// пересчитанные в VWAP-котировки под тот же baseAmount, тоже попадают в pricesCh и детектор.
+// Первая фатальная ошибка любого участника отменяет остальных и возвращается вызывающей стороне.
+// Отмена внешнего ctx считается штатным завершением — в этом случае возвращается nil.
+func (s *Service) Start(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	dexAdapters, cexAdapters := splitAdapters(s.adapters)
+
+	g := &group{cancel: cancel}
+
+	// Внутренний вход детектора: сюда сходятся все потоки котировок.
+	quotesCh := make(chan entity.ExecutableQuote, len(s.adapters)*len(s.pairs))
+
+	if len(dexAdapters) > 0 {
+		dexOut := make(chan entity.ExecutableQuote)
+		g.Go("dex prices", func() error {
+			return s.dexUC.Stream(runCtx, dexAdapters, s.pairs, s.interval, s.baseAmount, dexOut)
+		})
+		g.Go("dex fan-out", func() error {
+			return s.fanOutQuotes(runCtx, dexOut, quotesCh)
+		})
+	}
+
+	if len(cexAdapters) > 0 {
+		cexOut := make(chan entity.OrderBookResult)
+		g.Go("cex order books", func() error {
+			return s.cexUC.Stream(runCtx, cexAdapters, s.pairs, s.interval, cexOut)
+		})
+		g.Go("cex fan-out", func() error {
+			return s.fanOutOrderBooks(runCtx, cexOut, quotesCh)
+		})
+	}
+
+	g.Go("opportunities", func() error {
+		return s.oppUC.Detect(runCtx, quotesCh, s.oppCh)
+	})
+
+	s.log.Info("scanner started",
+		slog.Int("dex", len(dexAdapters)),
+		slog.Int("cex", len(cexAdapters)),
+		slog.Int("pairs", len(s.pairs)),
+	)
+
+	err := g.Wait()
+	if err != nil && ctx.Err() == nil {
+		s.log.Error("scanner stopped with error", slog.Any("err", err))
+		return err
+	}
+
+	s.log.Info("scanner stopped")
+	return nil
+}
+
+// fanOutQuotes пересылает котировки DEX во внешний pricesCh и во вход детектора.
+func (s *Service) fanOutQuotes(
+	ctx context.Context,
+	in <-chan entity.ExecutableQuote,
+	detector chan<- entity.ExecutableQuote,
+) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case q := <-in:
+			if err := send(ctx, s.pricesCh, q); err != nil {
+				return err
+			}
+			if err := send(ctx, detector, q); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// fanOutOrderBooks пересылает результаты загрузки стаканов во внешний orderBooksCh.
+// Если внешний канал не задан, результаты просто вычитываются, чтобы не блокировать юзкейс.
+// Успешно загруженные стаканы переводятся в исполнимые котировки под baseAmount и
+// отправляются в pricesCh и во вход детектора; стаканы без достаточной глубины пропускаются.
+func (s *Service) fanOutOrderBooks(
+	ctx context.Context,
+	in <-chan entity.OrderBookResult,
+	detector chan<- entity.ExecutableQuote,
+) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r := <-in:
+			if err := send(ctx, s.orderBooksCh, r); err != nil {
+				return err
+			}
+			if r.Error != nil {
+				continue
+			}
+
+			q, err := orderbook.ExecutableQuote(r.Exchange, r.Symbol, r.Data, s.baseAmount, r.Timestamp)
+			if err != nil {
+				s.log.Debug("skip order book quote", slog.Any("err", err))
+				continue
+			}
+			if err := send(ctx, s.pricesCh, q); err != nil {
+				return err
+			}
+			if err := send(ctx, detector, q); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// send блокирующе отправляет v в ch с учётом ctx. Отправка в nil-канал пропускается.
+func send[T any](ctx context.Context, ch chan<- T, v T) error {
+	if ch == nil {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case ch <- v:
+		return nil
+	}
+}
+
+// splitAdapters разделяет адаптеры на DEX и CEX.
+// Адаптер, реализующий оба интерфейса, попадает в обе группы.
+func splitAdapters(adapters []i.EXAdapter) (dex []i.DEXAdapter, cex []i.CEXAdapter) {
+	for _, ad := range adapters {
+		if d, ok := ad.(i.DEXAdapter); ok {
+			dex = append(dex, d)
+		}
+		if c, ok := ad.(i.CEXAdapter); ok {
+			cex = append(cex, c)
+		}
+	}
+	return dex, cex
+}
+
+// group — минимальная супервизия горутин: первая ошибка отменяет всю группу.
+type group struct {
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+	once   sync.Once
+	err    error
+}
+
+// Go запускает fn в отдельной горутине. Ошибки, отличные от отмены контекста,
+// считаются фатальными: они сохраняются (только первая) и отменяют группу.
+func (g *group) Go(name string, fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		err := fn()
+		if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return
+		}
+		g.once.Do(func() {
+			g.err = fmt.Errorf("%s: %w", name, err)
+			g.cancel()
+		})
+	}()
+}
+
+// Wait дожидается завершения всех горутин и возвращает первую фатальную ошибку.
+func (g *group) Wait() error {
+	g.wg.Wait()
+	return g.err
+}
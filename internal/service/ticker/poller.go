@@ -0,0 +1,92 @@
+package ticker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+)
+
+// Source загружает лучшие цены пары (например, binance.Adapter).
+type Source interface {
+	Name() string
+	BookTicker(ctx context.Context, pair string) (entity.TickerData, error)
+}
+
+// Options — параметры опроса.
+type Options struct {
+	// Interval — период опроса всех пар.
+	Interval time.Duration
+	// CallTimeout — таймаут одного запроса. При 0 равен Interval.
+	CallTimeout time.Duration
+}
+
+// Poller периодически опрашивает лучшие цены пар источника и сохраняет их в хранилище
+// тикеров. Каждый запрос ограничен CallTimeout, поэтому зависший запрос не
+// задерживает остальные пары дольше таймаута.
+type Poller struct {
+	log    i.Logger
+	source Source
+	store  i.TickerStore
+	pairs  []string
+	opts   Options
+}
+
+// NewPoller создаёт опросчик. Не запускает горутины.
+func NewPoller(log i.Logger, source Source, store i.TickerStore, pairs []string, opts Options) (*Poller, error) {
+	if source == nil || store == nil {
+		return nil, errors.New("source and store are required")
+	}
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive, got %s", opts.Interval)
+	}
+	if opts.CallTimeout <= 0 {
+		opts.CallTimeout = opts.Interval
+	}
+	return &Poller{
+		log:    log.Named("book_ticker"),
+		source: source,
+		store:  store,
+		pairs:  pairs,
+		opts:   opts,
+	}, nil
+}
+
+// Run опрашивает пары сразу и затем с периодом Interval до отмены ctx.
+func (p *Poller) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll опрашивает все пары один раз.
+func (p *Poller) Poll(ctx context.Context) {
+	for _, pair := range p.pairs {
+		if ctx.Err() != nil {
+			return
+		}
+		t, err := p.fetch(ctx, pair)
+		if err != nil {
+			p.log.Debug("book ticker failed", "exchange", p.source.Name(), "pair", pair, "err", err)
+			continue
+		}
+		p.store.Set(t)
+	}
+}
+
+func (p *Poller) fetch(ctx context.Context, pair string) (entity.TickerData, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.opts.CallTimeout)
+	defer cancel()
+	return p.source.BookTicker(ctx, pair)
+}
@@ -0,0 +1,49 @@
+package ticker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	"github.com/dimryb/cross-arb/internal/logger"
+	"github.com/dimryb/cross-arb/internal/storage"
+)
+
+// hangingSource зависает на паре hang до отмены контекста, остальные пары отдаёт сразу.
+type hangingSource struct{ hang string }
+
+func (s hangingSource) Name() string { return "binance" }
+
+func (s hangingSource) BookTicker(ctx context.Context, pair string) (entity.TickerData, error) {
+	if pair == s.hang {
+		<-ctx.Done()
+		return entity.TickerData{}, ctx.Err()
+	}
+	return entity.TickerData{Symbol: pair, Exchange: "binance"}, nil
+}
+
+func TestPoller_CallTimeoutBoundsHangingRequest(t *testing.T) {
+	store := storage.NewTickerStore()
+	p, err := NewPoller(logger.New("error"), hangingSource{hang: "SOL/USDT"}, store,
+		[]string{"SOL/USDT", "BTC/USDT"}, Options{Interval: time.Hour, CallTimeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewPoller: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.Poll(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("poll is not bounded by call timeout")
+	}
+
+	got := store.GetAll()
+	if len(got) != 1 || got[0].Symbol != "BTC/USDT" {
+		t.Errorf("tickers = %+v, want only BTC/USDT", got)
+	}
+}
@@ -2,12 +2,17 @@ package solana
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	i "github.com/dimryb/cross-arb/internal/interface"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
 	rpctx "github.com/gagliardetto/solana-go/rpc/sendAndConfirmTransaction"
 	"github.com/gagliardetto/solana-go/rpc/ws"
 )
@@ -36,17 +41,100 @@ func NewSolanaClient(logger i.Logger, rpcURL string) (*Client, error) {
 	}, nil
 }
 
+// awaitPollInterval — период опроса статуса транзакции в AwaitTransaction.
+const awaitPollInterval = 2 * time.Second
+
+// UnconfirmedError — транзакция могла уйти в сеть, но её исход неизвестен: она ещё может
+// попасть в блок, пока действителен Blockhash (см. AwaitTransaction).
+type UnconfirmedError struct {
+	Signature solana.Signature
+	Blockhash solana.Hash
+	Err       error
+}
+
+func (e *UnconfirmedError) Error() string {
+	return fmt.Sprintf("transaction %s is not confirmed: %v", e.Signature, e.Err)
+}
+
+func (e *UnconfirmedError) Unwrap() error { return e.Err }
+
 // SendAndConfirmTransaction отправляет и подтверждает транзакцию.
+//
+// Если узел отклонил транзакцию (например, на предварительной симуляции), возвращается
+// пустая сигнатура. Если транзакция могла уйти в сеть, но подтверждение не получено,
+// возвращается её сигнатура и *UnconfirmedError.
 func (c *Client) SendAndConfirmTransaction(ctx context.Context, tx *solana.Transaction) (solana.Signature, error) {
-	sig, err := rpctx.SendAndConfirmTransaction(ctx, c.rpcClient, c.wsClient, tx)
+	sig, err := c.rpcClient.SendTransactionWithOpts(ctx, tx, rpc.TransactionOpts{
+		PreflightCommitment: rpc.CommitmentFinalized,
+	})
 	if err != nil {
-		return solana.Signature{}, err
+		var rpcErr *jsonrpc.RPCError
+		if errors.As(err, &rpcErr) || len(tx.Signatures) == 0 {
+			return solana.Signature{}, err
+		}
+		// Ответ узла не получен — транзакция могла быть принята.
+		sig = tx.Signatures[0]
+		return sig, &UnconfirmedError{Signature: sig, Blockhash: tx.Message.RecentBlockhash, Err: err}
+	}
+
+	confirmed, err := rpctx.WaitForConfirmation(ctx, c.wsClient, sig, nil)
+	if err != nil {
+		if confirmed { // транзакция в блоке, но исполнилась с ошибкой
+			return sig, err
+		}
+		return sig, &UnconfirmedError{Signature: sig, Blockhash: tx.Message.RecentBlockhash, Err: err}
 	}
 
 	c.logger.Debug("Транзакция успешно отправлена и подтверждена", "сигнатура", sig.String())
 	return sig, nil
 }
 
+// AwaitTransaction опрашивает статус транзакции sig, пока она не подтвердится или не истечёт
+// её blockhash, и возвращает, исполнена ли она успешно. false без ошибки — транзакция
+// не попала в блок или исполнилась с ошибкой: балансы (кроме комиссии) не изменились.
+// Ошибка означает, что исход выяснить не удалось (например, ctx отменён раньше).
+func (c *Client) AwaitTransaction(ctx context.Context, sig solana.Signature, blockhash solana.Hash) (bool, error) {
+	ticker := time.NewTicker(awaitPollInterval)
+	defer ticker.Stop()
+	for {
+		landed, known, err := c.transactionOutcome(ctx, sig, blockhash)
+		if err == nil && known {
+			return landed, nil
+		}
+		if err != nil {
+			c.logger.Debug("transaction status check failed", "signature", sig.String(), "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return false, fmt.Errorf("transaction %s outcome is unknown: %w", sig, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// transactionOutcome проверяет исход транзакции; known = false — исход ещё не определён.
+func (c *Client) transactionOutcome(ctx context.Context, sig solana.Signature, blockhash solana.Hash) (landed, known bool, err error) {
+	// Актуальность blockhash проверяется до статуса: если blockhash истёк, а транзакции
+	// после этого нет даже в истории, в блок она уже не попадёт.
+	valid, err := c.rpcClient.IsBlockhashValid(ctx, blockhash, rpc.CommitmentConfirmed)
+	if err != nil {
+		return false, false, fmt.Errorf("blockhash validity: %w", err)
+	}
+	res, err := c.rpcClient.GetSignatureStatuses(ctx, true, sig)
+	if err != nil {
+		return false, false, fmt.Errorf("signature status: %w", err)
+	}
+	if len(res.Value) > 0 && res.Value[0] != nil {
+		switch st := res.Value[0]; st.ConfirmationStatus {
+		case rpc.ConfirmationStatusConfirmed, rpc.ConfirmationStatusFinalized:
+			return st.Err == nil, true, nil
+		default:
+			return false, false, nil
+		}
+	}
+	return false, !valid.Value, nil
+}
+
 // GetBalance получает баланс аккаунта.
 func (c *Client) GetBalance(ctx context.Context, account solana.PublicKey) (uint64, error) {
 	balance, err := c.rpcClient.GetBalance(ctx, account, rpc.CommitmentFinalized)
@@ -56,6 +144,150 @@ func (c *Client) GetBalance(ctx context.Context, account solana.PublicKey) (uint
 	return balance.Value, nil
 }
 
+// TokenBalances возвращает балансы SPL-токенов владельца по его токен-аккаунтам
+// (программы Token и Token-2022): mint → количество в целых токенах.
+// Балансы нескольких аккаунтов одного mint суммируются.
+func (c *Client) TokenBalances(ctx context.Context, owner solana.PublicKey) (map[string]float64, error) {
+	balances := make(map[string]float64)
+	for _, program := range []solana.PublicKey{solana.TokenProgramID, solana.Token2022ProgramID} {
+		res, err := c.rpcClient.GetTokenAccountsByOwner(ctx, owner,
+			&rpc.GetTokenAccountsConfig{ProgramId: program.ToPointer()},
+			&rpc.GetTokenAccountsOpts{Encoding: solana.EncodingJSONParsed, Commitment: rpc.CommitmentConfirmed},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("token accounts of %s: %w", program, err)
+		}
+		for _, acc := range res.Value {
+			mint, amount, err := parseTokenAccount(acc)
+			if err != nil {
+				return nil, err
+			}
+			balances[mint] += amount
+		}
+	}
+	return balances, nil
+}
+
+// TokenDeltas возвращает изменения балансов owner в подтверждённой транзакции sig:
+// mint → разница в целых токенах по метаданным транзакции (pre/post token balances).
+//
+// Нативный SOL суммируется с обёрнутым под mint wSOL: Jupiter оборачивает и разворачивает
+// SOL внутри той же транзакции. Комиссия сети в дельту SOL не входит, аренда созданных
+// транзакцией аккаунтов — входит.
+func (c *Client) TokenDeltas(ctx context.Context, sig solana.Signature, owner solana.PublicKey) (map[string]float64, error) {
+	version := rpc.MaxSupportedTransactionVersion0
+	res, err := c.rpcClient.GetTransaction(ctx, sig, &rpc.GetTransactionOpts{
+		Encoding:                       solana.EncodingBase64,
+		Commitment:                     rpc.CommitmentConfirmed,
+		MaxSupportedTransactionVersion: &version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res == nil || res.Meta == nil || res.Transaction == nil {
+		return nil, fmt.Errorf("transaction %s: no metadata", sig)
+	}
+	meta := res.Meta
+	if meta.Err != nil {
+		return nil, fmt.Errorf("transaction %s failed: %v", sig, meta.Err)
+	}
+
+	deltas := make(map[string]float64)
+	sides := []struct {
+		sign     float64
+		balances []rpc.TokenBalance
+	}{{-1, meta.PreTokenBalances}, {1, meta.PostTokenBalances}}
+	for _, side := range sides {
+		for _, b := range side.balances {
+			if b.Owner == nil || !b.Owner.Equals(owner) || b.UiTokenAmount == nil {
+				continue
+			}
+			amount, err := strconv.ParseFloat(b.UiTokenAmount.UiAmountString, 64)
+			if err != nil {
+				return nil, fmt.Errorf("transaction %s: token %s amount: %w", sig, b.Mint, err)
+			}
+			deltas[b.Mint.String()] += side.sign * amount
+		}
+	}
+
+	tx, err := res.Transaction.GetTransaction()
+	if err != nil {
+		return nil, fmt.Errorf("transaction %s: %w", sig, err)
+	}
+	for n, key := range tx.Message.AccountKeys {
+		if !key.Equals(owner) || n >= len(meta.PreBalances) || n >= len(meta.PostBalances) {
+			continue
+		}
+		lamports := float64(meta.PostBalances[n]) - float64(meta.PreBalances[n])
+		if n == 0 { // fee payer
+			lamports += float64(meta.Fee)
+		}
+		deltas[solana.SolMint.String()] += lamports / float64(solana.LAMPORTS_PER_SOL)
+		break
+	}
+	return deltas, nil
+}
+
+// AccountsData возвращает данные аккаунтов addresses (base58) одним запросом getMultipleAccounts
+// в порядке адресов. Для несуществующего аккаунта возвращается nil.
+func (c *Client) AccountsData(ctx context.Context, addresses []string) ([][]byte, error) {
+	keys := make([]solana.PublicKey, len(addresses))
+	for n, addr := range addresses {
+		key, err := solana.PublicKeyFromBase58(addr)
+		if err != nil {
+			return nil, fmt.Errorf("account %q: %w", addr, err)
+		}
+		keys[n] = key
+	}
+
+	res, err := c.rpcClient.GetMultipleAccountsWithOpts(ctx, keys, &rpc.GetMultipleAccountsOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Value) != len(keys) {
+		return nil, fmt.Errorf("got %d accounts, want %d", len(res.Value), len(keys))
+	}
+
+	data := make([][]byte, len(keys))
+	for n, acc := range res.Value {
+		if acc != nil && acc.Data != nil {
+			data[n] = acc.Data.GetBinary()
+		}
+	}
+	return data, nil
+}
+
+// parsedTokenAccount — нужная часть jsonParsed-представления токен-аккаунта.
+type parsedTokenAccount struct {
+	Parsed struct {
+		Info struct {
+			Mint        string `json:"mint"`
+			TokenAmount struct {
+				UIAmountString string `json:"uiAmountString"`
+			} `json:"tokenAmount"`
+		} `json:"info"`
+	} `json:"parsed"`
+}
+
+func parseTokenAccount(acc *rpc.TokenAccount) (mint string, amount float64, err error) {
+	if acc.Account.Data == nil {
+		return "", 0, fmt.Errorf("token account %s: no data", acc.Pubkey)
+	}
+	var parsed parsedTokenAccount
+	if err := json.Unmarshal(acc.Account.Data.GetRawJSON(), &parsed); err != nil {
+		return "", 0, fmt.Errorf("token account %s: %w", acc.Pubkey, err)
+	}
+	info := parsed.Parsed.Info
+	amount, err = strconv.ParseFloat(info.TokenAmount.UIAmountString, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("token account %s amount: %w", acc.Pubkey, err)
+	}
+	return info.Mint, amount, nil
+}
+
 // Close закрывает соединения.
 func (c *Client) Close() {
 	c.wsClient.Close()
@@ -0,0 +1,93 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/gagliardetto/solana-go"
+	ata "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Transfer переводит amount токена mint (в целых токенах) с кошелька signer на адрес to.
+// Для wSOL (solana.SolMint) переводится нативный SOL, для остальных mint — SPL-токен
+// программы Token между ассоциированными токен-аккаунтами; аккаунт получателя
+// создаётся за счёт отправителя, если его ещё нет.
+//
+// Возвращает сигнатуру подтверждённой транзакции. Если транзакция отправлена, но не
+// подтверждена, сигнатура возвращается вместе с *UnconfirmedError.
+func (c *Client) Transfer(
+	ctx context.Context,
+	signer i.TransactionSigner,
+	to solana.PublicKey,
+	mint solana.PublicKey,
+	amount float64,
+) (solana.Signature, error) {
+	if amount <= 0 {
+		return solana.Signature{}, fmt.Errorf("amount must be positive, got %v", amount)
+	}
+	from := signer.PublicKey()
+
+	var instructions []solana.Instruction
+	if mint.Equals(solana.SolMint) {
+		lamports := uint64(math.Round(amount * float64(solana.LAMPORTS_PER_SOL)))
+		instructions = append(instructions, system.NewTransferInstruction(lamports, from, to).Build())
+	} else {
+		ixs, err := c.tokenTransfer(ctx, from, to, mint, amount)
+		if err != nil {
+			return solana.Signature{}, err
+		}
+		instructions = ixs
+	}
+
+	recent, err := c.rpcClient.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("latest blockhash: %w", err)
+	}
+	tx, err := solana.NewTransaction(instructions, recent.Value.Blockhash, solana.TransactionPayer(from))
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("build transaction: %w", err)
+	}
+	if err := signer.SignTransaction(tx); err != nil {
+		return solana.Signature{}, fmt.Errorf("sign transaction: %w", err)
+	}
+	return c.SendAndConfirmTransaction(ctx, tx)
+}
+
+func (c *Client) tokenTransfer(
+	ctx context.Context,
+	from, to, mint solana.PublicKey,
+	amount float64,
+) ([]solana.Instruction, error) {
+	supply, err := c.rpcClient.GetTokenSupply(ctx, mint, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, fmt.Errorf("token supply of %s: %w", mint, err)
+	}
+	decimals := supply.Value.Decimals
+	atoms := uint64(math.Round(amount * math.Pow10(int(decimals))))
+
+	source, _, err := solana.FindAssociatedTokenAddress(from, mint)
+	if err != nil {
+		return nil, err
+	}
+	destination, _, err := solana.FindAssociatedTokenAddress(to, mint)
+	if err != nil {
+		return nil, err
+	}
+
+	var instructions []solana.Instruction
+	if _, err := c.rpcClient.GetAccountInfo(ctx, destination); err != nil {
+		if !errors.Is(err, rpc.ErrNotFound) {
+			return nil, fmt.Errorf("destination token account: %w", err)
+		}
+		instructions = append(instructions, ata.NewCreateInstruction(from, to, mint).Build())
+	}
+	instructions = append(instructions,
+		token.NewTransferCheckedInstruction(atoms, decimals, source, mint, destination, from, nil).Build())
+	return instructions, nil
+}
@@ -0,0 +1,278 @@
+package execute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+)
+
+const (
+	defaultLegTimeout        = 30 * time.Second
+	defaultMaxOpportunityAge = 3 * time.Second
+
+	// fillTolerance — относительный допуск при сравнении исполненных объёмов.
+	fillTolerance = 1e-6
+)
+
+// Mode — порядок исполнения ног.
+type Mode string
+
+const (
+	// ModeConcurrent — обе ноги отправляются одновременно на объём возможности.
+	ModeConcurrent Mode = "concurrent"
+	// ModeSequential — сначала покупка, затем продажа фактически купленного объёма.
+	ModeSequential Mode = "sequential"
+)
+
+// Options — параметры движка исполнения.
+type Options struct {
+	// Mode — порядок исполнения ног. При пустом значении — ModeConcurrent.
+	Mode Mode
+	// LegTimeout — ограничение на исполнение одной ноги. При 0 — 30 сек.
+	LegTimeout time.Duration
+	// MaxOpportunityAge — возможности старше этого возраста пропускаются. При 0 — 3 сек.
+	MaxOpportunityAge time.Duration
+	// Hedge — закрывать позицию, открытую несовпадением объёмов ног.
+	Hedge bool
+	// MaxHedgeLoss — допустимый убыток хеджа в QUOTE относительно средней цены исполненной
+	// ноги (без учёта комиссий). Задаёт лимитную цену хеджирующего ордера.
+	MaxHedgeLoss float64
+	// BalanceCap возвращает максимальный объём BASE, допустимый балансами для сделки;
+	// объём возможности урезается до него. Необязателен.
+	BalanceCap func(opp entity.ArbOpportunity) float64
+	// AfterExecute вызывается после каждого исполнения до того, как взять следующую
+	// возможность (например, обновить балансы для BalanceCap). Необязателен.
+	AfterExecute func(ctx context.Context, exec entity.Execution)
+}
+
+// Engine превращает арбитражные возможности в пары ордеров на биржах покупки и продажи
+// и публикует записи об исполнении с реализованным PnL.
+//
+// Возможности исполняются по одной: следующая берётся только после завершения обеих ног
+// предыдущей, поэтому сделки не конкурируют за одни и те же балансы.
+// Начатое исполнение доводится до конца даже при отмене ctx, чтобы не оставить
+// ногу без пары; каждая нога ограничена LegTimeout. При Options.Hedge разница
+// объёмов ног закрывается на бирже, где осталась позиция (см. hedge), кроме исполнений
+// с неизвестным исходом ноги (ExecutionUncertain).
+type Engine struct {
+	log      i.Logger
+	legs     map[string]Leg
+	adapters map[string]i.EXAdapter
+	opts     Options
+	now      func() time.Time
+}
+
+// NewEngine создаёт движок. legs — исполнители по имени биржи (как в EXAdapter.Name);
+// адаптеры нужны для расчёта комиссий.
+func NewEngine(log i.Logger, legs map[string]Leg, adapters []i.EXAdapter, opts Options) (*Engine, error) {
+	if len(legs) == 0 {
+		return nil, errors.New("no execution legs provided")
+	}
+	switch opts.Mode {
+	case "":
+		opts.Mode = ModeConcurrent
+	case ModeConcurrent, ModeSequential:
+	default:
+		return nil, fmt.Errorf("unknown execution mode %q", opts.Mode)
+	}
+	if opts.LegTimeout <= 0 {
+		opts.LegTimeout = defaultLegTimeout
+	}
+	if opts.MaxOpportunityAge <= 0 {
+		opts.MaxOpportunityAge = defaultMaxOpportunityAge
+	}
+	if opts.MaxHedgeLoss < 0 {
+		return nil, fmt.Errorf("maxHedgeLoss must not be negative, got %v", opts.MaxHedgeLoss)
+	}
+
+	byName := make(map[string]i.EXAdapter, len(adapters))
+	for _, ad := range adapters {
+		byName[ad.Name()] = ad
+	}
+	return &Engine{
+		log:      log.Named("execute"),
+		legs:     legs,
+		adapters: byName,
+		opts:     opts,
+		now:      time.Now,
+	}, nil
+}
+
+// Run потребляет возможности из in и публикует записи в out до отмены ctx или закрытия in.
+// Объём возможности ограничивается BalanceCap; возможности без объёма, устаревшие
+// или на биржах без исполнителя пропускаются.
+func (e *Engine) Run(ctx context.Context, in <-chan entity.ArbOpportunity, out chan<- entity.Execution) error {
+	if out == nil {
+		return errors.New("out must not be nil")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case opp, ok := <-in:
+			if !ok {
+				return nil
+			}
+			if e.opts.BalanceCap != nil {
+				opp.Size = min(opp.Size, e.opts.BalanceCap(opp))
+			}
+			if err := e.check(opp); err != nil {
+				e.log.Debug("opportunity skipped", "pair", opp.Pair, "buy_on", opp.BuyOn, "sell_on", opp.SellOn, "err", err)
+				continue
+			}
+
+			exec := e.Execute(ctx, opp)
+			if e.opts.AfterExecute != nil {
+				e.opts.AfterExecute(ctx, exec)
+			}
+			e.log.Info("execution finished",
+				"id", exec.ID,
+				"pair", opp.Pair,
+				"status", exec.Status,
+				"realized_pnl", exec.RealizedPnl,
+				"unhedged", exec.Unhedged,
+				"hedged", exec.Hedge != nil,
+			)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case out <- exec:
+			}
+		}
+	}
+}
+
+func (e *Engine) check(opp entity.ArbOpportunity) error {
+	if opp.Size <= 0 {
+		return errors.New("opportunity has no size")
+	}
+	if age := e.now().Sub(opp.DetectedAt); age > e.opts.MaxOpportunityAge {
+		return fmt.Errorf("opportunity is stale: %v", age)
+	}
+	for _, ex := range []string{opp.BuyOn, opp.SellOn} {
+		if _, ok := e.legs[ex]; !ok {
+			return fmt.Errorf("no execution leg for %q", ex)
+		}
+	}
+	return nil
+}
+
+// Execute исполняет обе ноги возможности и возвращает запись об исполнении.
+func (e *Engine) Execute(ctx context.Context, opp entity.ArbOpportunity) entity.Execution {
+	started := e.now()
+	exec := entity.Execution{
+		ID:          strconv.FormatInt(started.UnixNano(), 36),
+		Opportunity: opp,
+		StartedAt:   started,
+	}
+
+	// Уже начатая сделка не прерывается отменой внешнего контекста.
+	legCtx := context.WithoutCancel(ctx)
+
+	buy := Order{Pair: opp.Pair, Side: entity.SideBuy, Size: opp.Size, Price: opp.BuyPrice, ClientID: exec.ID + "b"}
+	sell := Order{Pair: opp.Pair, Side: entity.SideSell, Size: opp.Size, Price: opp.SellPrice, ClientID: exec.ID + "s"}
+
+	switch e.opts.Mode {
+	case ModeSequential:
+		exec.Buy = e.runLeg(legCtx, opp.BuyOn, buy)
+		if exec.Buy.Filled > 0 {
+			sell.Size = exec.Buy.Filled
+			exec.Sell = e.runLeg(legCtx, opp.SellOn, sell)
+		} else {
+			exec.Sell = entity.ExecutionLeg{Exchange: opp.SellOn, Side: entity.SideSell}
+		}
+	default:
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			exec.Buy = e.runLeg(legCtx, opp.BuyOn, buy)
+		}()
+		go func() {
+			defer wg.Done()
+			exec.Sell = e.runLeg(legCtx, opp.SellOn, sell)
+		}()
+		wg.Wait()
+	}
+
+	exec.Status = status(exec.Buy, exec.Sell)
+	exec.RealizedPnl, exec.Unhedged = realized(exec.Buy, exec.Sell)
+	// При неизвестном исходе ноги фактическая позиция неизвестна — хедж мог бы её удвоить.
+	if e.opts.Hedge && exec.Status != entity.ExecutionUncertain && !hedged(exec.Buy, exec.Sell) {
+		e.hedge(legCtx, &exec)
+	}
+	exec.FinishedAt = e.now()
+	return exec
+}
+
+func (e *Engine) runLeg(ctx context.Context, exchange string, order Order) entity.ExecutionLeg {
+	ctx, cancel := context.WithTimeout(ctx, e.opts.LegTimeout)
+	defer cancel()
+
+	leg := entity.ExecutionLeg{Exchange: exchange, Side: order.Side, Requested: order.Size}
+
+	fill, err := e.legs[exchange].Execute(ctx, order)
+	if err != nil {
+		leg.Error = err
+		leg.Uncertain = errors.Is(err, ErrUncertain)
+		e.log.Warn("leg failed", "exchange", exchange, "side", order.Side, "size", order.Size, "err", err)
+	}
+	leg.OrderID, leg.Filled, leg.Quote, leg.Estimated = fill.OrderID, fill.Filled, fill.Quote, fill.Estimated
+	leg.Fee = leg.Quote * e.takerFee(exchange, order.Pair)
+	return leg
+}
+
+func (e *Engine) takerFee(exchange, pair string) float64 {
+	ad, ok := e.adapters[exchange]
+	if !ok {
+		return 0
+	}
+	_, taker := ad.TradingFee(pair)
+	return taker
+}
+
+// status классифицирует исполнение по фактическим объёмам ног.
+func status(buy, sell entity.ExecutionLeg) entity.ExecutionStatus {
+	switch {
+	case buy.Uncertain || sell.Uncertain:
+		return entity.ExecutionUncertain
+	case buy.Filled <= 0 && sell.Filled <= 0:
+		return entity.ExecutionFailed
+	case buy.Filled <= 0 || sell.Filled <= 0:
+		return entity.ExecutionOneLegFailed
+	case complete(buy) && complete(sell) && hedged(buy, sell):
+		return entity.ExecutionFilled
+	default:
+		return entity.ExecutionPartial
+	}
+}
+
+// hedged сообщает, совпали ли объёмы ног (открытой позиции нет).
+func hedged(buy, sell entity.ExecutionLeg) bool {
+	return math.Abs(buy.Filled-sell.Filled) <= max(buy.Filled, sell.Filled)*fillTolerance
+}
+
+func complete(l entity.ExecutionLeg) bool {
+	return l.Error == nil && l.Filled >= l.Requested*(1-fillTolerance)
+}
+
+// realized считает PnL по совпавшему объёму ног (комиссии — пропорционально) и открытую позицию.
+func realized(buy, sell entity.ExecutionLeg) (pnl, unhedged float64) {
+	unhedged = buy.Filled - sell.Filled
+	matched := min(buy.Filled, sell.Filled)
+	if matched <= 0 {
+		return 0, unhedged
+	}
+	buyCost := matched*buy.AvgPrice() + buy.Fee*matched/buy.Filled
+	sellProceeds := matched*sell.AvgPrice() - sell.Fee*matched/sell.Filled
+	return sellProceeds - buyCost, unhedged
+}
@@ -0,0 +1,261 @@
+package execute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+type feeAdapter struct {
+	name  string
+	taker float64
+}
+
+func (f *feeAdapter) Name() string                         { return f.name }
+func (f *feeAdapter) TradingFee(string) (float64, float64) { return f.taker, f.taker }
+func (f *feeAdapter) Close() error                         { return nil }
+
+// fakeLeg исполняет долю ratio от запрошенного объёма по цене price,
+// сдвигая цену на drift после каждого вызова. Последняя заявка сохраняется в last.
+type fakeLeg struct {
+	price     float64
+	drift     float64
+	ratio     float64
+	estimated bool
+	err       error
+	calls     atomic.Int32
+	sizes     chan float64
+	last      Order
+}
+
+func (f *fakeLeg) Execute(_ context.Context, o Order) (Fill, error) {
+	f.calls.Add(1)
+	f.last = o
+	if f.sizes != nil {
+		f.sizes <- o.Size
+	}
+	if f.err != nil {
+		return Fill{}, f.err
+	}
+	price := f.price
+	f.price += f.drift
+	if err := checkLimit(o, price); err != nil {
+		return Fill{}, err
+	}
+	filled := o.Size * f.ratio
+	return Fill{OrderID: "id", Filled: filled, Quote: filled * price, Estimated: f.estimated}, nil
+}
+
+func newTestEngine(t *testing.T, mode Mode, buy, sell Leg) *Engine {
+	t.Helper()
+	e, err := NewEngine(logger.New("error"),
+		map[string]Leg{"mexc": buy, "jupiter": sell},
+		[]i.EXAdapter{&feeAdapter{name: "mexc", taker: 0.001}, &feeAdapter{name: "jupiter"}},
+		Options{Mode: mode},
+	)
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	return e
+}
+
+func testOpp() entity.ArbOpportunity {
+	return entity.ArbOpportunity{
+		Pair: "SOL/USDT", BuyOn: "mexc", BuyPrice: 100, SellOn: "jupiter", SellPrice: 102,
+		Size: 2, DetectedAt: time.Now(),
+	}
+}
+
+func TestEngine_Filled(t *testing.T) {
+	e := newTestEngine(t, ModeConcurrent, &fakeLeg{price: 100, ratio: 1}, &fakeLeg{price: 102, ratio: 1, estimated: true})
+
+	exec := e.Execute(context.Background(), testOpp())
+	if exec.Status != entity.ExecutionFilled {
+		t.Fatalf("status = %s, want filled", exec.Status)
+	}
+	if exec.Buy.Estimated || !exec.Sell.Estimated {
+		t.Errorf("estimated = %v / %v, want false / true", exec.Buy.Estimated, exec.Sell.Estimated)
+	}
+	// 2*(102-100) - 0.1% от 200 на покупке.
+	if math.Abs(exec.RealizedPnl-3.8) > 1e-9 || exec.Unhedged != 0 {
+		t.Errorf("pnl = %v, unhedged = %v, want 3.8, 0", exec.RealizedPnl, exec.Unhedged)
+	}
+	if exec.Buy.Fee != 0.2 || exec.Sell.Fee != 0 {
+		t.Errorf("fees = %v / %v", exec.Buy.Fee, exec.Sell.Fee)
+	}
+}
+
+func TestEngine_PartialAndOneLegFailed(t *testing.T) {
+	e := newTestEngine(t, ModeConcurrent, &fakeLeg{price: 100, ratio: 0.5}, &fakeLeg{price: 102, ratio: 1})
+	exec := e.Execute(context.Background(), testOpp())
+	if exec.Status != entity.ExecutionPartial {
+		t.Errorf("status = %s, want partial", exec.Status)
+	}
+	if exec.Unhedged != -1 {
+		t.Errorf("unhedged = %v, want -1 (sold more than bought)", exec.Unhedged)
+	}
+
+	boom := errors.New("boom")
+	e = newTestEngine(t, ModeConcurrent, &fakeLeg{price: 100, ratio: 1}, &fakeLeg{err: boom})
+	exec = e.Execute(context.Background(), testOpp())
+	if exec.Status != entity.ExecutionOneLegFailed || !errors.Is(exec.Sell.Error, boom) {
+		t.Errorf("status = %s, sell err = %v, want one_leg_failed with boom", exec.Status, exec.Sell.Error)
+	}
+	if exec.RealizedPnl != 0 || exec.Unhedged != 2 {
+		t.Errorf("pnl = %v, unhedged = %v, want 0, 2", exec.RealizedPnl, exec.Unhedged)
+	}
+}
+
+func TestEngine_SequentialSellsBoughtAmount(t *testing.T) {
+	sell := &fakeLeg{price: 102, ratio: 1, sizes: make(chan float64, 1)}
+	e := newTestEngine(t, ModeSequential, &fakeLeg{price: 100, ratio: 0.25}, sell)
+
+	exec := e.Execute(context.Background(), testOpp())
+	if got := <-sell.sizes; got != 0.5 {
+		t.Errorf("sell size = %v, want 0.5 (bought amount)", got)
+	}
+	if exec.Status != entity.ExecutionPartial || exec.Unhedged != 0 {
+		t.Errorf("status = %s, unhedged = %v", exec.Status, exec.Unhedged)
+	}
+
+	// Покупка не удалась — продажа не отправляется.
+	sell = &fakeLeg{price: 102, ratio: 1}
+	e = newTestEngine(t, ModeSequential, &fakeLeg{err: errors.New("rejected")}, sell)
+	if exec := e.Execute(context.Background(), testOpp()); exec.Status != entity.ExecutionFailed {
+		t.Errorf("status = %s, want failed", exec.Status)
+	}
+	if sell.calls.Load() != 0 {
+		t.Error("sell leg must not run after failed buy")
+	}
+}
+
+func TestEngine_RunSkipsStaleAndUnsized(t *testing.T) {
+	buy, sell := &fakeLeg{price: 100, ratio: 1}, &fakeLeg{price: 102, ratio: 1}
+	e := newTestEngine(t, ModeConcurrent, buy, sell)
+
+	stale := testOpp()
+	stale.DetectedAt = time.Now().Add(-time.Minute)
+	unsized := testOpp()
+	unsized.Size = 0
+
+	in := make(chan entity.ArbOpportunity, 3)
+	in <- stale
+	in <- unsized
+	in <- testOpp()
+	close(in)
+
+	out := make(chan entity.Execution, 3)
+	if err := e.Run(context.Background(), in, out); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	close(out)
+
+	var n int
+	for range out {
+		n++
+	}
+	if n != 1 || buy.calls.Load() != 1 {
+		t.Errorf("executions = %d, buy calls = %d, want 1 and 1", n, buy.calls.Load())
+	}
+}
+
+func TestEngine_RunCapsSizeByBalance(t *testing.T) {
+	buy := &fakeLeg{price: 100, ratio: 1, sizes: make(chan float64, 1)}
+	e := newTestEngine(t, ModeConcurrent, buy, &fakeLeg{price: 102, ratio: 1})
+	e.opts.BalanceCap = func(entity.ArbOpportunity) float64 { return 0.5 }
+
+	in := make(chan entity.ArbOpportunity, 1)
+	in <- testOpp()
+	close(in)
+	out := make(chan entity.Execution, 1)
+	if err := e.Run(context.Background(), in, out); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := <-buy.sizes; got != 0.5 {
+		t.Errorf("buy size = %v, want 0.5 (balance cap)", got)
+	}
+	if exec := <-out; exec.Opportunity.Size != 0.5 {
+		t.Errorf("recorded size = %v, want 0.5", exec.Opportunity.Size)
+	}
+}
+
+func TestEngine_RunRefreshesBalanceBeforeNextOpportunity(t *testing.T) {
+	buy := &fakeLeg{price: 100, ratio: 1, sizes: make(chan float64, 2)}
+	e := newTestEngine(t, ModeConcurrent, buy, &fakeLeg{price: 102, ratio: 1})
+	available := 2.5
+	e.opts.BalanceCap = func(entity.ArbOpportunity) float64 { return available }
+	// Сделка расходует остаток: следующая возможность урезается уже по обновлённому.
+	e.opts.AfterExecute = func(_ context.Context, exec entity.Execution) { available -= exec.Buy.Filled }
+
+	in := make(chan entity.ArbOpportunity, 2)
+	in <- testOpp()
+	in <- testOpp()
+	close(in)
+	out := make(chan entity.Execution, 2)
+	if err := e.Run(context.Background(), in, out); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if first, second := <-buy.sizes, <-buy.sizes; first != 2 || second != 0.5 {
+		t.Errorf("buy sizes = %v, %v, want 2 and 0.5", first, second)
+	}
+}
+
+func TestEngine_HedgesOrphanedLeg(t *testing.T) {
+	buy := &fakeLeg{price: 100, ratio: 1}
+	e := newTestEngine(t, ModeConcurrent, buy, &fakeLeg{err: errors.New("rejected")})
+	e.opts.Hedge, e.opts.MaxHedgeLoss = true, 1
+
+	exec := e.Execute(context.Background(), testOpp())
+	if exec.Status != entity.ExecutionHedged || exec.Hedge == nil {
+		t.Fatalf("status = %s, hedge = %v, want hedged", exec.Status, exec.Hedge)
+	}
+	// Купленное продаётся обратно на бирже покупки с ценой не хуже 100 - 1/2.
+	if buy.last.Side != entity.SideSell || buy.last.Size != 2 || buy.last.Limit != 99.5 {
+		t.Errorf("hedge order = %+v", buy.last)
+	}
+	// Цена та же, убыток — комиссии 0.1% на покупке и на продаже.
+	if math.Abs(exec.RealizedPnl+0.4) > 1e-9 || exec.Unhedged != 0 {
+		t.Errorf("pnl = %v, unhedged = %v, want -0.4, 0", exec.RealizedPnl, exec.Unhedged)
+	}
+}
+
+func TestEngine_DoesNotHedgeUncertainLeg(t *testing.T) {
+	buy := &fakeLeg{price: 100, ratio: 1}
+	e := newTestEngine(t, ModeConcurrent, buy, &fakeLeg{err: fmt.Errorf("%w: swap not confirmed", ErrUncertain)})
+	e.opts.Hedge, e.opts.MaxHedgeLoss = true, 1
+
+	exec := e.Execute(context.Background(), testOpp())
+	if exec.Status != entity.ExecutionUncertain || !exec.Sell.Uncertain {
+		t.Fatalf("status = %s, sell = %+v, want uncertain", exec.Status, exec.Sell)
+	}
+	// Продажа могла исполниться: обратная продажа купленного удвоила бы позицию.
+	if exec.Hedge != nil || buy.calls.Load() != 1 {
+		t.Errorf("hedge = %+v, buy calls = %d; want no hedge", exec.Hedge, buy.calls.Load())
+	}
+}
+
+func TestEngine_HedgeRespectsMaxLoss(t *testing.T) {
+	// Продажа прошла, покупка отклонена; откуп возможен только по 103 при продаже по 102.
+	sell := &fakeLeg{price: 102, drift: 1, ratio: 1}
+	e := newTestEngine(t, ModeConcurrent, &fakeLeg{err: errors.New("rejected")}, sell)
+	e.opts.Hedge, e.opts.MaxHedgeLoss = true, 1
+
+	exec := e.Execute(context.Background(), testOpp())
+	if exec.Status != entity.ExecutionHedgeFailed || exec.Hedge == nil {
+		t.Fatalf("status = %s, hedge = %v, want hedge_failed", exec.Status, exec.Hedge)
+	}
+	if !errors.Is(exec.Hedge.Error, ErrPriceLimit) || exec.Hedge.Side != entity.SideBuy {
+		t.Errorf("hedge = %+v, want rejected buy", exec.Hedge)
+	}
+	if exec.Unhedged != -2 {
+		t.Errorf("unhedged = %v, want -2", exec.Unhedged)
+	}
+}
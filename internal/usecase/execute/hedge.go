@@ -0,0 +1,66 @@
+package execute
+
+import (
+	"context"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+)
+
+// hedge закрывает открытую позицию exec.Unhedged на бирже, где она возникла:
+// излишек купленного BASE продаётся обратно на бирже покупки, недостающий —
+// откупается на бирже продажи. Цена хеджа ограничена так, чтобы убыток относительно
+// средней цены исполненной ноги не превысил MaxHedgeLoss.
+//
+// Результат записывается в exec.Hedge; статус становится ExecutionHedged или
+// ExecutionHedgeFailed, PnL и Unhedged пересчитываются с учётом хеджа.
+func (e *Engine) hedge(ctx context.Context, exec *entity.Execution) {
+	qty := exec.Unhedged
+	order := Order{Pair: exec.Opportunity.Pair, ClientID: exec.ID + "h"}
+
+	var exchange string
+	if qty > 0 {
+		exchange = exec.Buy.Exchange
+		order.Side, order.Size = entity.SideSell, qty
+		order.Price = exec.Buy.AvgPrice()
+		order.Limit = order.Price - e.opts.MaxHedgeLoss/qty
+	} else {
+		exchange = exec.Sell.Exchange
+		order.Side, order.Size = entity.SideBuy, -qty
+		order.Price = exec.Sell.AvgPrice()
+		order.Limit = order.Price + e.opts.MaxHedgeLoss/order.Size
+	}
+	// Допустимый убыток больше стоимости позиции — продаём по любой цене.
+	order.Limit = max(order.Limit, 0)
+
+	e.log.Warn("hedging unhedged position",
+		"id", exec.ID,
+		"exchange", exchange,
+		"side", order.Side,
+		"size", order.Size,
+		"limit", order.Limit,
+	)
+	leg := e.runLeg(ctx, exchange, order)
+	exec.Hedge = &leg
+
+	buy, sell := exec.Buy, exec.Sell
+	if leg.Side == entity.SideSell {
+		sell = merge(sell, leg)
+	} else {
+		buy = merge(buy, leg)
+	}
+	exec.RealizedPnl, exec.Unhedged = realized(buy, sell)
+
+	if hedged(buy, sell) {
+		exec.Status = entity.ExecutionHedged
+	} else {
+		exec.Status = entity.ExecutionHedgeFailed
+	}
+}
+
+// merge объединяет ногу с хеджем той же стороны.
+func merge(l, hedge entity.ExecutionLeg) entity.ExecutionLeg {
+	l.Filled += hedge.Filled
+	l.Quote += hedge.Quote
+	l.Fee += hedge.Fee
+	return l
+}
@@ -0,0 +1,148 @@
+package execute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	jupadapter "github.com/dimryb/cross-arb/internal/adapter/jupiter"
+	"github.com/dimryb/cross-arb/internal/api/jupiter"
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	blockchain "github.com/dimryb/cross-arb/internal/solana"
+	"github.com/gagliardetto/solana-go"
+)
+
+// SwapQuoter выдаёт исполнимые котировки Jupiter под объём ноги.
+type SwapQuoter interface {
+	SwapQuote(ctx context.Context, pair string, buy bool, baseAmount float64) (jupadapter.SwapQuote, error)
+}
+
+// QuoteSwapper исполняет обмен по готовой котировке (swap.Swapper).
+type QuoteSwapper interface {
+	SwapWithQuote(ctx context.Context, signer i.TransactionSigner, quote *jupiter.QuoteResponse) (solana.Signature, error)
+}
+
+// settleTimeout — сколько ждать исхода неподтверждённого обмена. Blockhash транзакции
+// действителен около 150 блоков (~1 мин), после этого исход известен наверняка.
+const settleTimeout = 3 * time.Minute
+
+// TxTracker выясняет исход и результат отправленной транзакции; реализуется solana.Client.
+type TxTracker interface {
+	// AwaitTransaction ждёт, пока транзакция подтвердится или истечёт её blockhash, и
+	// сообщает, исполнена ли она; ошибка — исход выяснить не удалось.
+	AwaitTransaction(ctx context.Context, sig solana.Signature, blockhash solana.Hash) (bool, error)
+	// TokenDeltas возвращает изменения балансов владельца в подтверждённой транзакции
+	// (mint → разница в целых токенах).
+	TokenDeltas(ctx context.Context, sig solana.Signature, owner solana.PublicKey) (map[string]float64, error)
+}
+
+// JupiterLeg исполняет ногу обменом через Jupiter: покупка — ExactOut QUOTE → BASE,
+// продажа — ExactIn BASE → QUOTE.
+//
+// При заданном Order.Limit обмен не отправляется, если средняя цена котировки хуже лимита.
+//
+// Исполненные объёмы берутся из изменений балансов подписанта в подтверждённой транзакции.
+// Если разобрать транзакцию не удалось (или tracker не задан), исполнение оценивается
+// номинальными объёмами котировки и помечается Fill.Estimated.
+//
+// Если транзакция отправлена, но не подтверждена, нога дожидается её исхода (до истечения
+// blockhash, не дольше settleTimeout). Исполненный обмен учитывается как обычно, не попавший
+// в блок — как неисполненный; если исход так и не выяснен, возвращается ошибка ErrUncertain.
+type JupiterLeg struct {
+	quoter  SwapQuoter
+	swapper QuoteSwapper
+	signer  i.TransactionSigner
+	tracker TxTracker
+	log     i.Logger
+}
+
+// NewJupiterLeg создаёт исполнителя. tracker может быть nil — тогда все исполнения оценочные,
+// а исход неподтверждённых обменов неизвестен.
+func NewJupiterLeg(log i.Logger, quoter SwapQuoter, swapper QuoteSwapper, signer i.TransactionSigner, tracker TxTracker) *JupiterLeg {
+	return &JupiterLeg{quoter: quoter, swapper: swapper, signer: signer, tracker: tracker, log: log.Named("jupiter-leg")}
+}
+
+// Execute удовлетворяет интерфейсу Leg.
+func (j *JupiterLeg) Execute(ctx context.Context, order Order) (Fill, error) {
+	q, err := j.quoter.SwapQuote(ctx, order.Pair, order.Side == entity.SideBuy, order.Size)
+	if err != nil {
+		return Fill{}, fmt.Errorf("swap quote: %w", err)
+	}
+	if err := checkLimit(order, q.Quote/q.Base); err != nil {
+		return Fill{}, err
+	}
+
+	sig, err := j.swapper.SwapWithQuote(ctx, j.signer, q.Response)
+	if err != nil {
+		var unconfirmed *blockchain.UnconfirmedError
+		if !errors.As(err, &unconfirmed) {
+			return Fill{OrderID: sigString(sig)}, fmt.Errorf("swap: %w", err)
+		}
+		// Транзакция ещё может попасть в блок: ждём исхода дольше таймаута ноги.
+		awaitCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+		defer cancel()
+		landed, awaitErr := j.await(awaitCtx, unconfirmed)
+		if awaitErr != nil {
+			return Fill{OrderID: sig.String()}, fmt.Errorf("%w: swap %s: %w", ErrUncertain, sig, errors.Join(err, awaitErr))
+		}
+		if !landed {
+			return Fill{OrderID: sig.String()}, fmt.Errorf("swap: %w", err)
+		}
+		j.log.Warn("unconfirmed swap landed", "signature", sig.String())
+		ctx = awaitCtx
+	}
+	fill, err := j.settled(ctx, sig, q.Response, order.Side)
+	if err != nil {
+		j.log.Warn("swap fill is estimated from quote", "signature", sig.String(), "err", err)
+		return Fill{OrderID: sig.String(), Filled: q.Base, Quote: q.Quote, Estimated: true}, nil
+	}
+	return fill, nil
+}
+
+// settled возвращает фактическое исполнение обмена по изменениям балансов подписанта:
+// при покупке BASE — выходной токен котировки, при продаже — входной.
+func (j *JupiterLeg) settled(ctx context.Context, sig solana.Signature, quote *jupiter.QuoteResponse, side entity.Side) (Fill, error) {
+	if j.tracker == nil {
+		return Fill{}, errors.New("transaction balances are not available")
+	}
+	deltas, err := j.tracker.TokenDeltas(ctx, sig, j.signer.PublicKey())
+	if err != nil {
+		return Fill{}, err
+	}
+	spent, got := -deltas[quote.InputMint], deltas[quote.OutputMint]
+	if spent <= 0 || got <= 0 {
+		return Fill{}, fmt.Errorf("no balance change: spent %v %s, got %v %s", spent, quote.InputMint, got, quote.OutputMint)
+	}
+	if side == entity.SideBuy {
+		return Fill{OrderID: sig.String(), Filled: got, Quote: spent}, nil
+	}
+	return Fill{OrderID: sig.String(), Filled: spent, Quote: got}, nil
+}
+
+// await выясняет исход неподтверждённой транзакции.
+func (j *JupiterLeg) await(ctx context.Context, unconfirmed *blockchain.UnconfirmedError) (bool, error) {
+	if j.tracker == nil {
+		return false, errors.New("transaction status is not available")
+	}
+	return j.tracker.AwaitTransaction(ctx, unconfirmed.Signature, unconfirmed.Blockhash)
+}
+
+func sigString(sig solana.Signature) string {
+	if sig.IsZero() {
+		return ""
+	}
+	return sig.String()
+}
+
+// checkLimit сверяет ожидаемую среднюю цену с Order.Limit.
+func checkLimit(order Order, price float64) error {
+	if order.Limit <= 0 {
+		return nil
+	}
+	if (order.Side == entity.SideBuy && price > order.Limit) || (order.Side == entity.SideSell && price < order.Limit) {
+		return fmt.Errorf("%w: %s at %v, limit %v", ErrPriceLimit, order.Side, price, order.Limit)
+	}
+	return nil
+}
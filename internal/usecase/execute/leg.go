@@ -0,0 +1,45 @@
+package execute
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+)
+
+// Order — заявка на исполнение одной ноги: рыночная покупка или продажа Size BASE.
+type Order struct {
+	Pair  string
+	Side  entity.Side
+	Size  float64 // Объём в BASE
+	Price float64 // Ожидаемая средняя цена, QUOTE за 1 BASE (для контроля и логов)
+	// Limit — худшая допустимая цена исполнения (максимум для покупки, минимум для продажи).
+	// 0 — без ограничения, рыночный ордер.
+	Limit float64
+	// ClientID — идентификатор ордера на стороне клиента, связывающий его с записью исполнения.
+	ClientID string
+}
+
+// ErrPriceLimit — исполнение возможно только по цене хуже Order.Limit.
+var ErrPriceLimit = errors.New("price beyond limit")
+
+// ErrUncertain — исход ордера неизвестен: он мог исполниться, а мог и нет. Движок не
+// хеджирует такое исполнение, потому что не знает фактической позиции.
+var ErrUncertain = errors.New("order outcome is unknown")
+
+// Fill — фактическое исполнение ноги.
+type Fill struct {
+	OrderID string
+	Filled  float64 // Исполненный объём в BASE
+	Quote   float64 // Потрачено или получено QUOTE
+	// Estimated — объёмы взяты из котировки, а не из фактического исполнения.
+	Estimated bool
+}
+
+// Leg исполняет ордера на одной бирже.
+//
+// При ошибке Fill может содержать частичное исполнение (например, ордер размещён,
+// но подтверждение не получено) — движок учитывает его в итоговой записи.
+type Leg interface {
+	Execute(ctx context.Context, order Order) (Fill, error)
+}
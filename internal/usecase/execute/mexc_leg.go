@@ -0,0 +1,106 @@
+package execute
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/api/mexc/trade"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+const defaultFillPollInterval = 200 * time.Millisecond
+
+// MexcTrader — торговые методы MEXC, нужные исполнителю (trade.Client).
+type MexcTrader interface {
+	PlaceOrder(ctx context.Context, req trade.OrderRequest) (trade.Order, error)
+	QueryOrder(ctx context.Context, req trade.QueryRequest) (trade.Order, error)
+}
+
+// MexcLeg исполняет ордера на MEXC: размещает ордер и опрашивает его статус
+// до финального, чтобы получить фактический объём.
+// Заявка без Limit отправляется рыночным ордером, с Limit — IOC-ордером по этой цене.
+//
+// С подключёнными фильтрами символа (SetFilters) объём округляется вниз до шага
+// количества, цена Limit — до шага цены в безопасную сторону, а заявки меньше
+// минимального количества или суммы отклоняются, не доходя до биржи.
+type MexcLeg struct {
+	trader       MexcTrader
+	symbol       func(pair string) string
+	filters      func(pair string) (market.Filters, bool)
+	pollInterval time.Duration
+}
+
+// NewMexcLeg создаёт исполнителя. symbol переводит пару ("SOL/USDT") в символ MEXC ("SOLUSDT").
+func NewMexcLeg(trader MexcTrader, symbol func(pair string) string) *MexcLeg {
+	return &MexcLeg{trader: trader, symbol: symbol, pollInterval: defaultFillPollInterval}
+}
+
+// SetFilters подключает торговые фильтры символов (например, mexc.Adapter.Filters).
+func (m *MexcLeg) SetFilters(filters func(pair string) (market.Filters, bool)) {
+	m.filters = filters
+}
+
+// Execute удовлетворяет интерфейсу Leg.
+func (m *MexcLeg) Execute(ctx context.Context, order Order) (Fill, error) {
+	order, err := m.applyFilters(order)
+	if err != nil {
+		return Fill{}, err
+	}
+	symbol := m.symbol(order.Pair)
+	req := trade.OrderRequest{
+		Symbol:           symbol,
+		Side:             trade.Side(order.Side),
+		Type:             trade.TypeMarket,
+		Quantity:         order.Size,
+		NewClientOrderID: order.ClientID,
+	}
+	if order.Limit > 0 {
+		req.Type, req.Price = trade.TypeImmediateOrCancel, order.Limit
+	}
+	placed, err := m.trader.PlaceOrder(ctx, req)
+	if err != nil {
+		return Fill{}, err
+	}
+
+	fill := Fill{OrderID: placed.OrderID}
+	for {
+		// Статус запрашиваем и после отмены ctx исполнения: ордер уже на бирже.
+		o, err := m.trader.QueryOrder(context.WithoutCancel(ctx), trade.QueryRequest{Symbol: symbol, OrderID: placed.OrderID})
+		if err != nil {
+			return fill, fmt.Errorf("order %s: %w", placed.OrderID, err)
+		}
+		fill.Filled, fill.Quote = float64(o.ExecutedQty), float64(o.CummulativeQuoteQty)
+		if o.Status.Final() {
+			return fill, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fill, fmt.Errorf("order %s not final (%s): %w", placed.OrderID, o.Status, ctx.Err())
+		case <-time.After(m.pollInterval):
+		}
+	}
+}
+
+// applyFilters приводит объём и предельную цену заявки к шагам символа и проверяет
+// минимальные количество и сумму. Сумма оценивается по Limit, а без него — по Price.
+func (m *MexcLeg) applyFilters(order Order) (Order, error) {
+	if m.filters == nil {
+		return order, nil
+	}
+	f, ok := m.filters(order.Pair)
+	if !ok {
+		return order, nil
+	}
+	order.Size = f.FloorQty(order.Size)
+	price := order.Price
+	if order.Limit > 0 {
+		order.Limit = f.RoundLimit(order.Side, order.Limit)
+		price = order.Limit
+	}
+	if err := f.Check(price, order.Size); err != nil {
+		return order, fmt.Errorf("%s %s %v: %w", order.Side, order.Pair, order.Size, err)
+	}
+	return order, nil
+}
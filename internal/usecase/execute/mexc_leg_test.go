@@ -0,0 +1,115 @@
+package execute
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dimryb/cross-arb/internal/api/mexc/trade"
+	"github.com/dimryb/cross-arb/internal/api/mexc/utils"
+	"github.com/dimryb/cross-arb/internal/entity"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+// fakeTrader возвращает заранее заданные состояния ордера на каждый QueryOrder.
+type fakeTrader struct {
+	placeErr error
+	placed   trade.OrderRequest
+	states   []trade.Order
+}
+
+func (f *fakeTrader) PlaceOrder(_ context.Context, req trade.OrderRequest) (trade.Order, error) {
+	f.placed = req
+	if f.placeErr != nil {
+		return trade.Order{}, f.placeErr
+	}
+	return trade.Order{OrderID: "42"}, nil
+}
+
+func (f *fakeTrader) QueryOrder(_ context.Context, req trade.QueryRequest) (trade.Order, error) {
+	if req.OrderID != "42" {
+		return trade.Order{}, utils.ErrOrderNotFound
+	}
+	o := f.states[0]
+	if len(f.states) > 1 {
+		f.states = f.states[1:]
+	}
+	return o, nil
+}
+
+func TestMexcLeg_PollsUntilFilled(t *testing.T) {
+	trader := &fakeTrader{states: []trade.Order{
+		{OrderID: "42", Status: trade.StatusPartiallyFilled, ExecutedQty: 1, CummulativeQuoteQty: 100},
+		{OrderID: "42", Status: trade.StatusFilled, ExecutedQty: 1.5, CummulativeQuoteQty: 150.3},
+	}}
+	leg := NewMexcLeg(trader, func(string) string { return "SOLUSDT" })
+	leg.pollInterval = 0
+
+	fill, err := leg.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideBuy, Size: 1.5, ClientID: "x1"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if fill.OrderID != "42" || fill.Filled != 1.5 || fill.Quote != 150.3 {
+		t.Errorf("fill = %+v", fill)
+	}
+	want := trade.OrderRequest{Symbol: "SOLUSDT", Side: trade.SideBuy, Type: trade.TypeMarket, Quantity: 1.5, NewClientOrderID: "x1"}
+	if trader.placed != want {
+		t.Errorf("order request = %+v, want %+v", trader.placed, want)
+	}
+}
+
+func TestMexcLeg_PlaceError(t *testing.T) {
+	leg := NewMexcLeg(&fakeTrader{placeErr: utils.ErrInsufficientBalance}, func(string) string { return "SOLUSDT" })
+
+	_, err := leg.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideSell, Size: 1})
+	if !errors.Is(err, utils.ErrInsufficientBalance) {
+		t.Fatalf("err = %v, want ErrInsufficientBalance", err)
+	}
+}
+
+func TestMexcLeg_LimitUsesIOC(t *testing.T) {
+	trader := &fakeTrader{states: []trade.Order{{OrderID: "42", Status: trade.StatusCanceled}}}
+	leg := NewMexcLeg(trader, func(string) string { return "SOLUSDT" })
+
+	fill, err := leg.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideSell, Size: 2, Limit: 99.5})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if trader.placed.Type != trade.TypeImmediateOrCancel || trader.placed.Price != 99.5 || fill.Filled != 0 {
+		t.Errorf("order request = %+v, fill = %+v", trader.placed, fill)
+	}
+}
+
+func TestMexcLeg_AppliesFilters(t *testing.T) {
+	filters := func(string) (market.Filters, bool) {
+		return market.Filters{TickSize: 0.01, StepSize: 0.001, MinQty: 0.01, MinNotional: 5}, true
+	}
+
+	t.Run("rounds size and limit", func(t *testing.T) {
+		trader := &fakeTrader{states: []trade.Order{{OrderID: "42", Status: trade.StatusCanceled}}}
+		leg := NewMexcLeg(trader, func(string) string { return "SOLUSDT" })
+		leg.SetFilters(filters)
+
+		_, err := leg.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideBuy, Size: 1.23456, Limit: 100.019})
+		if err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		if trader.placed.Quantity != 1.234 || trader.placed.Price != 100.01 {
+			t.Errorf("order request = %+v, want qty 1.234 price 100.01", trader.placed)
+		}
+	})
+
+	t.Run("rejects below min notional", func(t *testing.T) {
+		trader := &fakeTrader{}
+		leg := NewMexcLeg(trader, func(string) string { return "SOLUSDT" })
+		leg.SetFilters(filters)
+
+		_, err := leg.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideSell, Size: 0.04, Price: 100})
+		if !errors.Is(err, market.ErrBelowMinNotional) {
+			t.Fatalf("err = %v, want ErrBelowMinNotional", err)
+		}
+		if trader.placed != (trade.OrderRequest{}) {
+			t.Errorf("order was placed: %+v", trader.placed)
+		}
+	})
+}
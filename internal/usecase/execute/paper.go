@@ -0,0 +1,96 @@
+package execute
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+// ErrInsufficientFunds — на виртуальном балансе не хватает актива для сделки.
+var ErrInsufficientFunds = errors.New("insufficient paper balance")
+
+// PaperAccount — виртуальные балансы по биржам и активам для бумажной торговли.
+// Безопасен для конкурентного использования.
+type PaperAccount struct {
+	mu       sync.Mutex
+	initial  map[string]map[string]float64
+	balances map[string]map[string]float64
+}
+
+// NewPaperAccount создаёт счёт с начальными балансами: биржа → актив → количество.
+func NewPaperAccount(balances map[string]map[string]float64) *PaperAccount {
+	return &PaperAccount{initial: copyBalances(balances), balances: copyBalances(balances)}
+}
+
+// Balances возвращает копию текущих балансов.
+func (p *PaperAccount) Balances() map[string]map[string]float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return copyBalances(p.balances)
+}
+
+// PnL оценивает изменение балансов с момента создания счёта в единицах оценки marks
+// (актив → цена; для QUOTE-актива — 1). Активы без оценки не учитываются.
+func (p *PaperAccount) PnL(marks map[string]float64) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var pnl float64
+	for venue, assets := range p.balances {
+		for asset, amount := range assets {
+			pnl += (amount - p.initial[venue][asset]) * marks[asset]
+		}
+	}
+	return pnl
+}
+
+// settle проводит сделку на бирже venue: покупка списывает quote+fee QUOTE и зачисляет base BASE,
+// продажа — наоборот. При нехватке средств балансы не меняются.
+func (p *PaperAccount) settle(venue, pair string, side entity.Side, base, quote, fee float64) error {
+	inst, err := market.ParsePair(pair)
+	if err != nil {
+		return err
+	}
+	baseAsset, quoteAsset := inst.Base, inst.Quote
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	assets := p.balances[venue]
+	if assets == nil {
+		assets = make(map[string]float64)
+		p.balances[venue] = assets
+	}
+	switch side {
+	case entity.SideBuy:
+		if need := quote + fee; assets[quoteAsset] < need {
+			return fmt.Errorf("%w: %s %s %v < %v", ErrInsufficientFunds, venue, quoteAsset, assets[quoteAsset], need)
+		}
+		assets[quoteAsset] -= quote + fee
+		assets[baseAsset] += base
+	case entity.SideSell:
+		if assets[baseAsset] < base {
+			return fmt.Errorf("%w: %s %s %v < %v", ErrInsufficientFunds, venue, baseAsset, assets[baseAsset], base)
+		}
+		assets[baseAsset] -= base
+		assets[quoteAsset] += quote - fee
+	default:
+		return fmt.Errorf("invalid side %q", side)
+	}
+	return nil
+}
+
+func copyBalances(src map[string]map[string]float64) map[string]map[string]float64 {
+	dst := make(map[string]map[string]float64, len(src))
+	for venue, assets := range src {
+		m := make(map[string]float64, len(assets))
+		for asset, amount := range assets {
+			m[asset] = amount
+		}
+		dst[venue] = m
+	}
+	return dst
+}
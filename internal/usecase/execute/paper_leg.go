@@ -0,0 +1,196 @@
+package execute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/orderbook"
+)
+
+// defaultMaxBookAge — стакан старше этого возраста не используется для симуляции.
+const defaultMaxBookAge = 5 * time.Second
+
+// BookCache хранит последний стакан по бирже и паре; заполняется из потока
+// entity.OrderBookResult сканера. Безопасен для конкурентного использования.
+type BookCache struct {
+	mu    sync.RWMutex
+	books map[string]entity.OrderBookResult
+}
+
+// NewBookCache создаёт пустой кэш стаканов.
+func NewBookCache() *BookCache {
+	return &BookCache{books: make(map[string]entity.OrderBookResult)}
+}
+
+// Update сохраняет стакан; результаты с ошибкой пропускаются.
+func (c *BookCache) Update(ob entity.OrderBookResult) {
+	if ob.Error != nil {
+		return
+	}
+	c.mu.Lock()
+	c.books[ob.Exchange+"|"+ob.Symbol] = ob
+	c.mu.Unlock()
+}
+
+// OrderBook возвращает последний стакан биржи по паре.
+func (c *BookCache) OrderBook(exchange, pair string) (entity.OrderBookResult, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ob, ok := c.books[exchange+"|"+pair]
+	return ob, ok
+}
+
+// paperIDs нумерует симулированные ордера.
+var paperIDs atomic.Int64
+
+func paperOrderID() string {
+	return "paper-" + strconv.FormatInt(paperIDs.Add(1), 10)
+}
+
+// PaperBookLeg симулирует исполнение на CEX проходом по последнему стакану биржи:
+// рыночный ордер набирает объём по уровням, ордер с Limit (IOC) — только по уровням
+// не хуже лимита. Исполнение проводится по виртуальным балансам с тейкер-комиссией биржи.
+type PaperBookLeg struct {
+	account    *PaperAccount
+	exchange   i.EXAdapter
+	books      *BookCache
+	maxBookAge time.Duration
+	now        func() time.Time
+}
+
+// NewPaperBookLeg создаёт симулятор для биржи exchange.
+func NewPaperBookLeg(account *PaperAccount, exchange i.EXAdapter, books *BookCache) *PaperBookLeg {
+	return &PaperBookLeg{
+		account:    account,
+		exchange:   exchange,
+		books:      books,
+		maxBookAge: defaultMaxBookAge,
+		now:        time.Now,
+	}
+}
+
+// Execute удовлетворяет интерфейсу Leg.
+func (p *PaperBookLeg) Execute(_ context.Context, order Order) (Fill, error) {
+	name := p.exchange.Name()
+	ob, ok := p.books.OrderBook(name, order.Pair)
+	if !ok {
+		return Fill{}, fmt.Errorf("no %s order book for %s", name, order.Pair)
+	}
+	if age := p.now().Sub(ob.Timestamp); age > p.maxBookAge {
+		return Fill{}, fmt.Errorf("%s order book for %s is stale: %v", name, order.Pair, age)
+	}
+
+	levels := ob.Data.Asks
+	if order.Side == entity.SideSell {
+		levels = ob.Data.Bids
+	}
+	if order.Limit > 0 {
+		levels = withinLimit(levels, order.Side, order.Limit)
+	}
+	f := orderbook.Walk(levels, order.Size)
+	if f.Qty <= 0 {
+		return Fill{}, fmt.Errorf("%w: %s %s", orderbook.ErrInsufficientDepth, name, order.Pair)
+	}
+
+	_, taker := p.exchange.TradingFee(order.Pair)
+	if err := p.account.settle(name, order.Pair, order.Side, f.Qty, f.Notional, f.Notional*taker); err != nil {
+		return Fill{}, err
+	}
+	return Fill{OrderID: paperOrderID(), Filled: f.Qty, Quote: f.Notional}, nil
+}
+
+// withinLimit отбрасывает уровни хуже лимитной цены (уровни упорядочены от лучшего).
+func withinLimit(levels []entity.Order, side entity.Side, limit float64) []entity.Order {
+	for n, l := range levels {
+		if (side == entity.SideBuy && l.Price > limit) || (side == entity.SideSell && l.Price < limit) {
+			return levels[:n]
+		}
+	}
+	return levels
+}
+
+// PaperSwapLeg симулирует обмен через Jupiter по исполнимой котировке: объём BASE
+// берётся из котировки, а сумма QUOTE сдвигается на полный слиппедж котировки
+// в невыгодную сторону — худший исход, который допустил бы реальный обмен.
+type PaperSwapLeg struct {
+	account  *PaperAccount
+	exchange i.EXAdapter
+	quoter   SwapQuoter
+}
+
+// NewPaperSwapLeg создаёт симулятор обменов для биржи exchange.
+func NewPaperSwapLeg(account *PaperAccount, exchange i.EXAdapter, quoter SwapQuoter) *PaperSwapLeg {
+	return &PaperSwapLeg{account: account, exchange: exchange, quoter: quoter}
+}
+
+// Execute удовлетворяет интерфейсу Leg.
+func (p *PaperSwapLeg) Execute(ctx context.Context, order Order) (Fill, error) {
+	q, err := p.quoter.SwapQuote(ctx, order.Pair, order.Side == entity.SideBuy, order.Size)
+	if err != nil {
+		return Fill{}, fmt.Errorf("swap quote: %w", err)
+	}
+	if q.Response == nil || q.Base <= 0 {
+		return Fill{}, errors.New("empty swap quote")
+	}
+
+	slippage := float64(q.Response.SlippageBps) / 10_000
+	quote := q.Quote * (1 + slippage)
+	if order.Side == entity.SideSell {
+		quote = q.Quote * (1 - slippage)
+	}
+	if err := checkLimit(order, quote/q.Base); err != nil {
+		return Fill{}, err
+	}
+
+	name := p.exchange.Name()
+	_, taker := p.exchange.TradingFee(order.Pair)
+	if err := p.account.settle(name, order.Pair, order.Side, q.Base, quote, quote*taker); err != nil {
+		return Fill{}, err
+	}
+	return Fill{OrderID: paperOrderID(), Filled: q.Base, Quote: quote}, nil
+}
+
+// PaperQuoteLeg симулирует обмен на DEX без исполнимых котировок (пулы AMM) по эффективной
+// цене DEXAdapter.Quote для объёма ордера: покупка — по ask, продажа — по bid.
+type PaperQuoteLeg struct {
+	account *PaperAccount
+	dex     i.DEXAdapter
+}
+
+// NewPaperQuoteLeg создаёт симулятор обменов для DEX dex.
+func NewPaperQuoteLeg(account *PaperAccount, dex i.DEXAdapter) *PaperQuoteLeg {
+	return &PaperQuoteLeg{account: account, dex: dex}
+}
+
+// Execute удовлетворяет интерфейсу Leg.
+func (p *PaperQuoteLeg) Execute(ctx context.Context, order Order) (Fill, error) {
+	bid, ask, err := p.dex.Quote(ctx, order.Pair, order.Size)
+	if err != nil {
+		return Fill{}, fmt.Errorf("quote: %w", err)
+	}
+	price := ask
+	if order.Side == entity.SideSell {
+		price = bid
+	}
+	if price <= 0 {
+		return Fill{}, fmt.Errorf("empty %s quote for %s", order.Side, order.Pair)
+	}
+	if err := checkLimit(order, price); err != nil {
+		return Fill{}, err
+	}
+
+	name := p.dex.Name()
+	quote := order.Size * price
+	_, taker := p.dex.TradingFee(order.Pair)
+	if err := p.account.settle(name, order.Pair, order.Side, order.Size, quote, quote*taker); err != nil {
+		return Fill{}, err
+	}
+	return Fill{OrderID: paperOrderID(), Filled: order.Size, Quote: quote}, nil
+}
@@ -0,0 +1,143 @@
+package execute
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	jupadapter "github.com/dimryb/cross-arb/internal/adapter/jupiter"
+	"github.com/dimryb/cross-arb/internal/api/jupiter"
+	"github.com/dimryb/cross-arb/internal/entity"
+)
+
+type fakeQuoter struct{ q jupadapter.SwapQuote }
+
+func (f fakeQuoter) SwapQuote(context.Context, string, bool, float64) (jupadapter.SwapQuote, error) {
+	return f.q, nil
+}
+
+func testBooks(ts time.Time) *BookCache {
+	books := NewBookCache()
+	books.Update(entity.OrderBookResult{
+		Exchange: "mexc",
+		Symbol:   "SOL/USDT",
+		Data: entity.OrderBook{
+			Bids: []entity.Order{{Price: 99, Quantity: 1}, {Price: 98, Quantity: 5}},
+			Asks: []entity.Order{{Price: 100, Quantity: 1}, {Price: 101, Quantity: 5}},
+		},
+		Timestamp: ts,
+	})
+	return books
+}
+
+func TestPaperBookLeg_WalksBookAndSettles(t *testing.T) {
+	account := NewPaperAccount(map[string]map[string]float64{"mexc": {"USDT": 1000}})
+	leg := NewPaperBookLeg(account, &feeAdapter{name: "mexc", taker: 0.001}, testBooks(time.Now()))
+
+	fill, err := leg.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideBuy, Size: 2})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if fill.Filled != 2 || fill.Quote != 201 {
+		t.Errorf("fill = %+v, want 2 SOL for 201 USDT", fill)
+	}
+	bal := account.Balances()["mexc"]
+	if bal["SOL"] != 2 || math.Abs(bal["USDT"]-(1000-201-0.201)) > 1e-9 {
+		t.Errorf("balances = %v", bal)
+	}
+	// Купленное и проданное по середине 100 — убыток равен спреду и комиссии.
+	if pnl := account.PnL(map[string]float64{"SOL": 100, "USDT": 1}); math.Abs(pnl+1.201) > 1e-9 {
+		t.Errorf("pnl = %v, want -1.201", pnl)
+	}
+
+	// IOC продажа не ниже 99 исполняется только по первому уровню.
+	fill, err = leg.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideSell, Size: 2, Limit: 99})
+	if err != nil || fill.Filled != 1 || fill.Quote != 99 {
+		t.Errorf("limit fill = %+v, err = %v", fill, err)
+	}
+}
+
+func TestPaperBookLeg_Rejects(t *testing.T) {
+	account := NewPaperAccount(map[string]map[string]float64{"mexc": {"USDT": 10}})
+	leg := NewPaperBookLeg(account, &feeAdapter{name: "mexc"}, testBooks(time.Now()))
+
+	_, err := leg.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideBuy, Size: 1})
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("err = %v, want ErrInsufficientFunds", err)
+	}
+	if bal := account.Balances()["mexc"]; bal["USDT"] != 10 || bal["SOL"] != 0 {
+		t.Errorf("balances changed on rejection: %v", bal)
+	}
+
+	stale := NewPaperBookLeg(account, &feeAdapter{name: "mexc"}, testBooks(time.Now().Add(-time.Minute)))
+	if _, err := stale.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideSell, Size: 1}); err == nil {
+		t.Error("expected error for stale book")
+	}
+}
+
+func TestPaperSwapLeg_AppliesSlippage(t *testing.T) {
+	account := NewPaperAccount(map[string]map[string]float64{"jupiter": {"SOL": 3}})
+	quoter := fakeQuoter{q: jupadapter.SwapQuote{
+		Response: &jupiter.QuoteResponse{SlippageBps: 50},
+		Base:     2,
+		Quote:    204,
+	}}
+	leg := NewPaperSwapLeg(account, &feeAdapter{name: "jupiter"}, quoter)
+
+	fill, err := leg.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideSell, Size: 2})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if fill.Filled != 2 || math.Abs(fill.Quote-202.98) > 1e-9 {
+		t.Errorf("fill = %+v, want 2 SOL for 202.98 USDT", fill)
+	}
+	if bal := account.Balances()["jupiter"]; bal["SOL"] != 1 || math.Abs(bal["USDT"]-202.98) > 1e-9 {
+		t.Errorf("balances = %v", bal)
+	}
+
+	_, err = leg.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideSell, Size: 2, Limit: 102})
+	if !errors.Is(err, ErrPriceLimit) {
+		t.Errorf("err = %v, want ErrPriceLimit", err)
+	}
+}
+
+// quoteDEX отдаёт фиксированные эффективные цены bid/ask.
+type quoteDEX struct {
+	feeAdapter
+	bid, ask float64
+}
+
+func (d *quoteDEX) Quote(context.Context, string, float64) (float64, float64, error) {
+	return d.bid, d.ask, nil
+}
+
+func TestPaperQuoteLeg_SettlesAtQuote(t *testing.T) {
+	account := NewPaperAccount(map[string]map[string]float64{"raydium": {"USDT": 300}})
+	leg := NewPaperQuoteLeg(account, &quoteDEX{feeAdapter: feeAdapter{name: "raydium", taker: 0.01}, bid: 99, ask: 101})
+
+	fill, err := leg.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideBuy, Size: 2})
+	if err != nil {
+		t.Fatalf("Execute buy: %v", err)
+	}
+	if fill.Filled != 2 || fill.Quote != 202 {
+		t.Errorf("buy fill = %+v, want 2 SOL for 202 USDT", fill)
+	}
+	fill, err = leg.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideSell, Size: 1})
+	if err != nil {
+		t.Fatalf("Execute sell: %v", err)
+	}
+	if fill.Filled != 1 || fill.Quote != 99 {
+		t.Errorf("sell fill = %+v, want 1 SOL for 99 USDT", fill)
+	}
+	// 300 - 202*1.01 + 99*0.99.
+	if bal := account.Balances()["raydium"]; bal["SOL"] != 1 || math.Abs(bal["USDT"]-193.99) > 1e-9 {
+		t.Errorf("balances = %v", bal)
+	}
+
+	_, err = leg.Execute(context.Background(), Order{Pair: "SOL/USDT", Side: entity.SideBuy, Size: 1, Limit: 100})
+	if !errors.Is(err, ErrPriceLimit) {
+		t.Errorf("err = %v, want ErrPriceLimit", err)
+	}
+}
@@ -0,0 +1,175 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+)
+
+const defaultMaxQuoteAge = 5 * time.Second
+
+// OpportunityOptions — параметры детектора арбитражных возможностей.
+type OpportunityOptions struct {
+	// MinSpreadPct — минимальный чистый спред в процентах, при превышении которого публикуется возможность.
+	MinSpreadPct float64
+	// MaxQuoteAge — максимальный возраст котировки. Более старые котировки игнорируются.
+	// При 0 берётся 5 сек.
+	MaxQuoteAge time.Duration
+	// EventTime — отсчитывать возраст котировок от времени обновлённой котировки, а не от часов;
+	// нужно при воспроизведении записанной истории, где время котировок не совпадает с текущим.
+	EventTime bool
+}
+
+// FeeAwareOpportunityUseCase хранит последнюю котировку по каждой паре (биржа, пара)
+// и на каждое обновление сравнивает все комбинации «купить здесь — продать там».
+//
+// Из цен вычитаются taker-комиссии бирж (EXAdapter.TradingFee). Значения PnL
+// считаются на 1 BASE. Устаревшие котировки не участвуют в сравнении, чтобы
+// старые цены не порождали фантомных возможностей.
+type FeeAwareOpportunityUseCase struct {
+	log      i.Logger
+	adapters map[string]i.EXAdapter
+	opts     OpportunityOptions
+	now      func() time.Time
+}
+
+// NewFeeAwareOpportunityUseCase создаёт детектор. Адаптеры нужны для получения комиссий;
+// для бирж без адаптера комиссия считается нулевой.
+func NewFeeAwareOpportunityUseCase(
+	log i.Logger,
+	adapters []i.EXAdapter,
+	opts OpportunityOptions,
+) *FeeAwareOpportunityUseCase {
+	if opts.MaxQuoteAge <= 0 {
+		opts.MaxQuoteAge = defaultMaxQuoteAge
+	}
+	byName := make(map[string]i.EXAdapter, len(adapters))
+	for _, ad := range adapters {
+		byName[ad.Name()] = ad
+	}
+	return &FeeAwareOpportunityUseCase{
+		log:      log.Named("opportunities"),
+		adapters: byName,
+		opts:     opts,
+		now:      time.Now,
+	}
+}
+
+// Detect реализует ArbOpportunityUseCase.
+func (u *FeeAwareOpportunityUseCase) Detect(
+	ctx context.Context,
+	in <-chan entity.ExecutableQuote,
+	out chan<- entity.ArbOpportunity,
+) error {
+	if out == nil {
+		return errors.New("out must not be nil")
+	}
+
+	// pair → exchange → последняя котировка
+	latest := make(map[string]map[string]entity.ExecutableQuote)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case q, ok := <-in:
+			if !ok {
+				return nil
+			}
+			venues, found := latest[q.Pair]
+			if !found {
+				venues = make(map[string]entity.ExecutableQuote)
+				latest[q.Pair] = venues
+			}
+			venues[q.Exchange] = q
+
+			for _, opp := range u.evaluate(q, venues) {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case out <- opp:
+				}
+			}
+		}
+	}
+}
+
+// evaluate сравнивает обновлённую котировку со свежими котировками других бирж в обе стороны.
+// Устаревшие котировки удаляются из venues.
+func (u *FeeAwareOpportunityUseCase) evaluate(
+	updated entity.ExecutableQuote,
+	venues map[string]entity.ExecutableQuote,
+) []entity.ArbOpportunity {
+	now := u.now()
+	if u.opts.EventTime {
+		now = updated.Timestamp
+	}
+	if now.Sub(updated.Timestamp) > u.opts.MaxQuoteAge {
+		return nil
+	}
+
+	var found []entity.ArbOpportunity
+	for name, other := range venues {
+		if name == updated.Exchange {
+			continue
+		}
+		if now.Sub(other.Timestamp) > u.opts.MaxQuoteAge {
+			delete(venues, name)
+			continue
+		}
+		if opp, ok := u.compare(updated, other, now); ok {
+			found = append(found, opp)
+		}
+		if opp, ok := u.compare(other, updated, now); ok {
+			found = append(found, opp)
+		}
+	}
+	return found
+}
+
+// compare оценивает сделку «купить на buy по Ask, продать на sell по Bid».
+func (u *FeeAwareOpportunityUseCase) compare(
+	buy, sell entity.ExecutableQuote,
+	now time.Time,
+) (entity.ArbOpportunity, bool) {
+	buyPrice := buy.Ask
+	sellPrice := sell.Bid
+	if buyPrice <= 0 || sellPrice <= 0 {
+		return entity.ArbOpportunity{}, false
+	}
+
+	buyFee := u.takerFee(buy.Exchange, buy.Pair)
+	sellFee := u.takerFee(sell.Exchange, sell.Pair)
+
+	gross := sellPrice - buyPrice
+	net := sellPrice*(1-sellFee) - buyPrice*(1+buyFee)
+	spreadPct := net / buyPrice * 100
+
+	if spreadPct <= u.opts.MinSpreadPct {
+		return entity.ArbOpportunity{}, false
+	}
+
+	return entity.ArbOpportunity{
+		Pair:       buy.Pair,
+		BuyOn:      buy.Exchange,
+		BuyPrice:   buyPrice,
+		SellOn:     sell.Exchange,
+		SellPrice:  sellPrice,
+		GrossPnl:   gross,
+		NetPnl:     net,
+		SpreadPct:  spreadPct,
+		DetectedAt: now,
+	}, true
+}
+
+func (u *FeeAwareOpportunityUseCase) takerFee(exchange, pair string) float64 {
+	ad, ok := u.adapters[exchange]
+	if !ok {
+		return 0
+	}
+	_, taker := ad.TradingFee(pair)
+	return taker
+}
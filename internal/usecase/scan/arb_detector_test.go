@@ -0,0 +1,149 @@
+package scan
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+type feeAdapter struct {
+	name  string
+	taker float64
+}
+
+func (f *feeAdapter) Name() string                         { return f.name }
+func (f *feeAdapter) TradingFee(string) (float64, float64) { return f.taker, f.taker }
+func (f *feeAdapter) Close() error                         { return nil }
+
+func newTestDetector(minSpread float64, now time.Time) *FeeAwareOpportunityUseCase {
+	uc := NewFeeAwareOpportunityUseCase(
+		logger.New("error"),
+		[]i.EXAdapter{&feeAdapter{name: "mexc", taker: 0.001}, &feeAdapter{name: "jupiter"}},
+		OpportunityOptions{MinSpreadPct: minSpread, MaxQuoteAge: time.Second},
+	)
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+func runDetector(
+	t *testing.T,
+	uc ArbOpportunityUseCase,
+	quotes ...entity.ExecutableQuote,
+) []entity.ArbOpportunity {
+	t.Helper()
+
+	in := make(chan entity.ExecutableQuote, len(quotes))
+	for _, q := range quotes {
+		in <- q
+	}
+	close(in)
+
+	out := make(chan entity.ArbOpportunity, 16)
+	if err := uc.Detect(context.Background(), in, out); err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	close(out)
+
+	var opps []entity.ArbOpportunity
+	for o := range out {
+		opps = append(opps, o)
+	}
+	return opps
+}
+
+func TestFeeAwareOpportunityUseCase_NetOfFees(t *testing.T) {
+	now := time.Now()
+	uc := newTestDetector(0.1, now)
+
+	opps := runDetector(t, uc,
+		entity.ExecutableQuote{Exchange: "mexc", Pair: "SOL/USDT", Bid: 99.9, Ask: 100, Timestamp: now},
+		entity.ExecutableQuote{Exchange: "jupiter", Pair: "SOL/USDT", Bid: 101, Ask: 101.2, Timestamp: now},
+	)
+	if len(opps) != 1 {
+		t.Fatalf("got %d opportunities, want 1: %+v", len(opps), opps)
+	}
+
+	o := opps[0]
+	if o.BuyOn != "mexc" || o.SellOn != "jupiter" {
+		t.Errorf("direction = %s→%s, want mexc→jupiter", o.BuyOn, o.SellOn)
+	}
+	if math.Abs(o.GrossPnl-1) > 1e-9 {
+		t.Errorf("GrossPnl = %v, want 1", o.GrossPnl)
+	}
+	// 101 - 100*(1+0.001) = 0.9
+	if math.Abs(o.NetPnl-0.9) > 1e-9 {
+		t.Errorf("NetPnl = %v, want 0.9", o.NetPnl)
+	}
+	if math.Abs(o.SpreadPct-0.9) > 1e-9 {
+		t.Errorf("SpreadPct = %v, want 0.9", o.SpreadPct)
+	}
+	if !o.DetectedAt.Equal(now) {
+		t.Errorf("DetectedAt = %v, want %v", o.DetectedAt, now)
+	}
+}
+
+func TestFeeAwareOpportunityUseCase_UsesQuoteSides(t *testing.T) {
+	now := time.Now()
+	uc := newTestDetector(0, now)
+
+	// Котировка Jupiter «перевёрнута» (Bid > Ask): продаём по Bid, а не по меньшей из цен.
+	opps := runDetector(t, uc,
+		entity.ExecutableQuote{Exchange: "mexc", Pair: "SOL/USDT", Bid: 99.9, Ask: 100, Timestamp: now},
+		entity.ExecutableQuote{Exchange: "jupiter", Pair: "SOL/USDT", Bid: 101, Ask: 100.5, Timestamp: now},
+	)
+	if len(opps) != 1 {
+		t.Fatalf("got %d opportunities, want 1: %+v", len(opps), opps)
+	}
+	if o := opps[0]; o.BuyPrice != 100 || o.SellPrice != 101 {
+		t.Errorf("buy/sell = %v/%v, want 100/101", o.BuyPrice, o.SellPrice)
+	}
+}
+
+func TestFeeAwareOpportunityUseCase_BelowThreshold(t *testing.T) {
+	now := time.Now()
+	uc := newTestDetector(1, now)
+
+	opps := runDetector(t, uc,
+		entity.ExecutableQuote{Exchange: "mexc", Pair: "SOL/USDT", Bid: 99.9, Ask: 100, Timestamp: now},
+		entity.ExecutableQuote{Exchange: "jupiter", Pair: "SOL/USDT", Bid: 101, Ask: 101.2, Timestamp: now},
+	)
+	if len(opps) != 0 {
+		t.Errorf("got %d opportunities below threshold, want 0", len(opps))
+	}
+}
+
+func TestFeeAwareOpportunityUseCase_IgnoresStaleQuotes(t *testing.T) {
+	now := time.Now()
+	uc := newTestDetector(0, now)
+
+	opps := runDetector(t, uc,
+		entity.ExecutableQuote{Exchange: "mexc", Pair: "SOL/USDT", Bid: 90, Ask: 91, Timestamp: now.Add(-time.Minute)},
+		entity.ExecutableQuote{Exchange: "jupiter", Pair: "SOL/USDT", Bid: 101, Ask: 101.2, Timestamp: now},
+	)
+	if len(opps) != 0 {
+		t.Errorf("stale quote produced opportunities: %+v", opps)
+	}
+}
+
+func TestFeeAwareOpportunityUseCase_EventTime(t *testing.T) {
+	now := time.Now()
+	uc := newTestDetector(0, now)
+	uc.opts.EventTime = true
+
+	// Котировки часовой давности сравниваются между собой по их собственному времени.
+	past := now.Add(-time.Hour)
+	opps := runDetector(t, uc,
+		entity.ExecutableQuote{Exchange: "mexc", Pair: "SOL/USDT", Bid: 90, Ask: 91, Timestamp: past.Add(-2 * time.Second)},
+		entity.ExecutableQuote{Exchange: "mexc", Pair: "SOL/USDT", Bid: 99.9, Ask: 100, Timestamp: past},
+		entity.ExecutableQuote{Exchange: "jupiter", Pair: "SOL/USDT", Bid: 101, Ask: 101.2, Timestamp: past.Add(500 * time.Millisecond)},
+		entity.ExecutableQuote{Exchange: "jupiter", Pair: "SOL/USDT", Bid: 101, Ask: 101.2, Timestamp: past.Add(3 * time.Second)},
+	)
+	if len(opps) != 1 || !opps[0].DetectedAt.Equal(past.Add(500*time.Millisecond)) {
+		t.Errorf("opportunities = %+v, want one at the quote time", opps)
+	}
+}
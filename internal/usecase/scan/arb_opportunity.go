@@ -17,15 +17,21 @@ type ArbOpportunityUseCase interface {
 }
 
 // NoopOpportunityUseCase — заглушка выходного юзкейса.
+// Вычитывает входной поток, чтобы не блокировать источники, и ничего не публикует.
 type NoopOpportunityUseCase struct{}
 
 func NewNoopOpportunityUseCase() *NoopOpportunityUseCase { return &NoopOpportunityUseCase{} }
 
 func (n *NoopOpportunityUseCase) Detect(
 	ctx context.Context,
-	_ <-chan entity.ExecutableQuote,
+	in <-chan entity.ExecutableQuote,
 	_ chan<- entity.ArbOpportunity,
 ) error {
-	<-ctx.Done()
-	return ctx.Err()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-in:
+		}
+	}
 }
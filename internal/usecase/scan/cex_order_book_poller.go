@@ -0,0 +1,241 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+)
+
+const (
+	defaultCEXDepth       = 20
+	defaultCEXConcurrency = 4
+)
+
+// CEXOrderBookOptions — параметры опроса стаканов CEX.
+type CEXOrderBookOptions struct {
+	// Depth — лимит глубины, передаваемый в OrderBookDepth. При 0 берётся 20.
+	Depth int
+	// ExchangeDepth — лимит глубины по бирже; биржи без записи используют Depth.
+	ExchangeDepth map[string]int
+	// Concurrency — максимальное число одновременных запросов. При 0 берётся 4.
+	Concurrency int
+	// CallTimeout — таймаут одного запроса. При 0 равен интервалу опроса.
+	CallTimeout time.Duration
+}
+
+// PollingCEXOrderBookUseCase периодически загружает стаканы через CEXAdapter.OrderBookDepth.
+//
+// Результаты (в том числе ошибки) публикуются в out. Если потребитель не успевает,
+// обновления коалесцируются: для каждой пары (биржа, символ) хранится только последний
+// результат, поэтому опрос никогда не блокируется на медленном читателе.
+type PollingCEXOrderBookUseCase struct {
+	log  i.Logger
+	opts CEXOrderBookOptions
+	now  func() time.Time
+}
+
+// NewPollingCEXOrderBookUseCase создаёт юзкейс опроса стаканов.
+func NewPollingCEXOrderBookUseCase(log i.Logger, opts CEXOrderBookOptions) *PollingCEXOrderBookUseCase {
+	if opts.Depth <= 0 {
+		opts.Depth = defaultCEXDepth
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultCEXConcurrency
+	}
+	return &PollingCEXOrderBookUseCase{
+		log:  log.Named("cex_order_books"),
+		opts: opts,
+		now:  time.Now,
+	}
+}
+
+// Stream реализует CEXOrderBookUseCase.
+func (u *PollingCEXOrderBookUseCase) Stream(
+	ctx context.Context,
+	providers []i.CEXAdapter,
+	pairs []string,
+	interval time.Duration,
+	out chan<- entity.OrderBookResult,
+) error {
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", interval)
+	}
+	if out == nil {
+		return errors.New("out must not be nil")
+	}
+
+	callTimeout := u.opts.CallTimeout
+	if callTimeout <= 0 {
+		callTimeout = interval
+	}
+
+	pending := newOrderBookCoalescer()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		pending.publish(ctx, out)
+	}()
+	defer wg.Wait()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	u.poll(ctx, providers, pairs, callTimeout, pending)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case tick := <-ticker.C:
+			if lag := u.now().Sub(tick); lag > interval {
+				u.log.Debug("skip stale tick", slog.Duration("lag", lag))
+				continue
+			}
+			u.poll(ctx, providers, pairs, callTimeout, pending)
+		}
+	}
+}
+
+// poll выполняет один раунд загрузки стаканов и складывает результаты в коалесцирующий буфер.
+func (u *PollingCEXOrderBookUseCase) poll(
+	ctx context.Context,
+	providers []i.CEXAdapter,
+	pairs []string,
+	callTimeout time.Duration,
+	pending *orderBookCoalescer,
+) {
+	sem := make(chan struct{}, u.opts.Concurrency)
+	wg := &sync.WaitGroup{}
+
+	for _, p := range providers {
+		for _, pair := range pairs {
+			select {
+			case <-ctx.Done():
+				wg.Wait()
+				return
+			case sem <- struct{}{}:
+			}
+
+			wg.Add(1)
+			go func(p i.CEXAdapter, pair string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				callCtx, cancel := context.WithTimeout(ctx, callTimeout)
+				book, err := p.OrderBookDepth(callCtx, pair, u.depth(p.Name()))
+				cancel()
+
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					u.log.Warn("order book request failed",
+						slog.String("exchange", p.Name()),
+						slog.String("pair", pair),
+						slog.Any("err", err),
+					)
+				}
+
+				pending.put(entity.OrderBookResult{
+					Exchange:  p.Name(),
+					Symbol:    pair,
+					Data:      book,
+					Error:     err,
+					Timestamp: u.now(),
+				})
+			}(p, pair)
+		}
+	}
+
+	wg.Wait()
+}
+
+// depth возвращает лимит глубины стакана биржи.
+func (u *PollingCEXOrderBookUseCase) depth(exchange string) int {
+	if d := u.opts.ExchangeDepth[exchange]; d > 0 {
+		return d
+	}
+	return u.opts.Depth
+}
+
+type orderBookKey struct {
+	exchange string
+	symbol   string
+}
+
+// orderBookCoalescer хранит последний неотправленный результат для каждой пары (биржа, символ)
+// и отдаёт их в порядке первого появления.
+type orderBookCoalescer struct {
+	mu     sync.Mutex
+	order  []orderBookKey
+	latest map[orderBookKey]entity.OrderBookResult
+	notify chan struct{}
+}
+
+func newOrderBookCoalescer() *orderBookCoalescer {
+	return &orderBookCoalescer{
+		latest: make(map[orderBookKey]entity.OrderBookResult),
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// put заменяет ожидающий результат для пары более свежим. Никогда не блокируется.
+func (c *orderBookCoalescer) put(r entity.OrderBookResult) {
+	k := orderBookKey{exchange: r.Exchange, symbol: r.Symbol}
+
+	c.mu.Lock()
+	if _, ok := c.latest[k]; !ok {
+		c.order = append(c.order, k)
+	}
+	c.latest[k] = r
+	c.mu.Unlock()
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pop извлекает самый старый ожидающий результат.
+func (c *orderBookCoalescer) pop() (entity.OrderBookResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.order) == 0 {
+		return entity.OrderBookResult{}, false
+	}
+	k := c.order[0]
+	c.order = c.order[1:]
+	r := c.latest[k]
+	delete(c.latest, k)
+	return r, true
+}
+
+// publish отправляет ожидающие результаты в out до отмены ctx.
+func (c *orderBookCoalescer) publish(ctx context.Context, out chan<- entity.OrderBookResult) {
+	for {
+		r, ok := c.pop()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.notify:
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case out <- r:
+		}
+	}
+}
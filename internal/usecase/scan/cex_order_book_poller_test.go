@@ -0,0 +1,149 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+// stubCEX отдаёт стакан, лучший бид которого равен номеру вызова, или ошибку err.
+type stubCEX struct {
+	name  string
+	err   error
+	calls atomic.Int32
+	depth atomic.Int64 // Последний запрошенный лимит глубины
+}
+
+func (s *stubCEX) Name() string                         { return s.name }
+func (s *stubCEX) TradingFee(string) (float64, float64) { return 0, 0 }
+func (s *stubCEX) Close() error                         { return nil }
+
+func (s *stubCEX) OrderBookDepth(_ context.Context, _ string, depth int) (entity.OrderBook, error) {
+	s.depth.Store(int64(depth))
+	n := s.calls.Add(1)
+	if s.err != nil {
+		return entity.OrderBook{}, s.err
+	}
+	return entity.OrderBook{Bids: []entity.Order{{Price: float64(n), Quantity: 1}}}, nil
+}
+
+func TestOrderBookCoalescer_KeepsLatestPerKey(t *testing.T) {
+	c := newOrderBookCoalescer()
+	c.put(entity.OrderBookResult{Exchange: "mexc", Symbol: "SOL/USDT", Data: entity.OrderBook{Bids: []entity.Order{{Price: 1}}}})
+	c.put(entity.OrderBookResult{Exchange: "binance", Symbol: "SOL/USDT"})
+	c.put(entity.OrderBookResult{Exchange: "mexc", Symbol: "SOL/USDT", Data: entity.OrderBook{Bids: []entity.Order{{Price: 2}}}})
+
+	first, ok := c.pop()
+	if !ok || first.Exchange != "mexc" || first.Data.Bids[0].Price != 2 {
+		t.Errorf("first = %+v, want latest mexc book in first-seen order", first)
+	}
+	second, ok := c.pop()
+	if !ok || second.Exchange != "binance" {
+		t.Errorf("second = %+v, want binance", second)
+	}
+	if r, ok := c.pop(); ok {
+		t.Errorf("unexpected pending result %+v", r)
+	}
+}
+
+func TestPollingCEXOrderBookUseCase_CoalescesForSlowReader(t *testing.T) {
+	uc := NewPollingCEXOrderBookUseCase(logger.New("error"), CEXOrderBookOptions{})
+	cex := &stubCEX{name: "mexc"}
+	out := make(chan entity.OrderBookResult)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = uc.Stream(ctx, []i.CEXAdapter{cex}, []string{"SOL/USDT"}, 5*time.Millisecond, out) }()
+
+	// Первый результат уходит сразу; пока читатель стоит, опросы продолжаются.
+	<-out
+	deadline := time.Now().Add(time.Second)
+	for cex.calls.Load() < 6 {
+		if time.Now().After(deadline) {
+			t.Fatal("polling stalled on a slow reader")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Один результат уже ждёт отправки в out, за ним — только самый свежий, без очереди промежуточных.
+	<-out
+	r := <-out
+	if got := r.Data.Bids[0].Price; got < 5 {
+		t.Errorf("got book #%v, want the latest (>= 5)", got)
+	}
+}
+
+func TestPollingCEXOrderBookUseCase_SkipsStaleTicks(t *testing.T) {
+	uc := NewPollingCEXOrderBookUseCase(logger.New("error"), CEXOrderBookOptions{})
+	// Каждый тик кажется опоздавшим больше чем на интервал.
+	uc.now = func() time.Time { return time.Now().Add(time.Hour) }
+	cex := &stubCEX{name: "mexc"}
+	out := make(chan entity.OrderBookResult, 16)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := uc.Stream(ctx, []i.CEXAdapter{cex}, []string{"SOL/USDT"}, 5*time.Millisecond, out); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stream: %v", err)
+	}
+	if got := cex.calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want only the initial poll", got)
+	}
+}
+
+func TestPollingCEXOrderBookUseCase_PublishesErrors(t *testing.T) {
+	uc := NewPollingCEXOrderBookUseCase(logger.New("error"), CEXOrderBookOptions{})
+	failure := errors.New("rate limited")
+	out := make(chan entity.OrderBookResult, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = uc.Stream(ctx, []i.CEXAdapter{&stubCEX{name: "mexc", err: failure}}, []string{"SOL/USDT"}, time.Hour, out)
+	}()
+
+	select {
+	case r := <-out:
+		if !errors.Is(r.Error, failure) || r.Exchange != "mexc" || r.Symbol != "SOL/USDT" || r.Timestamp.IsZero() {
+			t.Errorf("result = %+v, want error from mexc SOL/USDT", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("error result was not published")
+	}
+}
+
+func TestPollingCEXOrderBookUseCase_ValidatesArguments(t *testing.T) {
+	uc := NewPollingCEXOrderBookUseCase(logger.New("error"), CEXOrderBookOptions{})
+
+	if err := uc.Stream(context.Background(), nil, nil, 0, make(chan entity.OrderBookResult)); err == nil {
+		t.Error("expected error for zero interval")
+	}
+	if err := uc.Stream(context.Background(), nil, nil, time.Second, nil); err == nil {
+		t.Error("expected error for nil out")
+	}
+}
+
+func TestPollingCEXOrderBookUseCase_DepthPerExchange(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	uc := NewPollingCEXOrderBookUseCase(logger.New("error"), CEXOrderBookOptions{
+		ExchangeDepth: map[string]int{"mexc": 5, "binance": 50},
+	})
+	uc.now = func() time.Time { return now }
+	mexc, binance, other := &stubCEX{name: "mexc"}, &stubCEX{name: "binance"}, &stubCEX{name: "other"}
+
+	pending := newOrderBookCoalescer()
+	uc.poll(context.Background(), []i.CEXAdapter{mexc, binance, other}, []string{"SOL/USDT"}, time.Second, pending)
+
+	if mexc.depth.Load() != 5 || binance.depth.Load() != 50 || other.depth.Load() != defaultCEXDepth {
+		t.Errorf("depths = %d, %d, %d, want 5, 50, %d",
+			mexc.depth.Load(), binance.depth.Load(), other.depth.Load(), defaultCEXDepth)
+	}
+	if r, ok := pending.pop(); !ok || !r.Timestamp.Equal(now) {
+		t.Errorf("result = %+v, want timestamp from the injected clock", r)
+	}
+}
@@ -0,0 +1,385 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/market"
+)
+
+// cycleEpsilon — минимальное улучшение суммы весов при релаксации; отсекает
+// «циклы» из ошибок округления логарифмов.
+const cycleEpsilon = 1e-12
+
+// CycleOptions — параметры детектора многоходовых циклов.
+type CycleOptions struct {
+	// MinProfitPct — минимальная доходность цикла в процентах после комиссий всех ходов.
+	MinProfitPct float64
+	// MaxQuoteAge — максимальный возраст котировки. При 0 берётся 5 сек.
+	MaxQuoteAge time.Duration
+	// EventTime — отсчитывать возраст котировок от времени обновлённой котировки (см. OpportunityOptions).
+	EventTime bool
+	// StartAssets — активы, с которых по возможности начинается цикл в порядке предпочтения
+	// (например, USDT, USDC). Иначе цикл начинается с наименьшего по алфавиту актива.
+	StartAssets []string
+}
+
+// CycleDetector ищет прибыльные циклы обменов по всем парам и биржам.
+//
+// Из последних свежих котировок строится граф: вершины — активы, рёбра — обмены.
+// Котировка пары BASE/QUOTE на бирже даёт ребро QUOTE→BASE (покупка по худшей из
+// двух цен с комиссией) и BASE→QUOTE (продажа). Из параллельных рёбер разных бирж
+// остаётся самое выгодное. Вес ребра — -ln(курс), поэтому прибыльный цикл —
+// цикл отрицательного веса; он ищется алгоритмом Беллмана — Форда.
+//
+// Двухходовые циклы (купить на одной бирже, продать на другой) тоже находятся —
+// это те же возможности, что и у FeeAwareOpportunityUseCase.
+type CycleDetector struct {
+	log      i.Logger
+	adapters map[string]i.EXAdapter
+	opts     CycleOptions
+	now      func() time.Time
+}
+
+// NewCycleDetector создаёт детектор. Адаптеры нужны для получения комиссий;
+// для бирж без адаптера комиссия считается нулевой.
+func NewCycleDetector(log i.Logger, adapters []i.EXAdapter, opts CycleOptions) *CycleDetector {
+	if opts.MaxQuoteAge <= 0 {
+		opts.MaxQuoteAge = defaultMaxQuoteAge
+	}
+	byName := make(map[string]i.EXAdapter, len(adapters))
+	for _, ad := range adapters {
+		byName[ad.Name()] = ad
+	}
+	return &CycleDetector{
+		log:      log.Named("cycles"),
+		adapters: byName,
+		opts:     opts,
+		now:      time.Now,
+	}
+}
+
+// Detect потребляет поток котировок и на каждое обновление публикует найденные циклы.
+// Завершается по ctx или при закрытии in; владение out — у вызывающей стороны.
+func (d *CycleDetector) Detect(
+	ctx context.Context,
+	in <-chan entity.ExecutableQuote,
+	out chan<- entity.CycleOpportunity,
+) error {
+	if out == nil {
+		return errors.New("out must not be nil")
+	}
+
+	// "биржа|пара" → последняя котировка
+	latest := make(map[string]entity.ExecutableQuote)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case q, ok := <-in:
+			if !ok {
+				return nil
+			}
+			latest[q.Exchange+"|"+q.Pair] = q
+
+			for _, c := range d.evaluate(q, latest) {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case out <- c:
+				}
+			}
+		}
+	}
+}
+
+// evaluate ищет циклы по свежим котировкам; устаревшие удаляются из latest.
+func (d *CycleDetector) evaluate(
+	updated entity.ExecutableQuote,
+	latest map[string]entity.ExecutableQuote,
+) []entity.CycleOpportunity {
+	now := d.now()
+	if d.opts.EventTime {
+		now = updated.Timestamp
+	}
+	if now.Sub(updated.Timestamp) > d.opts.MaxQuoteAge {
+		return nil
+	}
+
+	g := newAssetGraph()
+	for key, q := range latest {
+		if now.Sub(q.Timestamp) > d.opts.MaxQuoteAge {
+			delete(latest, key)
+			continue
+		}
+		d.addQuote(g, q)
+	}
+
+	var found []entity.CycleOpportunity
+	for _, legs := range g.negativeCycles() {
+		c := d.cycle(legs, now)
+		if c.ProfitPct > d.opts.MinProfitPct {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// addQuote добавляет в граф ходы покупки BASE по Ask и продажи по Bid.
+func (d *CycleDetector) addQuote(g *assetGraph, q entity.ExecutableQuote) {
+	inst, err := market.ParsePair(q.Pair)
+	if err != nil {
+		return
+	}
+	base, quote := inst.Base, inst.Quote
+	buyPrice, sellPrice := q.Ask, q.Bid
+	if buyPrice <= 0 || sellPrice <= 0 {
+		return
+	}
+	fee := d.takerFee(q.Exchange, q.Pair)
+
+	g.add(entity.CycleLeg{
+		Exchange: q.Exchange, Pair: q.Pair, Side: entity.SideBuy,
+		From: quote, To: base, Price: buyPrice, Fee: fee,
+		Rate: 1 / (buyPrice * (1 + fee)),
+	})
+	g.add(entity.CycleLeg{
+		Exchange: q.Exchange, Pair: q.Pair, Side: entity.SideSell,
+		From: base, To: quote, Price: sellPrice, Fee: fee,
+		Rate: sellPrice * (1 - fee),
+	})
+}
+
+// cycle собирает возможность из ходов цикла, начиная с предпочтительного актива.
+func (d *CycleDetector) cycle(legs []entity.CycleLeg, now time.Time) entity.CycleOpportunity {
+	start := 0
+	for n, leg := range legs {
+		if d.startRank(leg.From) < d.startRank(legs[start].From) ||
+			d.startRank(leg.From) == d.startRank(legs[start].From) && leg.From < legs[start].From {
+			start = n
+		}
+	}
+	rotated := append(slices.Clone(legs[start:]), legs[:start]...)
+
+	ret := 1.0
+	for _, leg := range rotated {
+		ret *= leg.Rate
+	}
+	return entity.CycleOpportunity{
+		Asset:      rotated[0].From,
+		Legs:       rotated,
+		Return:     ret,
+		ProfitPct:  (ret - 1) * 100,
+		DetectedAt: now,
+	}
+}
+
+// startRank — позиция актива в StartAssets; для прочих — len(StartAssets).
+func (d *CycleDetector) startRank(asset string) int {
+	if n := slices.Index(d.opts.StartAssets, asset); n >= 0 {
+		return n
+	}
+	return len(d.opts.StartAssets)
+}
+
+func (d *CycleDetector) takerFee(exchange, pair string) float64 {
+	ad, ok := d.adapters[exchange]
+	if !ok {
+		return 0
+	}
+	_, taker := ad.TradingFee(pair)
+	return taker
+}
+
+// assetGraph — граф обменов: из параллельных рёбер хранится лучшее по курсу.
+type assetGraph struct {
+	assets map[string]int
+	edges  map[[2]int]entity.CycleLeg
+}
+
+func newAssetGraph() *assetGraph {
+	return &assetGraph{
+		assets: make(map[string]int),
+		edges:  make(map[[2]int]entity.CycleLeg),
+	}
+}
+
+func (g *assetGraph) vertex(asset string) int {
+	v, ok := g.assets[asset]
+	if !ok {
+		v = len(g.assets)
+		g.assets[asset] = v
+	}
+	return v
+}
+
+func (g *assetGraph) add(leg entity.CycleLeg) {
+	key := [2]int{g.vertex(leg.From), g.vertex(leg.To)}
+	if cur, ok := g.edges[key]; !ok || leg.Rate > cur.Rate {
+		g.edges[key] = leg
+	}
+}
+
+// negativeCycles находит циклы отрицательного веса алгоритмом Беллмана — Форда
+// от фиктивного источника (все расстояния начинаются с нуля). Каждый цикл ищется
+// по цепочке предшественников от вершины, улучшенной на V-м проходе; вершины
+// найденных циклов не пересекаются.
+func (g *assetGraph) negativeCycles() [][]entity.CycleLeg {
+	type edge struct {
+		from, to int
+		weight   float64
+		leg      entity.CycleLeg
+	}
+	edges := make([]edge, 0, len(g.edges))
+	for key, leg := range g.edges {
+		edges = append(edges, edge{from: key[0], to: key[1], weight: -math.Log(leg.Rate), leg: leg})
+	}
+	// Порядок релаксации влияет на то, какой из пересекающихся циклов будет найден.
+	sort.Slice(edges, func(x, y int) bool {
+		if edges[x].from != edges[y].from {
+			return edges[x].from < edges[y].from
+		}
+		return edges[x].to < edges[y].to
+	})
+
+	n := len(g.assets)
+	dist := make([]float64, n)
+	pred := make([]int, n) // индекс ребра, которым достигнута вершина
+	for v := range pred {
+		pred[v] = -1
+	}
+
+	var relaxed []int
+	for range n {
+		relaxed = relaxed[:0]
+		for e, ed := range edges {
+			if d := dist[ed.from] + ed.weight; d < dist[ed.to]-cycleEpsilon {
+				dist[ed.to] = d
+				pred[ed.to] = e
+				relaxed = append(relaxed, ed.to)
+			}
+		}
+		if len(relaxed) == 0 {
+			return nil
+		}
+	}
+
+	var cycles [][]entity.CycleLeg
+	onCycle := make([]bool, n)
+	for _, v := range relaxed {
+		// После V шагов по предшественникам вершина гарантированно лежит на цикле.
+		for range n {
+			if pred[v] < 0 {
+				break
+			}
+			v = edges[pred[v]].from
+		}
+		if onCycle[v] || pred[v] < 0 {
+			continue
+		}
+		var legs []entity.CycleLeg
+		for x := v; ; {
+			onCycle[x] = true
+			ed := edges[pred[x]]
+			legs = append(legs, ed.leg)
+			x = ed.from
+			if x == v {
+				break
+			}
+		}
+		slices.Reverse(legs)
+		cycles = append(cycles, legs)
+	}
+	return cycles
+}
+
+// CycleAwareOpportunityUseCase передаёт каждую котировку и вложенному детектору
+// (его возможности идут в out метода Detect), и детектору циклов, циклы которого
+// публикуются в отдельный канал.
+type CycleAwareOpportunityUseCase struct {
+	inner  ArbOpportunityUseCase
+	cycles *CycleDetector
+	out    chan<- entity.CycleOpportunity
+}
+
+// NewCycleAwareOpportunityUseCase оборачивает детектор inner поиском циклов; циклы
+// публикуются в cyclesOut, владение которым — у вызывающей стороны.
+func NewCycleAwareOpportunityUseCase(
+	inner ArbOpportunityUseCase,
+	cycles *CycleDetector,
+	cyclesOut chan<- entity.CycleOpportunity,
+) *CycleAwareOpportunityUseCase {
+	return &CycleAwareOpportunityUseCase{inner: inner, cycles: cycles, out: cyclesOut}
+}
+
+// Detect реализует ArbOpportunityUseCase.
+func (u *CycleAwareOpportunityUseCase) Detect(
+	ctx context.Context,
+	in <-chan entity.ExecutableQuote,
+	out chan<- entity.ArbOpportunity,
+) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pairIn := make(chan entity.ExecutableQuote)
+	cycleIn := make(chan entity.ExecutableQuote)
+	errs := make(chan error, 2)
+	go func() {
+		err := u.inner.Detect(runCtx, pairIn, out)
+		if err != nil {
+			cancel()
+		}
+		errs <- err
+	}()
+	go func() {
+		err := u.cycles.Detect(runCtx, cycleIn, u.out)
+		if err != nil {
+			cancel()
+		}
+		errs <- err
+	}()
+
+	u.tee(runCtx, in, pairIn, cycleIn)
+	close(pairIn)
+	close(cycleIn)
+
+	var err error
+	for range 2 {
+		if e := <-errs; err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// tee раздаёт котировки из in во все outs до закрытия in или отмены ctx.
+func (u *CycleAwareOpportunityUseCase) tee(
+	ctx context.Context,
+	in <-chan entity.ExecutableQuote,
+	outs ...chan<- entity.ExecutableQuote,
+) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case q, ok := <-in:
+			if !ok {
+				return
+			}
+			for _, ch := range outs {
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- q:
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package scan
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+func runCycleDetector(
+	t *testing.T,
+	opts CycleOptions,
+	now time.Time,
+	quotes ...entity.ExecutableQuote,
+) []entity.CycleOpportunity {
+	t.Helper()
+
+	d := NewCycleDetector(
+		logger.New("error"),
+		[]i.EXAdapter{&feeAdapter{name: "mexc", taker: 0.001}, &feeAdapter{name: "jupiter"}},
+		opts,
+	)
+	d.now = func() time.Time { return now }
+
+	in := make(chan entity.ExecutableQuote, len(quotes))
+	for _, q := range quotes {
+		in <- q
+	}
+	close(in)
+
+	out := make(chan entity.CycleOpportunity, 16)
+	if err := d.Detect(context.Background(), in, out); err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	close(out)
+
+	var cycles []entity.CycleOpportunity
+	for c := range out {
+		cycles = append(cycles, c)
+	}
+	return cycles
+}
+
+// triangle — USDT→SOL на Jupiter, SOL→USDC на MEXC, USDC→USDT на Jupiter.
+func triangle(now time.Time) []entity.ExecutableQuote {
+	return []entity.ExecutableQuote{
+		{Exchange: "jupiter", Pair: "SOL/USDT", Bid: 100, Ask: 100, Timestamp: now},
+		{Exchange: "mexc", Pair: "SOL/USDC", Bid: 101.5, Ask: 101.6, Timestamp: now},
+		{Exchange: "jupiter", Pair: "USDC/USDT", Bid: 0.999, Ask: 0.999, Timestamp: now},
+	}
+}
+
+func TestCycleDetector_Triangle(t *testing.T) {
+	now := time.Now()
+	cycles := runCycleDetector(t, CycleOptions{MinProfitPct: 0.1, StartAssets: []string{"USDT"}}, now, triangle(now)...)
+	if len(cycles) != 1 {
+		t.Fatalf("got %d cycles, want 1: %+v", len(cycles), cycles)
+	}
+
+	c := cycles[0]
+	if want := "USDT →jupiter→ SOL →mexc→ USDC →jupiter→ USDT"; c.Path() != want {
+		t.Errorf("path = %q, want %q", c.Path(), want)
+	}
+	if c.Legs[0].Side != entity.SideBuy || c.Legs[1].Side != entity.SideSell || c.Legs[2].Side != entity.SideSell {
+		t.Errorf("sides = %s %s %s, want BUY SELL SELL", c.Legs[0].Side, c.Legs[1].Side, c.Legs[2].Side)
+	}
+	// 1/100 * 101.5*(1-0.001) * 0.999
+	want := 101.5 * 0.999 * 0.999 / 100
+	if math.Abs(c.Return-want) > 1e-12 || math.Abs(c.ProfitPct-(want-1)*100) > 1e-9 {
+		t.Errorf("return = %v (%v%%), want %v", c.Return, c.ProfitPct, want)
+	}
+	if !c.DetectedAt.Equal(now) {
+		t.Errorf("DetectedAt = %v, want %v", c.DetectedAt, now)
+	}
+}
+
+func TestCycleDetector_FeesAndThreshold(t *testing.T) {
+	now := time.Now()
+	// Доходность треугольника ≈ 1.3 % — ниже порога.
+	if cycles := runCycleDetector(t, CycleOptions{MinProfitPct: 2}, now, triangle(now)...); len(cycles) != 0 {
+		t.Errorf("got %d cycles above 2%%, want 0: %+v", len(cycles), cycles)
+	}
+
+	// Без комиссии MEXC цикл был бы прибыльным: 100.05 * 0.9995 / 100 > 1; с комиссией 0.1 % — нет.
+	quotes := []entity.ExecutableQuote{
+		{Exchange: "jupiter", Pair: "SOL/USDT", Bid: 100, Ask: 100, Timestamp: now},
+		{Exchange: "mexc", Pair: "SOL/USDC", Bid: 100.05, Ask: 100.1, Timestamp: now},
+		{Exchange: "jupiter", Pair: "USDC/USDT", Bid: 0.9995, Ask: 0.9995, Timestamp: now},
+	}
+	if cycles := runCycleDetector(t, CycleOptions{}, now, quotes...); len(cycles) != 0 {
+		t.Errorf("got %d cycles, want 0 after fees: %+v", len(cycles), cycles)
+	}
+}
+
+func TestCycleDetector_IgnoresStaleQuotes(t *testing.T) {
+	now := time.Now()
+	quotes := triangle(now)
+	quotes[1].Timestamp = now.Add(-2 * time.Second)
+
+	cycles := runCycleDetector(t, CycleOptions{MaxQuoteAge: time.Second}, now, quotes...)
+	if len(cycles) != 0 {
+		t.Errorf("got %d cycles with a stale hop, want 0: %+v", len(cycles), cycles)
+	}
+}
+
+func TestCycleDetector_TwoVenues(t *testing.T) {
+	now := time.Now()
+	cycles := runCycleDetector(t, CycleOptions{}, now,
+		entity.ExecutableQuote{Exchange: "mexc", Pair: "SOL/USDT", Bid: 99.9, Ask: 100, Timestamp: now},
+		entity.ExecutableQuote{Exchange: "jupiter", Pair: "SOL/USDT", Bid: 101, Ask: 101.2, Timestamp: now},
+	)
+	if len(cycles) != 1 {
+		t.Fatalf("got %d cycles, want 1: %+v", len(cycles), cycles)
+	}
+
+	// Без StartAssets цикл начинается с наименьшего по алфавиту актива.
+	c := cycles[0]
+	if want := "SOL →jupiter→ USDT →mexc→ SOL"; c.Path() != want {
+		t.Errorf("path = %q, want %q", c.Path(), want)
+	}
+	if want := 101 / (100 * 1.001); math.Abs(c.Return-want) > 1e-12 {
+		t.Errorf("return = %v, want %v", c.Return, want)
+	}
+}
+
+func TestCycleAwareOpportunityUseCase_FeedsBothDetectors(t *testing.T) {
+	now := time.Now()
+	inner := newTestDetector(0.1, now)
+	cycles := NewCycleDetector(logger.New("error"), nil, CycleOptions{StartAssets: []string{"USDT"}})
+	cycles.now = func() time.Time { return now }
+
+	cyclesOut := make(chan entity.CycleOpportunity, 16)
+	uc := NewCycleAwareOpportunityUseCase(inner, cycles, cyclesOut)
+
+	opps := runDetector(t, uc,
+		entity.ExecutableQuote{Exchange: "mexc", Pair: "SOL/USDT", Bid: 99.9, Ask: 100, Timestamp: now},
+		entity.ExecutableQuote{Exchange: "jupiter", Pair: "SOL/USDT", Bid: 101, Ask: 101.2, Timestamp: now},
+	)
+	if len(opps) != 1 || opps[0].BuyOn != "mexc" || opps[0].SellOn != "jupiter" {
+		t.Errorf("opportunities = %+v, want one mexc→jupiter", opps)
+	}
+	if len(cyclesOut) != 1 {
+		t.Fatalf("got %d cycles, want 1", len(cyclesOut))
+	}
+	if c := <-cyclesOut; c.Path() != "USDT →mexc→ SOL →jupiter→ USDT" {
+		t.Errorf("path = %q", c.Path())
+	}
+}
@@ -0,0 +1,169 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+)
+
+const defaultDEXConcurrency = 4
+
+// DEXPriceOptions — параметры опроса DEX.
+type DEXPriceOptions struct {
+	// Concurrency — максимальное число одновременных вызовов Quote. При 0 берётся 4.
+	Concurrency int
+	// CallTimeout — таймаут одного вызова Quote. При 0 равен интервалу опроса.
+	CallTimeout time.Duration
+}
+
+// PollingDEXPriceUseCase опрашивает DEXAdapter.Quote для каждой пары на каждом тике.
+//
+// Вызовы выполняются параллельно с ограничением Concurrency и собственным таймаутом,
+// поэтому медленная пара не задерживает остальные дольше CallTimeout.
+// Ошибки отдельных вызовов логируются и не прерывают поток.
+// Если раунд опроса занял больше интервала, накопившийся устаревший тик пропускается.
+type PollingDEXPriceUseCase struct {
+	log  i.Logger
+	opts DEXPriceOptions
+	now  func() time.Time
+}
+
+// NewPollingDEXPriceUseCase создаёт юзкейс опроса DEX.
+func NewPollingDEXPriceUseCase(log i.Logger, opts DEXPriceOptions) *PollingDEXPriceUseCase {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultDEXConcurrency
+	}
+	return &PollingDEXPriceUseCase{
+		log:  log.Named("dex_prices"),
+		opts: opts,
+		now:  time.Now,
+	}
+}
+
+// Stream реализует DEXPriceUseCase.
+func (u *PollingDEXPriceUseCase) Stream(
+	ctx context.Context,
+	providers []i.DEXAdapter,
+	pairs []string,
+	interval time.Duration,
+	baseAmount float64,
+	out chan<- entity.ExecutableQuote,
+) error {
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", interval)
+	}
+	if baseAmount <= 0 {
+		return fmt.Errorf("baseAmount must be positive, got %v", baseAmount)
+	}
+	if out == nil {
+		return errors.New("out must not be nil")
+	}
+
+	callTimeout := u.opts.CallTimeout
+	if callTimeout <= 0 {
+		callTimeout = interval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	u.poll(ctx, providers, pairs, baseAmount, callTimeout, out)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case tick := <-ticker.C:
+			if lag := u.now().Sub(tick); lag > interval {
+				u.log.Debug("skip stale tick", slog.Duration("lag", lag))
+				continue
+			}
+			u.poll(ctx, providers, pairs, baseAmount, callTimeout, out)
+		}
+	}
+}
+
+// poll выполняет один раунд опроса всех провайдеров по всем парам.
+func (u *PollingDEXPriceUseCase) poll(
+	ctx context.Context,
+	providers []i.DEXAdapter,
+	pairs []string,
+	baseAmount float64,
+	callTimeout time.Duration,
+	out chan<- entity.ExecutableQuote,
+) {
+	sem := make(chan struct{}, u.opts.Concurrency)
+	wg := &sync.WaitGroup{}
+
+	for _, p := range providers {
+		for _, pair := range pairs {
+			select {
+			case <-ctx.Done():
+				wg.Wait()
+				return
+			case sem <- struct{}{}:
+			}
+
+			wg.Add(1)
+			go func(p i.DEXAdapter, pair string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				q, err := u.quote(ctx, p, pair, baseAmount, callTimeout)
+				if err != nil {
+					if ctx.Err() == nil {
+						u.log.Warn("dex quote failed",
+							slog.String("exchange", p.Name()),
+							slog.String("pair", pair),
+							slog.Any("err", err),
+						)
+					}
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+				case out <- q:
+				}
+			}(p, pair)
+		}
+	}
+
+	wg.Wait()
+}
+
+// quote запрашивает котировку у одного провайдера с собственным таймаутом.
+func (u *PollingDEXPriceUseCase) quote(
+	ctx context.Context,
+	p i.DEXAdapter,
+	pair string,
+	baseAmount float64,
+	timeout time.Duration,
+) (entity.ExecutableQuote, error) {
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	bid, ask, err := p.Quote(callCtx, pair, baseAmount)
+	if err != nil {
+		return entity.ExecutableQuote{}, err
+	}
+	if bid <= 0 || ask <= 0 {
+		return entity.ExecutableQuote{}, fmt.Errorf("non-positive quote: bid=%v ask=%v", bid, ask)
+	}
+
+	return entity.ExecutableQuote{
+		Exchange:  p.Name(),
+		Pair:      pair,
+		Bid:       bid,
+		Ask:       ask,
+		BidQty:    baseAmount,
+		AskQty:    baseAmount,
+		Timestamp: u.now(),
+	}, nil
+}
@@ -0,0 +1,119 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/logger"
+)
+
+type stubDEX struct {
+	name     string
+	bid, ask float64
+	delay    time.Duration
+}
+
+func (s *stubDEX) Name() string                         { return s.name }
+func (s *stubDEX) TradingFee(string) (float64, float64) { return 0, 0 }
+func (s *stubDEX) Close() error                         { return nil }
+
+func (s *stubDEX) Quote(ctx context.Context, _ string, _ float64) (float64, float64, error) {
+	select {
+	case <-time.After(s.delay):
+		return s.bid, s.ask, nil
+	case <-ctx.Done():
+		return 0, 0, ctx.Err()
+	}
+}
+
+func TestPollingDEXPriceUseCase_SlowProviderDoesNotStall(t *testing.T) {
+	uc := NewPollingDEXPriceUseCase(logger.New("error"), DEXPriceOptions{
+		Concurrency: 2,
+		CallTimeout: 50 * time.Millisecond,
+	})
+
+	providers := []i.DEXAdapter{
+		&stubDEX{name: "slow", bid: 1, ask: 1, delay: time.Hour},
+		&stubDEX{name: "fast", bid: 99.5, ask: 100.5},
+	}
+	out := make(chan entity.ExecutableQuote, 4)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = uc.Stream(ctx, providers, []string{"SOL/USDT"}, time.Hour, 3, out) }()
+
+	select {
+	case q := <-out:
+		if q.Exchange != "fast" || q.Bid != 99.5 || q.Ask != 100.5 {
+			t.Errorf("unexpected quote %+v", q)
+		}
+		if q.BidQty != 3 || q.AskQty != 3 {
+			t.Errorf("BidQty/AskQty = %v/%v, want baseAmount 3", q.BidQty, q.AskQty)
+		}
+		if q.Timestamp.IsZero() {
+			t.Error("Timestamp is not set")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no quote from fast provider")
+	}
+
+	select {
+	case q := <-out:
+		t.Errorf("unexpected quote from timed-out provider: %+v", q)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// slowClockDEX отвечает сразу, но сдвигает часы опроса на delay: с их точки зрения
+// каждый вызов длится дольше интервала.
+type slowClockDEX struct {
+	stubDEX
+	delay time.Duration
+	skew  *atomic.Int64
+	calls atomic.Int32
+}
+
+func (s *slowClockDEX) Quote(ctx context.Context, pair string, amount float64) (float64, float64, error) {
+	s.calls.Add(1)
+	s.skew.Add(int64(s.delay))
+	return s.stubDEX.Quote(ctx, pair, amount)
+}
+
+func TestPollingDEXPriceUseCase_SkipsStaleTicks(t *testing.T) {
+	uc := NewPollingDEXPriceUseCase(logger.New("error"), DEXPriceOptions{})
+	skew := &atomic.Int64{}
+	uc.now = func() time.Time { return time.Now().Add(time.Duration(skew.Load())) }
+	dex := &slowClockDEX{stubDEX: stubDEX{name: "jupiter", bid: 1, ask: 1}, delay: time.Hour, skew: skew}
+	out := make(chan entity.ExecutableQuote, 16)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := uc.Stream(ctx, []i.DEXAdapter{dex}, []string{"SOL/USDT"}, 5*time.Millisecond, 1, out)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stream: %v", err)
+	}
+	// После медленного раунда все тики опаздывают больше чем на интервал и пропускаются.
+	if got := dex.calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want only the initial poll", got)
+	}
+}
+
+func TestPollingDEXPriceUseCase_ValidatesArguments(t *testing.T) {
+	uc := NewPollingDEXPriceUseCase(logger.New("error"), DEXPriceOptions{})
+	out := make(chan entity.ExecutableQuote)
+
+	if err := uc.Stream(context.Background(), nil, nil, 0, 1, out); err == nil {
+		t.Error("expected error for zero interval")
+	}
+	if err := uc.Stream(context.Background(), nil, nil, time.Second, 0, out); err == nil {
+		t.Error("expected error for zero baseAmount")
+	}
+	if err := uc.Stream(context.Background(), nil, nil, time.Second, 1, nil); err == nil {
+		t.Error("expected error for nil out")
+	}
+}
@@ -0,0 +1,490 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/market"
+	"github.com/dimryb/cross-arb/internal/orderbook"
+)
+
+const (
+	defaultSizingIterations = 12
+	defaultSizingTimeout    = 10 * time.Second
+	defaultSizingBookDepth  = 500
+)
+
+// invPhi — 1/φ, шаг золотого сечения.
+var invPhi = (math.Sqrt(5) - 1) / 2
+
+// SizingOptions — параметры подбора объёма сделки.
+type SizingOptions struct {
+	// MinSize — минимальный объём сделки в BASE. Если сделка не прибыльна даже на нём,
+	// возможность отбрасывается.
+	MinSize float64
+	// MaxSize — максимальный объём сделки в BASE. При значении меньше MinSize объём не подбирается.
+	MaxSize float64
+	// MaxQuote — максимальная стоимость покупки в QUOTE. При 0 не ограничивается.
+	MaxQuote float64
+	// Iterations — количество шагов золотого сечения. При 0 — 12.
+	Iterations int
+	// Timeout — ограничение на подбор объёма одной возможности. При 0 — 10 сек.
+	Timeout time.Duration
+	// BookDepth — глубина стакана CEX, запрашиваемая для оценки исполнения. При 0 — 500.
+	BookDepth int
+	// BalanceCap возвращает максимальный объём BASE, допустимый балансами для сделки.
+	// Необязателен: при nil балансы объём не ограничивают.
+	BalanceCap func(opp entity.ArbOpportunity) float64
+	// Filters возвращает торговые фильтры пары на бирже (market.Registry.Filters).
+	// Необязателен: при nil объём не округляется до шага и минимумы бирж не проверяются.
+	Filters func(exchange, pair string) (market.Filters, bool)
+}
+
+// SizedOpportunityUseCase дополняет возможности, найденные вложенным детектором,
+// оптимальным объёмом сделки и ожидаемой прибылью.
+//
+// Чистая прибыль как функция объёма оценивается по реальным кривым исполнения:
+// для CEX — проход по стакану (OrderBookDepth), для DEX — повторные котировки
+// (DEXAdapter.Quote) под каждый пробный объём. Максимум ищется золотым сечением
+// в пределах [max(MinSize, MinQty бирж), min(MaxSize, MaxQuote, глубина стакана, балансы)].
+// Найденный объём округляется вниз до шага количества бирж и проверяется на их
+// минимумы, чтобы движку не приходилось отправлять заведомо отклоняемые ордера.
+// Итоговая оценка делается по свежим стаканам и котировкам, а DetectedAt возможности —
+// время этой оценки: подбор не «состаривает» возможность для движка.
+//
+// Пока идёт подбор, новые возможности по тому же направлению коалесцируются:
+// оценивается только последняя, а детектор не блокируется.
+type SizedOpportunityUseCase struct {
+	log      i.Logger
+	inner    ArbOpportunityUseCase
+	adapters map[string]i.EXAdapter
+	opts     SizingOptions
+}
+
+// NewSizedOpportunityUseCase оборачивает детектор inner подбором объёма.
+func NewSizedOpportunityUseCase(
+	log i.Logger,
+	inner ArbOpportunityUseCase,
+	adapters []i.EXAdapter,
+	opts SizingOptions,
+) *SizedOpportunityUseCase {
+	if opts.MaxSize < opts.MinSize {
+		opts.MaxSize = opts.MinSize
+	}
+	if opts.Iterations <= 0 {
+		opts.Iterations = defaultSizingIterations
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultSizingTimeout
+	}
+	if opts.BookDepth <= 0 {
+		opts.BookDepth = defaultSizingBookDepth
+	}
+	byName := make(map[string]i.EXAdapter, len(adapters))
+	for _, ad := range adapters {
+		byName[ad.Name()] = ad
+	}
+	return &SizedOpportunityUseCase{
+		log:      log.Named("sizing"),
+		inner:    inner,
+		adapters: byName,
+		opts:     opts,
+	}
+}
+
+// Detect реализует ArbOpportunityUseCase.
+func (u *SizedOpportunityUseCase) Detect(
+	ctx context.Context,
+	in <-chan entity.ExecutableQuote,
+	out chan<- entity.ArbOpportunity,
+) error {
+	if out == nil {
+		return errors.New("out must not be nil")
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	raw := make(chan entity.ArbOpportunity)
+	innerErr := make(chan error, 1)
+	go func() {
+		innerErr <- u.inner.Detect(runCtx, in, raw)
+		cancel()
+	}()
+
+	pending := newOpportunityQueue()
+	go func() {
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case opp := <-raw:
+				pending.put(opp)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-runCtx.Done():
+			if err := <-innerErr; err != nil {
+				return err
+			}
+			return ctx.Err()
+		case <-pending.ready:
+			for _, opp := range pending.take() {
+				sized, err := u.size(runCtx, opp)
+				if err != nil {
+					u.log.Debug("opportunity dropped by sizing",
+						"pair", opp.Pair, "buy_on", opp.BuyOn, "sell_on", opp.SellOn, "err", err)
+					continue
+				}
+				select {
+				case <-runCtx.Done():
+				case out <- sized:
+				}
+			}
+		}
+	}
+}
+
+// errUnprofitable — ни один допустимый объём не даёт положительной прибыли.
+var errUnprofitable = errors.New("no profitable size")
+
+// size подбирает объём сделки, максимизирующий чистую прибыль.
+func (u *SizedOpportunityUseCase) size(ctx context.Context, opp entity.ArbOpportunity) (entity.ArbOpportunity, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.opts.Timeout)
+	defer cancel()
+
+	r := &sizingRun{
+		ctx:       ctx,
+		u:         u,
+		opp:       opp,
+		buyFee:    u.takerFee(opp.BuyOn, opp.Pair),
+		sellFee:   u.takerFee(opp.SellOn, opp.Pair),
+		books:     make(map[string]entity.OrderBook),
+		dexQuotes: make(map[dexQuoteKey][2]float64),
+	}
+
+	lo := u.opts.MinSize
+	for _, exchange := range []string{opp.BuyOn, opp.SellOn} {
+		if f, ok := r.filters(exchange); ok {
+			lo = max(lo, f.MinQty)
+		}
+	}
+	hi, err := r.upperBound()
+	if err != nil {
+		return entity.ArbOpportunity{}, err
+	}
+	if lo <= 0 || hi < lo {
+		return entity.ArbOpportunity{}, fmt.Errorf("size range [%v, %v] is empty", lo, hi)
+	}
+
+	best := goldenSection(r.evaluate, lo, hi, u.opts.Iterations)
+	if best.err != nil && best.pnl <= 0 {
+		return entity.ArbOpportunity{}, best.err
+	}
+	if best.pnl <= 0 {
+		return entity.ArbOpportunity{}, errUnprofitable
+	}
+
+	// Подбор делает десятки котировок и может длиться дольше, чем движок допускает возраст
+	// возможности. Поэтому итоговый объём (округлённый до шагов бирж) переоценивается
+	// по заново загруженным стаканам и котировкам, и возможность датируется их временем.
+	size := r.floorToSteps(best.size)
+	if size <= 0 || size < lo {
+		return entity.ArbOpportunity{}, fmt.Errorf("size %v rounded to lot step is below minimum %v", size, lo)
+	}
+	quotedAt := time.Now()
+	best = r.refresh().evaluate(size)
+	if best.err != nil {
+		return entity.ArbOpportunity{}, best.err
+	}
+	if best.pnl <= 0 {
+		return entity.ArbOpportunity{}, errUnprofitable
+	}
+	if err := r.checkFilters(best); err != nil {
+		return entity.ArbOpportunity{}, err
+	}
+
+	opp.BuyPrice, opp.SellPrice = best.buyPrice, best.sellPrice
+	opp.GrossPnl = best.sellPrice - best.buyPrice
+	opp.NetPnl = best.pnl / best.size
+	opp.SpreadPct = opp.NetPnl / best.buyPrice * 100
+	opp.Size = best.size
+	opp.ExpectedPnl = best.pnl
+	opp.DetectedAt = quotedAt
+	return opp, nil
+}
+
+func (u *SizedOpportunityUseCase) takerFee(exchange, pair string) float64 {
+	ad, ok := u.adapters[exchange]
+	if !ok {
+		return 0
+	}
+	_, taker := ad.TradingFee(pair)
+	return taker
+}
+
+// sizingPoint — оценка сделки для одного объёма.
+type sizingPoint struct {
+	size      float64
+	buyPrice  float64
+	sellPrice float64
+	pnl       float64 // в QUOTE за весь объём; -Inf, если объём неисполним
+	err       error
+}
+
+type dexQuoteKey struct {
+	exchange string
+	size     float64
+}
+
+// sizingRun — состояние подбора объёма одной возможности: стаканы CEX загружаются
+// один раз, котировки DEX кешируются по объёму.
+type sizingRun struct {
+	ctx       context.Context
+	u         *SizedOpportunityUseCase
+	opp       entity.ArbOpportunity
+	buyFee    float64
+	sellFee   float64
+	books     map[string]entity.OrderBook
+	dexQuotes map[dexQuoteKey][2]float64
+}
+
+// upperBound — наибольший допустимый объём с учётом MaxSize, MaxQuote, глубины стаканов и балансов.
+func (r *sizingRun) upperBound() (float64, error) {
+	hi := r.u.opts.MaxSize
+	if r.u.opts.MaxQuote > 0 && r.opp.BuyPrice > 0 {
+		hi = min(hi, r.u.opts.MaxQuote/r.opp.BuyPrice)
+	}
+	if r.u.opts.BalanceCap != nil {
+		hi = min(hi, r.u.opts.BalanceCap(r.opp))
+	}
+
+	for _, leg := range []struct {
+		exchange string
+		buy      bool
+	}{{r.opp.BuyOn, true}, {r.opp.SellOn, false}} {
+		if _, ok := r.u.adapters[leg.exchange].(i.CEXAdapter); !ok {
+			continue
+		}
+		book, err := r.book(leg.exchange)
+		if err != nil {
+			return 0, err
+		}
+		levels := book.Bids
+		if leg.buy {
+			levels = book.Asks
+		}
+		var depth float64
+		for _, l := range levels {
+			depth += l.Quantity
+		}
+		hi = min(hi, depth)
+	}
+	return hi, nil
+}
+
+// refresh сбрасывает загруженные стаканы и котировки, чтобы следующая оценка взяла свежие.
+func (r *sizingRun) refresh() *sizingRun {
+	clear(r.books)
+	clear(r.dexQuotes)
+	return r
+}
+
+// filters возвращает торговые фильтры пары на бирже exchange, если они известны.
+func (r *sizingRun) filters(exchange string) (market.Filters, bool) {
+	if r.u.opts.Filters == nil {
+		return market.Filters{}, false
+	}
+	return r.u.opts.Filters(exchange, r.opp.Pair)
+}
+
+// floorToSteps округляет объём вниз до шага количества обеих бирж.
+func (r *sizingRun) floorToSteps(size float64) float64 {
+	for _, exchange := range []string{r.opp.BuyOn, r.opp.SellOn} {
+		if f, ok := r.filters(exchange); ok {
+			size = f.FloorQty(size)
+		}
+	}
+	return size
+}
+
+// checkFilters проверяет минимальные количество и сумму ордера обеих ног по их ценам.
+func (r *sizingRun) checkFilters(p sizingPoint) error {
+	for _, leg := range []struct {
+		exchange string
+		price    float64
+	}{{r.opp.BuyOn, p.buyPrice}, {r.opp.SellOn, p.sellPrice}} {
+		f, ok := r.filters(leg.exchange)
+		if !ok {
+			continue
+		}
+		if err := f.Check(leg.price, p.size); err != nil {
+			return fmt.Errorf("%s: %w", leg.exchange, err)
+		}
+	}
+	return nil
+}
+
+// evaluate оценивает чистую прибыль сделки объёмом size.
+func (r *sizingRun) evaluate(size float64) sizingPoint {
+	p := sizingPoint{size: size, pnl: math.Inf(-1)}
+
+	buyPrice, err := r.price(r.opp.BuyOn, true, size)
+	if err != nil {
+		p.err = err
+		return p
+	}
+	sellPrice, err := r.price(r.opp.SellOn, false, size)
+	if err != nil {
+		p.err = err
+		return p
+	}
+
+	p.buyPrice, p.sellPrice = buyPrice, sellPrice
+	p.pnl = size * (sellPrice*(1-r.sellFee) - buyPrice*(1+r.buyFee))
+	return p
+}
+
+// price возвращает среднюю цену исполнения объёма size на бирже exchange.
+func (r *sizingRun) price(exchange string, buy bool, size float64) (float64, error) {
+	switch ad := r.u.adapters[exchange].(type) {
+	case i.CEXAdapter:
+		book, err := r.book(exchange)
+		if err != nil {
+			return 0, err
+		}
+		fill := orderbook.Sell(book, size)
+		if buy {
+			fill = orderbook.Buy(book, size)
+		}
+		if !fill.Filled(size) {
+			return 0, fmt.Errorf("%w: %s %v", orderbook.ErrInsufficientDepth, exchange, size)
+		}
+		return fill.AvgPrice, nil
+
+	case i.DEXAdapter:
+		key := dexQuoteKey{exchange: exchange, size: size}
+		q, ok := r.dexQuotes[key]
+		if !ok {
+			bid, ask, err := ad.Quote(r.ctx, r.opp.Pair, size)
+			if err != nil {
+				return 0, fmt.Errorf("%s quote: %w", exchange, err)
+			}
+			q = [2]float64{bid, ask}
+			r.dexQuotes[key] = q
+		}
+		if buy {
+			return q[1], nil
+		}
+		return q[0], nil
+
+	default:
+		return 0, fmt.Errorf("exchange %q has no depth or quote source", exchange)
+	}
+}
+
+func (r *sizingRun) book(exchange string) (entity.OrderBook, error) {
+	if b, ok := r.books[exchange]; ok {
+		return b, nil
+	}
+	ad, ok := r.u.adapters[exchange].(i.CEXAdapter)
+	if !ok {
+		return entity.OrderBook{}, fmt.Errorf("exchange %q is not a CEX", exchange)
+	}
+	b, err := ad.OrderBookDepth(r.ctx, r.opp.Pair, r.u.opts.BookDepth)
+	if err != nil {
+		return entity.OrderBook{}, fmt.Errorf("%s order book: %w", exchange, err)
+	}
+	r.books[exchange] = b
+	return b, nil
+}
+
+// goldenSection ищет максимум f на [lo, hi] золотым сечением и возвращает лучшую
+// из оценённых точек. Граница lo оценивается всегда — это гарантированный запасной вариант.
+func goldenSection(f func(float64) sizingPoint, lo, hi float64, iterations int) sizingPoint {
+	best := f(lo)
+	track := func(p sizingPoint) sizingPoint {
+		if p.pnl > best.pnl {
+			best = p
+		}
+		if best.err == nil && p.err != nil {
+			best.err = p.err
+		}
+		return p
+	}
+	if hi-lo <= 0 {
+		return best
+	}
+
+	a, b := lo, hi
+	c := b - invPhi*(b-a)
+	d := a + invPhi*(b-a)
+	fc, fd := track(f(c)), track(f(d))
+	for range iterations {
+		if fc.pnl >= fd.pnl {
+			b, d, fd = d, c, fc
+			c = b - invPhi*(b-a)
+			fc = track(f(c))
+		} else {
+			a, c, fc = c, d, fd
+			d = a + invPhi*(b-a)
+			fd = track(f(d))
+		}
+	}
+	return best
+}
+
+// opportunityQueue коалесцирует возможности по направлению (пара, покупка, продажа).
+type opportunityQueue struct {
+	mu      sync.Mutex
+	pending map[opportunityKey]entity.ArbOpportunity
+	order   []opportunityKey
+	ready   chan struct{}
+}
+
+type opportunityKey struct{ pair, buyOn, sellOn string }
+
+func newOpportunityQueue() *opportunityQueue {
+	return &opportunityQueue{
+		pending: make(map[opportunityKey]entity.ArbOpportunity),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+func (q *opportunityQueue) put(opp entity.ArbOpportunity) {
+	k := opportunityKey{pair: opp.Pair, buyOn: opp.BuyOn, sellOn: opp.SellOn}
+
+	q.mu.Lock()
+	if _, ok := q.pending[k]; !ok {
+		q.order = append(q.order, k)
+	}
+	q.pending[k] = opp
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *opportunityQueue) take() []entity.ArbOpportunity {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	opps := make([]entity.ArbOpportunity, 0, len(q.order))
+	for _, k := range q.order {
+		opps = append(opps, q.pending[k])
+	}
+	q.pending = make(map[opportunityKey]entity.ArbOpportunity)
+	q.order = nil
+	return opps
+}
@@ -0,0 +1,235 @@
+package scan
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dimryb/cross-arb/internal/entity"
+	i "github.com/dimryb/cross-arb/internal/interface"
+	"github.com/dimryb/cross-arb/internal/logger"
+	"github.com/dimryb/cross-arb/internal/market"
+	"github.com/dimryb/cross-arb/internal/usecase/execute"
+)
+
+// depthCEX отдаёт фиксированный стакан.
+type depthCEX struct {
+	feeAdapter
+	book entity.OrderBook
+}
+
+func (c *depthCEX) OrderBookDepth(context.Context, string, int) (entity.OrderBook, error) {
+	return c.book, nil
+}
+
+// impactDEX — DEX с линейным ценовым влиянием: цена продажи BASE падает на slope за каждый BASE.
+type impactDEX struct {
+	feeAdapter
+	price, slope float64
+}
+
+func (d *impactDEX) Quote(_ context.Context, _ string, baseAmount float64) (float64, float64, error) {
+	p := d.price - d.slope*baseAmount
+	return p, p, nil
+}
+
+// staticDetector публикует заданные возможности и ждёт отмены.
+type staticDetector struct{ opps []entity.ArbOpportunity }
+
+func (s staticDetector) Detect(ctx context.Context, _ <-chan entity.ExecutableQuote, out chan<- entity.ArbOpportunity) error {
+	for _, o := range s.opps {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case out <- o:
+		}
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func runSizer(t *testing.T, opts SizingOptions, opp entity.ArbOpportunity) (entity.ArbOpportunity, bool) {
+	t.Helper()
+
+	// Покупка на CEX по 100 (глубина 100 BASE), продажа на DEX: 110 - q.
+	// Прибыль q*(10 - q) максимальна при q = 5.
+	adapters := []i.EXAdapter{
+		&depthCEX{
+			feeAdapter: feeAdapter{name: "mexc"},
+			book:       entity.OrderBook{Asks: []entity.Order{{Price: 100, Quantity: 100}}},
+		},
+		&impactDEX{feeAdapter: feeAdapter{name: "jupiter"}, price: 110, slope: 1},
+	}
+	uc := NewSizedOpportunityUseCase(logger.New("error"), staticDetector{opps: []entity.ArbOpportunity{opp}}, adapters, opts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := make(chan entity.ArbOpportunity, 1)
+	go func() { _ = uc.Detect(ctx, nil, out) }()
+
+	select {
+	case o := <-out:
+		return o, true
+	case <-time.After(200 * time.Millisecond):
+		return entity.ArbOpportunity{}, false
+	}
+}
+
+var probeOpp = entity.ArbOpportunity{Pair: "SOL/USDT", BuyOn: "mexc", BuyPrice: 100, SellOn: "jupiter", SellPrice: 109.9}
+
+func TestSizedOpportunityUseCase_FindsOptimum(t *testing.T) {
+	o, ok := runSizer(t, SizingOptions{MinSize: 0.1, MaxSize: 20, Iterations: 20}, probeOpp)
+	if !ok {
+		t.Fatal("no opportunity published")
+	}
+	if math.Abs(o.Size-5) > 0.05 {
+		t.Errorf("Size = %v, want ~5", o.Size)
+	}
+	if math.Abs(o.ExpectedPnl-25) > 0.01 {
+		t.Errorf("ExpectedPnl = %v, want ~25", o.ExpectedPnl)
+	}
+	if math.Abs(o.NetPnl*o.Size-o.ExpectedPnl) > 1e-9 || o.BuyPrice != 100 {
+		t.Errorf("inconsistent per-unit values: %+v", o)
+	}
+}
+
+func TestSizedOpportunityUseCase_RespectsLimits(t *testing.T) {
+	o, ok := runSizer(t, SizingOptions{
+		MinSize:    0.1,
+		MaxSize:    20,
+		BalanceCap: func(entity.ArbOpportunity) float64 { return 3 },
+	}, probeOpp)
+	if !ok {
+		t.Fatal("no opportunity published")
+	}
+	if o.Size > 3 || o.Size < 2.9 {
+		t.Errorf("Size = %v, want close to balance cap 3", o.Size)
+	}
+
+	o, _ = runSizer(t, SizingOptions{MinSize: 0.1, MaxSize: 20, MaxQuote: 200}, probeOpp)
+	if o.Size > 2 || o.Size < 1.9 {
+		t.Errorf("Size = %v, want close to MaxQuote/price = 2", o.Size)
+	}
+}
+
+func TestSizedOpportunityUseCase_DropsUnprofitable(t *testing.T) {
+	// На минимальном объёме 12 BASE цена DEX уже ниже 100.
+	if o, ok := runSizer(t, SizingOptions{MinSize: 12, MaxSize: 20}, probeOpp); ok {
+		t.Errorf("unexpected opportunity %+v", o)
+	}
+}
+
+func TestSizedOpportunityUseCase_AppliesVenueFilters(t *testing.T) {
+	filters := func(f market.Filters) func(string, string) (market.Filters, bool) {
+		return func(exchange, _ string) (market.Filters, bool) { return f, exchange == "mexc" }
+	}
+
+	o, ok := runSizer(t, SizingOptions{
+		MinSize:    0.1,
+		MaxSize:    20,
+		Iterations: 20,
+		Filters:    filters(market.Filters{StepSize: 0.5}),
+	}, probeOpp)
+	if !ok {
+		t.Fatal("no opportunity published")
+	}
+	// Оптимум ~5 округляется вниз до шага (4.5), прибыль пересчитывается для него.
+	if o.Size != 4.5 || math.Abs(o.ExpectedPnl-24.75) > 1e-9 {
+		t.Errorf("Size = %v, ExpectedPnl = %v; want 4.5 and 24.75", o.Size, o.ExpectedPnl)
+	}
+
+	// Округление до шага опускает объём до нуля или ниже минимального.
+	for _, opts := range []SizingOptions{
+		{MinSize: 0.1, MaxSize: 0.4},
+		{MinSize: 0.6, MaxSize: 0.9},
+	} {
+		opts.Filters = filters(market.Filters{StepSize: 0.5})
+		if o, ok := runSizer(t, opts, probeOpp); ok {
+			t.Errorf("range [%v, %v]: unexpected opportunity %+v", opts.MinSize, opts.MaxSize, o)
+		}
+	}
+
+	// Минимальное количество биржи поднимает нижнюю границу туда, где сделка убыточна.
+	if o, ok := runSizer(t, SizingOptions{
+		MinSize: 0.1,
+		MaxSize: 20,
+		Filters: filters(market.Filters{MinQty: 12}),
+	}, probeOpp); ok {
+		t.Errorf("unexpected opportunity %+v", o)
+	}
+
+	// Даже лучший объём не дотягивает до минимальной суммы ордера.
+	if o, ok := runSizer(t, SizingOptions{
+		MinSize: 0.1,
+		MaxSize: 20,
+		Filters: filters(market.Filters{MinNotional: 1500}),
+	}, probeOpp); ok {
+		t.Errorf("unexpected opportunity %+v", o)
+	}
+}
+
+// slowDEX — impactDEX с задержкой каждой котировки, как у реального агрегатора.
+type slowDEX struct {
+	impactDEX
+	delay time.Duration
+}
+
+func (d *slowDEX) Quote(ctx context.Context, pair string, baseAmount float64) (float64, float64, error) {
+	select {
+	case <-ctx.Done():
+		return 0, 0, ctx.Err()
+	case <-time.After(d.delay):
+	}
+	return d.impactDEX.Quote(ctx, pair, baseAmount)
+}
+
+// instantLeg исполняет ордер полностью по цене заявки.
+type instantLeg struct{}
+
+func (instantLeg) Execute(_ context.Context, o execute.Order) (execute.Fill, error) {
+	return execute.Fill{OrderID: o.ClientID, Filled: o.Size, Quote: o.Size * o.Price}, nil
+}
+
+func TestSizedOpportunityUseCase_FreshForEngine(t *testing.T) {
+	const maxAge = 100 * time.Millisecond
+	adapters := []i.EXAdapter{
+		&depthCEX{
+			feeAdapter: feeAdapter{name: "mexc"},
+			book:       entity.OrderBook{Asks: []entity.Order{{Price: 100, Quantity: 100}}},
+		},
+		// ~26 котировок по 15 мс: подбор длится в несколько раз дольше maxAge.
+		&slowDEX{impactDEX: impactDEX{feeAdapter: feeAdapter{name: "jupiter"}, price: 110, slope: 1}, delay: 15 * time.Millisecond},
+	}
+	opp := probeOpp
+	opp.DetectedAt = time.Now()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sizer := NewSizedOpportunityUseCase(logger.New("error"), staticDetector{opps: []entity.ArbOpportunity{opp}}, adapters,
+		SizingOptions{MinSize: 0.1, MaxSize: 20})
+	sized := make(chan entity.ArbOpportunity, 1)
+	go func() { _ = sizer.Detect(ctx, nil, sized) }()
+
+	engine, err := execute.NewEngine(logger.New("error"),
+		map[string]execute.Leg{"mexc": instantLeg{}, "jupiter": instantLeg{}}, adapters,
+		execute.Options{MaxOpportunityAge: maxAge})
+	if err != nil {
+		t.Fatal(err)
+	}
+	execs := make(chan entity.Execution, 1)
+	go func() { _ = engine.Run(ctx, sized, execs) }()
+
+	select {
+	case e := <-execs:
+		if e.Status != entity.ExecutionFilled {
+			t.Errorf("status = %s, want filled", e.Status)
+		}
+		if time.Since(opp.DetectedAt) <= maxAge {
+			t.Errorf("sizing took less than %v; latency is not exercised", maxAge)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("sized opportunity was not executed (rejected as stale)")
+	}
+}
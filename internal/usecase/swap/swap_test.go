@@ -43,7 +43,8 @@ func TestSwapper_Swap(t *testing.T) {
 	t.Logf("Кошелек успешно создан. PublicKey: %s", phantomWallet.PublicKey())
 
 	// Определяем mint-адреса для пары через резолвер Jupiter
-	inMint, outMint, err := jupiter.ConvertSpotToMints("SOLUSDT")
+	tokens := jupiter.NewTokenRegistry(testLogger, jupiter.TokenRegistryConfig{})
+	inMint, outMint, err := tokens.Mints("SOL/USDT")
 	if err != nil {
 		t.Fatalf("Не удалось получить mint-адреса: %v", err)
 	}
@@ -18,7 +18,8 @@ import (
 //  3. подписывает транзакцию переданным TransactionSigner,
 //  4. отправляет и подтверждает её через Solana RPC.
 //
-// Возвращает сигнатуру подтверждённой транзакции или ошибку.
+// Возвращает сигнатуру подтверждённой транзакции или ошибку; если транзакция могла уйти
+// в сеть, но не подтверждена, — её сигнатуру и *solana.UnconfirmedError (пакет internal/solana).
 func (s *Swapper) SwapWithQuote(
 	ctx context.Context,
 	signer i.TransactionSigner,
@@ -47,9 +48,11 @@ func (s *Swapper) SwapWithQuote(
 		return solana.Signature{}, fmt.Errorf("не удалось подписать транзакцию: %w", err)
 	}
 
+	// При неподтверждённой транзакции сигнатура возвращается вместе с ошибкой:
+	// обмен ещё может исполниться.
 	signature, err := s.solanaClient.SendAndConfirmTransaction(ctx, tx)
 	if err != nil {
-		return solana.Signature{}, err
+		return signature, err
 	}
 
 	return signature, nil